@@ -0,0 +1,426 @@
+package accessrequest
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/authority"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+type Request struct {
+	Id            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	User          primitive.ObjectID `bson:"user" json:"user"`
+	Username      string             `bson:"username" json:"username"`
+	Type          string             `bson:"type" json:"type"`
+	Resource      primitive.ObjectID `bson:"resource,omitempty" json:"resource"`
+	ResourceName  string             `bson:"resource_name" json:"resource_name"`
+	Role          string             `bson:"role" json:"role"`
+	Justification string             `bson:"justification" json:"justification"`
+	Duration      int                `bson:"duration" json:"duration"`
+	State         string             `bson:"state" json:"state"`
+	Timestamp     time.Time          `bson:"timestamp" json:"timestamp"`
+	Approver      primitive.ObjectID `bson:"approver,omitempty" json:"approver"`
+	ApproverName  string             `bson:"approver_name" json:"approver_name"`
+	Comment       string             `bson:"comment" json:"comment"`
+	Decided       time.Time          `bson:"decided" json:"decided"`
+	Expires       time.Time          `bson:"expires" json:"expires"`
+	Added         bool               `bson:"added" json:"-"`
+}
+
+func (r *Request) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	r.Justification = strings.TrimSpace(r.Justification)
+	if len(r.Justification) > 1024 {
+		r.Justification = r.Justification[:1024]
+	}
+
+	if r.Justification == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_justification_missing",
+			Message: "Access request justification is required",
+		}
+		return
+	}
+
+	if r.Duration < 1 || r.Duration > settings.Access.MaxDuration {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_duration_invalid",
+			Message: "Access request duration is invalid",
+		}
+		return
+	}
+
+	switch r.Type {
+	case Role:
+		r.Resource = primitive.NilObjectID
+		r.ResourceName = r.Role
+
+		if !contains(settings.Access.RequestRoles, r.Role) {
+			errData = &errortypes.ErrorData{
+				Error:   "access_request_role_invalid",
+				Message: "Role cannot be requested",
+			}
+			return
+		}
+		break
+	case Service:
+		srvce, e := service.Get(db, r.Resource)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); ok {
+				errData = &errortypes.ErrorData{
+					Error:   "access_request_service_invalid",
+					Message: "Service does not exist",
+				}
+			} else {
+				err = e
+			}
+			return
+		}
+
+		r.ResourceName = srvce.Name
+
+		if r.Role == "" && len(srvce.Roles) != 0 {
+			r.Role = srvce.Roles[0]
+		}
+
+		if !contains(srvce.Roles, r.Role) {
+			errData = &errortypes.ErrorData{
+				Error:   "access_request_role_invalid",
+				Message: "Role does not grant access to service",
+			}
+			return
+		}
+		break
+	case Authority:
+		authr, e := authority.Get(db, r.Resource)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); ok {
+				errData = &errortypes.ErrorData{
+					Error:   "access_request_authority_invalid",
+					Message: "Authority does not exist",
+				}
+			} else {
+				err = e
+			}
+			return
+		}
+
+		r.ResourceName = authr.Name
+
+		if !authr.MatchRoles {
+			errData = &errortypes.ErrorData{
+				Error:   "access_request_authority_open",
+				Message: "Authority is available to all users",
+			}
+			return
+		}
+
+		if r.Role == "" && len(authr.Roles) != 0 {
+			r.Role = authr.Roles[0]
+		}
+
+		if !contains(authr.Roles, r.Role) {
+			errData = &errortypes.ErrorData{
+				Error:   "access_request_role_invalid",
+				Message: "Role does not grant access to authority",
+			}
+			return
+		}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_type_invalid",
+			Message: "Access request type is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+func (r *Request) decide(approver *user.User, state, comment string) (
+	errData *errortypes.ErrorData, err error) {
+
+	if r.State != Pending {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_pending",
+			Message: "Access request is no longer pending",
+		}
+		return
+	}
+
+	if approver.Id == r.User {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_self_approve",
+			Message: "Cannot decide own access request",
+		}
+		return
+	}
+
+	r.State = state
+	r.Approver = approver.Id
+	r.ApproverName = approver.Username
+	r.Comment = strings.TrimSpace(comment)
+	if len(r.Comment) > 1024 {
+		r.Comment = r.Comment[:1024]
+	}
+	r.Decided = time.Now()
+
+	return
+}
+
+// Approve request and grant role until the requested duration expires
+func (r *Request) Approve(db *database.Database, approver *user.User,
+	comment string) (errData *errortypes.ErrorData, err error) {
+
+	errData, err = r.decide(approver, Approved, comment)
+	if err != nil || errData != nil {
+		return
+	}
+
+	usr, err := user.Get(db, r.User)
+	if err != nil {
+		return
+	}
+
+	r.Expires = r.Decided.Add(time.Duration(r.Duration) * time.Hour)
+
+	if !contains(usr.Roles, r.Role) {
+		r.Added = true
+	} else {
+		r.Added, err = hasActiveGrant(db, r.User, r.Role, r.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	ok, err := r.commitState(db, Pending, set.NewSet(
+		"state",
+		"approver",
+		"approver_name",
+		"comment",
+		"decided",
+		"expires",
+		"added",
+	))
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_pending",
+			Message: "Access request is no longer pending",
+		}
+		return
+	}
+
+	coll := db.Users()
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": r.User,
+	}, &bson.M{
+		"$addToSet": &bson.M{
+			"roles": r.Role,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func (r *Request) Deny(db *database.Database, approver *user.User,
+	comment string) (errData *errortypes.ErrorData, err error) {
+
+	errData, err = r.decide(approver, Denied, comment)
+	if err != nil || errData != nil {
+		return
+	}
+
+	ok, err := r.commitState(db, Pending, set.NewSet(
+		"state",
+		"approver",
+		"approver_name",
+		"comment",
+		"decided",
+	))
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_pending",
+			Message: "Access request is no longer pending",
+		}
+		return
+	}
+
+	return
+}
+
+func (r *Request) Cancel(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	if r.State != Pending {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_pending",
+			Message: "Access request is no longer pending",
+		}
+		return
+	}
+
+	r.State = Cancelled
+	r.Decided = time.Now()
+
+	ok, err := r.commitState(db, Pending, set.NewSet(
+		"state",
+		"decided",
+	))
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_pending",
+			Message: "Access request is no longer pending",
+		}
+		return
+	}
+
+	return
+}
+
+// End an approved grant and remove the role if no other grant holds it
+func (r *Request) End(db *database.Database, state string) (
+	errData *errortypes.ErrorData, err error) {
+
+	if r.State != Approved {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_approved",
+			Message: "Access request is not an active grant",
+		}
+		return
+	}
+
+	r.State = state
+	if state == Revoked {
+		r.Expires = time.Now()
+	}
+
+	ok, err := r.commitState(db, Approved, set.NewSet(
+		"state",
+		"expires",
+	))
+	if err != nil {
+		return
+	}
+
+	if !ok {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_not_approved",
+			Message: "Access request is not an active grant",
+		}
+		return
+	}
+
+	if !r.Added {
+		return
+	}
+
+	active, err := hasActiveGrant(db, r.User, r.Role, r.Id)
+	if err != nil {
+		return
+	}
+
+	if active {
+		return
+	}
+
+	coll := db.Users()
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": r.User,
+	}, &bson.M{
+		"$pull": &bson.M{
+			"roles": r.Role,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		} else {
+			return
+		}
+	}
+
+	return
+}
+
+// Commit fields only if request has not changed state
+func (r *Request) commitState(db *database.Database, state string,
+	fields set.Set) (ok bool, err error) {
+
+	coll := db.AccessRequests()
+
+	resp, err := coll.UpdateOne(db, &bson.M{
+		"_id":   r.Id,
+		"state": state,
+	}, database.SelectFieldsAll(r, fields))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	ok = resp.MatchedCount != 0
+
+	return
+}
+
+func (r *Request) Commit(db *database.Database) (err error) {
+	coll := db.AccessRequests()
+
+	err = coll.Commit(r.Id, r)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *Request) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.AccessRequests()
+
+	err = coll.CommitFields(r.Id, r, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *Request) Insert(db *database.Database) (err error) {
+	coll := db.AccessRequests()
+
+	_, err = coll.InsertOne(db, r)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
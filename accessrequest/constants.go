@@ -0,0 +1,20 @@
+package accessrequest
+
+import (
+	"github.com/pritunl/pritunl-zero/device"
+)
+
+const (
+	NotifyLevel = device.Medium
+
+	Role      = "role"
+	Service   = "service"
+	Authority = "authority"
+
+	Pending   = "pending"
+	Approved  = "approved"
+	Denied    = "denied"
+	Cancelled = "cancelled"
+	Revoked   = "revoked"
+	Expired   = "expired"
+)
@@ -0,0 +1,111 @@
+package accessrequest
+
+import (
+	"fmt"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/pritunl-zero/alertevent"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/sirupsen/logrus"
+)
+
+func (r *Request) label() string {
+	if r.Type == Role {
+		return fmt.Sprintf("role %s", r.Role)
+	}
+	return fmt.Sprintf("%s %s", r.Type, r.ResourceName)
+}
+
+func notifyUser(db *database.Database, usr *user.User, msg string) {
+	devices, err := usr.GetDevices(db)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"user_id": usr.Id.Hex(),
+			"error":   err,
+		}).Error("accessrequest: Failed to get user devices")
+		return
+	}
+
+	for _, devc := range devices {
+		if devc.Mode != device.Phone || !devc.CheckLevel(NotifyLevel) {
+			continue
+		}
+
+		errData, err := alertevent.Send(devc.Number, msg, devc.Type)
+		if err != nil {
+			if errData != nil {
+				logrus.WithFields(logrus.Fields{
+					"user_id":        usr.Id.Hex(),
+					"server_error":   errData.Error,
+					"server_message": errData.Message,
+					"error":          err,
+				}).Error("accessrequest: Failed to send notification")
+			} else {
+				logrus.WithFields(logrus.Fields{
+					"user_id": usr.Id.Hex(),
+					"error":   err,
+				}).Error("accessrequest: Failed to send notification")
+			}
+		}
+	}
+}
+
+// Notify approvers of a new request
+func (r *Request) NotifyApprovers() {
+	if len(settings.Access.ApproverRoles) == 0 {
+		return
+	}
+
+	go func() {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		users, _, err := user.GetAll(db, &bson.M{
+			"roles": &bson.M{
+				"$in": settings.Access.ApproverRoles,
+			},
+			"disabled": &bson.M{
+				"$ne": true,
+			},
+		}, 0, 0)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("accessrequest: Failed to get approvers")
+			return
+		}
+
+		msg := fmt.Sprintf("Access request from %s for %s",
+			r.Username, r.label())
+
+		for _, usr := range users {
+			if usr.Id == r.User {
+				continue
+			}
+			notifyUser(db, usr, msg)
+		}
+	}()
+}
+
+// Notify requesting user of a state change
+func (r *Request) NotifyUser() {
+	go func() {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		usr, err := user.Get(db, r.User)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id": r.User.Hex(),
+				"error":   err,
+			}).Error("accessrequest: Failed to get user")
+			return
+		}
+
+		notifyUser(db, usr, fmt.Sprintf("Access request for %s %s",
+			r.label(), r.State))
+	}()
+}
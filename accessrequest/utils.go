@@ -0,0 +1,219 @@
+package accessrequest
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func contains(items []string, item string) bool {
+	if item == "" {
+		return false
+	}
+
+	for _, itm := range items {
+		if itm == item {
+			return true
+		}
+	}
+	return false
+}
+
+func hasActiveGrant(db *database.Database, userId primitive.ObjectID,
+	role string, excludeId primitive.ObjectID) (active bool, err error) {
+
+	coll := db.AccessRequests()
+
+	count, err := coll.CountDocuments(db, &bson.M{
+		"_id": &bson.M{
+			"$ne": excludeId,
+		},
+		"user":  userId,
+		"role":  role,
+		"state": Approved,
+		"added": true,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	active = count != 0
+
+	return
+}
+
+// Check if user has an approved access request that has not expired for
+// the service resource or for one of the roles
+func HasApproved(db *database.Database, userId primitive.ObjectID,
+	resourceId primitive.ObjectID, roles []string) (
+	approved bool, err error) {
+
+	coll := db.AccessRequests()
+
+	match := []*bson.M{}
+	if !resourceId.IsZero() {
+		match = append(match, &bson.M{
+			"type":     Service,
+			"resource": resourceId,
+		})
+	}
+	if len(roles) != 0 {
+		match = append(match, &bson.M{
+			"type": Role,
+			"role": &bson.M{
+				"$in": roles,
+			},
+		})
+	}
+
+	if len(match) == 0 {
+		return
+	}
+
+	count, err := coll.CountDocuments(db, &bson.M{
+		"user":  userId,
+		"state": Approved,
+		"expires": &bson.M{
+			"$gt": time.Now(),
+		},
+		"$or": match,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	approved = count != 0
+
+	return
+}
+
+func IsApprover(usr *user.User) bool {
+	return usr.RolesMatch(settings.Access.ApproverRoles)
+}
+
+func New(usr *user.User) (req *Request) {
+	req = &Request{
+		Id:        primitive.NewObjectID(),
+		User:      usr.Id,
+		Username:  usr.Username,
+		State:     Pending,
+		Timestamp: time.Now(),
+	}
+
+	return
+}
+
+func Get(db *database.Database, reqId primitive.ObjectID) (
+	req *Request, err error) {
+
+	coll := db.AccessRequests()
+	req = &Request{}
+
+	err = coll.FindOneId(reqId, req)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetUser(db *database.Database, reqId primitive.ObjectID,
+	userId primitive.ObjectID) (req *Request, err error) {
+
+	coll := db.AccessRequests()
+	req = &Request{}
+
+	err = coll.FindOne(db, &bson.M{
+		"_id":  reqId,
+		"user": userId,
+	}).Decode(req)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database, query *bson.M, limit int64) (
+	reqs []*Request, err error) {
+
+	coll := db.AccessRequests()
+	reqs = []*Request{}
+
+	opts := &options.FindOptions{
+		Sort: &bson.D{
+			{"timestamp", -1},
+		},
+	}
+	if limit != 0 {
+		opts.Limit = &limit
+	}
+
+	cursor, err := coll.Find(db, query, opts)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		req := &Request{}
+		err = cursor.Decode(req)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		reqs = append(reqs, req)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetPendingDuplicate(db *database.Database, userId primitive.ObjectID,
+	role string) (exists bool, err error) {
+
+	coll := db.AccessRequests()
+
+	count, err := coll.CountDocuments(db, &bson.M{
+		"user":  userId,
+		"role":  role,
+		"state": Pending,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	exists = count != 0
+
+	return
+}
+
+func GetExpired(db *database.Database) (reqs []*Request, err error) {
+	reqs, err = GetAll(db, &bson.M{
+		"state": Approved,
+		"expires": &bson.M{
+			"$lte": time.Now(),
+		},
+	}, 0)
+	if err != nil {
+		return
+	}
+
+	return
+}
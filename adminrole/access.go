@@ -0,0 +1,133 @@
+package adminrole
+
+import (
+	"sort"
+
+	"github.com/dropbox/godropbox/container/set"
+)
+
+// Effective administrator permissions, scoped permissions only apply to
+// objects tagged with one of the scope roles
+type Access struct {
+	Super    bool
+	unscoped set.Set
+	scoped   map[string]set.Set
+}
+
+func (a *Access) add(perm string, roles []string) {
+	if scopable.Contains(perm) && len(roles) != 0 {
+		scopes := a.scoped[perm]
+		if scopes == nil {
+			scopes = set.NewSet()
+			a.scoped[perm] = scopes
+		}
+
+		for _, role := range roles {
+			scopes.Add(role)
+		}
+	} else {
+		a.unscoped.Add(perm)
+	}
+}
+
+// Check if permission is granted on any object
+func (a *Access) Allowed(perm string) bool {
+	if a.Super || a.unscoped.Contains(perm) {
+		return true
+	}
+
+	_, ok := a.scoped[perm]
+	return ok
+}
+
+// Check if any permission is granted
+func (a *Access) Any() bool {
+	return a.Super || a.unscoped.Len() != 0 || len(a.scoped) != 0
+}
+
+// Check if permission is granted without a scope
+func (a *Access) Unscoped(perm string) bool {
+	return a.Super || a.unscoped.Contains(perm)
+}
+
+// Get roles permission is scoped to, nil when unscoped
+func (a *Access) Scopes(perm string) []string {
+	if a.Unscoped(perm) {
+		return nil
+	}
+
+	roles := []string{}
+	scopes := a.scoped[perm]
+	if scopes != nil {
+		for role := range scopes.Iter() {
+			roles = append(roles, role.(string))
+		}
+	}
+	sort.Strings(roles)
+
+	return roles
+}
+
+// Check if permission is granted on an object with roles
+func (a *Access) AllowedRoles(perm string, roles []string) bool {
+	if a.Unscoped(perm) {
+		return true
+	}
+
+	scopes := a.scoped[perm]
+	if scopes == nil {
+		return false
+	}
+
+	for _, role := range roles {
+		if scopes.Contains(role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if all roles are within permission scope
+func (a *Access) AllowedRolesAll(perm string, roles []string) bool {
+	if a.Unscoped(perm) {
+		return true
+	}
+
+	scopes := a.scoped[perm]
+	if scopes == nil || len(roles) == 0 {
+		return false
+	}
+
+	for _, role := range roles {
+		if !scopes.Contains(role) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (a *Access) Permissions() (perms []string) {
+	perms = []string{}
+
+	for _, perm := range permissions {
+		if a.Allowed(perm) {
+			perms = append(perms, perm)
+		}
+	}
+
+	return
+}
+
+func (a *Access) PermissionScopes() (scopes map[string][]string) {
+	scopes = map[string][]string{}
+
+	for perm := range a.scoped {
+		if !a.Unscoped(perm) {
+			scopes[perm] = a.Scopes(perm)
+		}
+	}
+
+	return
+}
@@ -0,0 +1,117 @@
+package adminrole
+
+import (
+	"sort"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type AdminRole struct {
+	Id          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Permissions []string           `bson:"permissions" json:"permissions"`
+	Roles       []string           `bson:"roles" json:"roles"`
+}
+
+func (r *AdminRole) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	r.Name = utils.FilterStr(r.Name, 64)
+
+	if r.Name == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "admin_role_name_missing",
+			Message: "Admin role name is required",
+		}
+		return
+	}
+
+	if r.Permissions == nil {
+		r.Permissions = []string{}
+	}
+
+	if r.Roles == nil {
+		r.Roles = []string{}
+	}
+
+	permsSet := set.NewSet()
+	for _, perm := range r.Permissions {
+		if !permissionsSet.Contains(perm) {
+			errData = &errortypes.ErrorData{
+				Error:   "admin_role_permission_invalid",
+				Message: "Admin role permission is invalid",
+			}
+			return
+		}
+		permsSet.Add(perm)
+	}
+
+	perms := []string{}
+	for perm := range permsSet.Iter() {
+		perms = append(perms, perm.(string))
+	}
+	sort.Strings(perms)
+	r.Permissions = perms
+
+	rolesSet := set.NewSet()
+	for _, role := range r.Roles {
+		rolesSet.Add(role)
+	}
+
+	roles := []string{}
+	for role := range rolesSet.Iter() {
+		roles = append(roles, role.(string))
+	}
+	sort.Strings(roles)
+	r.Roles = roles
+
+	return
+}
+
+func (r *AdminRole) Commit(db *database.Database) (err error) {
+	coll := db.AdminRoles()
+
+	err = coll.Commit(r.Id, r)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *AdminRole) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.AdminRoles()
+
+	err = coll.CommitFields(r.Id, r, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (r *AdminRole) Insert(db *database.Database) (err error) {
+	coll := db.AdminRoles()
+
+	if !r.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("adminrole: Admin role already exists"),
+		}
+		return
+	}
+
+	_, err = coll.InsertOne(db, r)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,84 @@
+package adminrole
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+)
+
+const (
+	Super = "super"
+
+	AlertsRead        = "alerts:read"
+	AlertsWrite       = "alerts:write"
+	AuditRead         = "audit:read"
+	AuthoritiesRead   = "authorities:read"
+	AuthoritiesWrite  = "authorities:write"
+	AuthoritiesSign   = "authorities:sign"
+	CertificatesRead  = "certificates:read"
+	CertificatesWrite = "certificates:write"
+	ChecksRead        = "checks:read"
+	ChecksWrite       = "checks:write"
+	DevicesRead       = "devices:read"
+	DevicesWrite      = "devices:write"
+	EndpointsRead     = "endpoints:read"
+	EndpointsWrite    = "endpoints:write"
+	LogsRead          = "logs:read"
+	NodesRead         = "nodes:read"
+	NodesWrite        = "nodes:write"
+	PoliciesRead      = "policies:read"
+	PoliciesWrite     = "policies:write"
+	ServicesRead      = "services:read"
+	ServicesWrite     = "services:write"
+	SessionsRead      = "sessions:read"
+	SessionsWrite     = "sessions:write"
+	SettingsRead      = "settings:read"
+	SettingsWrite     = "settings:write"
+	UsersRead         = "users:read"
+	UsersWrite        = "users:write"
+	UsersDisable      = "users:disable"
+)
+
+var (
+	permissions = []string{
+		AlertsRead,
+		AlertsWrite,
+		AuditRead,
+		AuthoritiesRead,
+		AuthoritiesWrite,
+		AuthoritiesSign,
+		CertificatesRead,
+		CertificatesWrite,
+		ChecksRead,
+		ChecksWrite,
+		DevicesRead,
+		DevicesWrite,
+		EndpointsRead,
+		EndpointsWrite,
+		LogsRead,
+		NodesRead,
+		NodesWrite,
+		PoliciesRead,
+		PoliciesWrite,
+		ServicesRead,
+		ServicesWrite,
+		SessionsRead,
+		SessionsWrite,
+		SettingsRead,
+		SettingsWrite,
+		UsersRead,
+		UsersWrite,
+		UsersDisable,
+	}
+	permissionsSet = set.NewSet()
+	scopable       = set.NewSet(
+		PoliciesRead,
+		PoliciesWrite,
+		ServicesRead,
+		ServicesWrite,
+	)
+)
+
+func init() {
+	for _, perm := range permissions {
+		permissionsSet.Add(perm)
+	}
+}
@@ -0,0 +1,183 @@
+package adminrole
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func Get(db *database.Database, roleId primitive.ObjectID) (
+	role *AdminRole, err error) {
+
+	coll := db.AdminRoles()
+	role = &AdminRole{}
+
+	err = coll.FindOneId(roleId, role)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetMulti(db *database.Database, roleIds []primitive.ObjectID) (
+	roles []*AdminRole, err error) {
+
+	coll := db.AdminRoles()
+	roles = []*AdminRole{}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"_id": &bson.M{
+			"$in": roleIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		role := &AdminRole{}
+		err = cursor.Decode(role)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		roles = append(roles, role)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database) (roles []*AdminRole, err error) {
+	coll := db.AdminRoles()
+	roles = []*AdminRole{}
+
+	cursor, err := coll.Find(db, &bson.M{}, &options.FindOptions{
+		Sort: &bson.D{
+			{"name", 1},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		role := &AdminRole{}
+		err = cursor.Decode(role)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		roles = append(roles, role)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Check if user has administrator access, legacy permissions are only
+// counted when valid
+func IsAdmin(usr *user.User) bool {
+	if usr.Administrator == Super || len(usr.AdminRoles) != 0 {
+		return true
+	}
+
+	for _, perm := range usr.Permissions {
+		if permissionsSet.Contains(perm) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GetAccess(db *database.Database, usr *user.User) (
+	access *Access, err error) {
+
+	access = &Access{
+		Super:    usr.Administrator == Super,
+		unscoped: set.NewSet(),
+		scoped:   map[string]set.Set{},
+	}
+
+	if access.Super {
+		return
+	}
+
+	for _, perm := range usr.Permissions {
+		if permissionsSet.Contains(perm) {
+			access.add(perm, nil)
+		}
+	}
+
+	if len(usr.AdminRoles) == 0 {
+		return
+	}
+
+	roles, err := GetMulti(db, usr.AdminRoles)
+	if err != nil {
+		return
+	}
+
+	for _, role := range roles {
+		for _, perm := range role.Permissions {
+			access.add(perm, role.Roles)
+		}
+	}
+
+	return
+}
+
+func Remove(db *database.Database, roleId primitive.ObjectID) (err error) {
+	coll := db.AdminRoles()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": roleId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	coll = db.Users()
+
+	_, err = coll.UpdateMany(db, &bson.M{
+		"admin_roles": roleId,
+	}, &bson.M{
+		"$pull": &bson.M{
+			"admin_roles": roleId,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
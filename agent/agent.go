@@ -2,6 +2,7 @@ package agent
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/geo"
@@ -62,6 +63,19 @@ const (
 	Kindle       = "kindle"        // Kindle = Kindle
 )
 
+const (
+	PlatformLinux        = "linux"
+	PlatformMacOs        = "macos"
+	PlatformWindows      = "windows"
+	PlatformChromeOs     = "chrome_os"
+	PlatformIos          = "ios"
+	PlatformAndroid      = "android"
+	PlatformBlackberry   = "blackberry"
+	PlatformWindowsPhone = "windows_phone"
+	PlatformFirefoxOs    = "firefox_os"
+	PlatformKindle       = "kindle"
+)
+
 const (
 	Chrome                 = "chrome"                   // Chrome = Chrome + Chromium
 	ChromeMobile           = "chrome_mobile"            // Chrome Mobile = Chrome Mobile + Chrome Mobile iOS + Chrome Mobile WebView
@@ -77,19 +91,22 @@ const (
 )
 
 type Agent struct {
-	OperatingSystem string  `bson:"operating_system" json:"operating_system"`
-	Browser         string  `bson:"browser" json:"browser"`
-	Ip              string  `bson:"ip" json:"ip"`
-	Isp             string  `bson:"isp" json:"isp"`
-	Continent       string  `bson:"continent" json:"continent"`
-	ContinentCode   string  `bson:"continent_code" json:"continent_code"`
-	Country         string  `bson:"country" json:"country"`
-	CountryCode     string  `bson:"country_code" json:"country_code"`
-	Region          string  `bson:"region" json:"region"`
-	RegionCode      string  `bson:"region_code" json:"region_code"`
-	City            string  `bson:"city" json:"city"`
-	Latitude        float64 `bson:"latitude" json:"latitude"`
-	Longitude       float64 `bson:"longitude" json:"longitude"`
+	OperatingSystem        string  `bson:"operating_system" json:"operating_system"`
+	Browser                string  `bson:"browser" json:"browser"`
+	Platform               string  `bson:"platform" json:"platform"`
+	OperatingSystemVersion string  `bson:"operating_system_version" json:"operating_system_version"`
+	BrowserVersion         string  `bson:"browser_version" json:"browser_version"`
+	Ip                     string  `bson:"ip" json:"ip"`
+	Isp                    string  `bson:"isp" json:"isp"`
+	Continent              string  `bson:"continent" json:"continent"`
+	ContinentCode          string  `bson:"continent_code" json:"continent_code"`
+	Country                string  `bson:"country" json:"country"`
+	CountryCode            string  `bson:"country_code" json:"country_code"`
+	Region                 string  `bson:"region" json:"region"`
+	RegionCode             string  `bson:"region_code" json:"region_code"`
+	City                   string  `bson:"city" json:"city"`
+	Latitude               float64 `bson:"latitude" json:"latitude"`
+	Longitude              float64 `bson:"longitude" json:"longitude"`
 }
 
 func Parse(db *database.Database, r *http.Request) (agnt *Agent, err error) {
@@ -124,159 +141,99 @@ func Parse(db *database.Database, r *http.Request) (agnt *Agent, err error) {
 		Latitude:      ge.Latitude,
 	}
 
+	agnt.OperatingSystemVersion = version(
+		client.Os.Major, client.Os.Minor, client.Os.Patch)
+	agnt.BrowserVersion = version(client.UserAgent.Major,
+		client.UserAgent.Minor, client.UserAgent.Patch)
+
+	// Versioned labels are built from the parsed version so new releases
+	// do not require a new constant
 	switch client.Os.Family {
 	case "Android":
-		switch client.Os.Major {
-		case "4":
+		agnt.Platform = PlatformAndroid
+		if client.Os.Major == "4" {
 			if client.Os.Minor == "4" {
 				agnt.OperatingSystem = Android4
-				break
 			}
-			break
-		case "5":
-			agnt.OperatingSystem = Android5
-			break
-		case "6":
-			agnt.OperatingSystem = Android6
-			break
-		case "7":
-			agnt.OperatingSystem = Android7
-			break
-		case "8":
-			agnt.OperatingSystem = Android8
-			break
-		case "9":
-			agnt.OperatingSystem = Android9
-			break
-		case "10":
-			agnt.OperatingSystem = Android10
-			break
-		case "11":
-			agnt.OperatingSystem = Android11
-			break
-		case "12":
-			agnt.OperatingSystem = Android12
-			break
-		case "13":
-			agnt.OperatingSystem = Android13
-			break
-		case "14":
-			agnt.OperatingSystem = Android14
-			break
+		} else if atLeast(client.Os.Major, 5) {
+			agnt.OperatingSystem = "android_" + client.Os.Major
 		}
 		break
 	case "BlackBerry OS":
+		agnt.Platform = PlatformBlackberry
 		if client.Os.Major == "10" {
 			agnt.OperatingSystem = Blackberry10
 			break
 		}
 		break
 	case "Firefox OS":
+		agnt.Platform = PlatformFirefoxOs
 		agnt.OperatingSystem = FirefoxOs
 		break
 	case "iOS":
-		switch client.Os.Major {
-		case "8":
-			agnt.OperatingSystem = Ios8
-			break
-		case "9":
-			agnt.OperatingSystem = Ios9
-			break
-		case "10":
-			agnt.OperatingSystem = Ios10
-			break
-		case "11":
-			agnt.OperatingSystem = Ios11
-			break
-		case "12":
-			agnt.OperatingSystem = Ios12
-			break
-		case "13":
-			agnt.OperatingSystem = Ios13
-			break
-		case "14":
-			agnt.OperatingSystem = Ios14
-			break
-		case "15":
-			agnt.OperatingSystem = Ios15
-			break
-		case "16":
-			agnt.OperatingSystem = Ios16
-			break
-		case "17":
-			agnt.OperatingSystem = Ios17
-			break
-		case "18":
-			agnt.OperatingSystem = Ios18
-			break
+		agnt.Platform = PlatformIos
+		if atLeast(client.Os.Major, 8) {
+			agnt.OperatingSystem = "ios_" + client.Os.Major
 		}
 		break
 	case "Kindle":
+		agnt.Platform = PlatformKindle
 		agnt.OperatingSystem = Kindle
 		break
 	case "Mac OS X":
-		switch client.Os.Major {
-		case "10":
-			switch client.Os.Minor {
-			case "10":
-				agnt.OperatingSystem = MacOs1010
-				break
-			case "11":
-				agnt.OperatingSystem = MacOs1011
-				break
-			case "12":
-				agnt.OperatingSystem = MacOs1012
-				break
-			case "13":
-				agnt.OperatingSystem = MacOs1013
-				break
-			case "14":
-				agnt.OperatingSystem = MacOs1014
-				break
-			case "15":
-				agnt.OperatingSystem = MacOs1015
-				break
+		agnt.Platform = PlatformMacOs
+		if client.Os.Major == "10" {
+			if atLeast(client.Os.Minor, 10) {
+				agnt.OperatingSystem = "macos_10" + client.Os.Minor
 			}
-			break
-		case "11":
-			agnt.OperatingSystem = MacOs11
-			break
-		case "12":
-			agnt.OperatingSystem = MacOs12
-			break
-		case "13":
-			agnt.OperatingSystem = MacOs13
-			break
-		case "14":
-			agnt.OperatingSystem = MacOs14
-			break
+		} else if atLeast(client.Os.Major, 11) {
+			agnt.OperatingSystem = "macos_" + client.Os.Major
 		}
 		break
 	case "Windows Phone":
+		agnt.Platform = PlatformWindowsPhone
 		agnt.OperatingSystem = WindowsPhone
 		break
 	case "Windows XP":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = WindowsXp
+		agnt.OperatingSystemVersion = "5.1"
 		break
 	case "Windows 7":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = Windows7
+		agnt.OperatingSystemVersion = "6.1"
 		break
 	case "Windows Vista":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = WindowsVista
+		agnt.OperatingSystemVersion = "6.0"
 		break
 	case "Windows 8", "Windows 8.1", "Windows RT 8.1":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = Windows8
+		if strings.HasSuffix(client.Os.Family, "8.1") {
+			agnt.OperatingSystemVersion = "6.3"
+		} else {
+			agnt.OperatingSystemVersion = "6.2"
+		}
 		break
 	case "Windows 10":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = Windows10
+		agnt.OperatingSystemVersion = "10"
 		break
 	case "Windows 11":
+		agnt.Platform = PlatformWindows
 		agnt.OperatingSystem = Windows11
+		agnt.OperatingSystemVersion = "11"
 		break
 	case "Chrome OS":
+		agnt.Platform = PlatformChromeOs
 		agnt.OperatingSystem = ChromeOs
 		break
 	case "Linux", "Debian", "Ubuntu":
+		agnt.Platform = PlatformLinux
 		agnt.OperatingSystem = Linux
 		break
 	}
@@ -323,6 +280,9 @@ func Parse(db *database.Database, r *http.Request) (agnt *Agent, err error) {
 func (a *Agent) Diff(agnt *Agent) bool {
 	if a.OperatingSystem != agnt.OperatingSystem ||
 		a.Browser != agnt.Browser ||
+		a.Platform != agnt.Platform ||
+		a.OperatingSystemVersion != agnt.OperatingSystemVersion ||
+		a.BrowserVersion != agnt.BrowserVersion ||
 		a.Ip != agnt.Ip ||
 		a.Isp != agnt.Isp ||
 		a.Continent != agnt.Continent ||
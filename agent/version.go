@@ -0,0 +1,76 @@
+package agent
+
+import (
+	"strconv"
+	"strings"
+)
+
+func version(major, minor, patch string) string {
+	parts := []string{}
+	for _, part := range []string{major, minor, patch} {
+		if part == "" {
+			break
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, ".")
+}
+
+func atLeast(value string, min int) bool {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return false
+	}
+	return n >= min
+}
+
+// Parse dotted version into numeric components, non numeric suffixes
+// of a component are ignored
+func ParseVersion(ver string) (parts []int, ok bool) {
+	ver = strings.TrimSpace(ver)
+	if ver == "" {
+		return
+	}
+
+	for _, part := range strings.Split(ver, ".") {
+		end := 0
+		for end < len(part) && part[end] >= '0' && part[end] <= '9' {
+			end += 1
+		}
+
+		if end == 0 {
+			return
+		}
+
+		n, err := strconv.Atoi(part[:end])
+		if err != nil {
+			return
+		}
+		parts = append(parts, n)
+	}
+
+	ok = true
+	return
+}
+
+// Compare dotted versions returning -1, 0 or 1, missing components are
+// treated as zero
+func CompareVersion(x, y []int) int {
+	for i := 0; i < len(x) || i < len(y); i++ {
+		a := 0
+		b := 0
+		if i < len(x) {
+			a = x[i]
+		}
+		if i < len(y) {
+			b = y[i]
+		}
+
+		if a < b {
+			return -1
+		} else if a > b {
+			return 1
+		}
+	}
+	return 0
+}
@@ -18,6 +18,94 @@ type Alert struct {
 	Ignores   []string           `bson:"ignores" json:"ignores"`
 	ValueInt  int                `bson:"value_int" json:"value_int"`
 	ValueStr  string             `bson:"value_str" json:"value_str"`
+	Duration  int                `bson:"duration" json:"duration"`
+	ClearInt  int                `bson:"clear_int" json:"clear_int"`
+	Aggregate string             `bson:"aggregate" json:"aggregate"`
+	Window    int                `bson:"window" json:"window"`
+	Quantile  int                `bson:"quantile" json:"quantile"`
+}
+
+// Check if alert is evaluated against the stored time series instead of
+// only the latest sample
+func (a *Alert) HasCondition() bool {
+	return a.Duration > 0 || a.ClearInt > 0 || a.Aggregate != Latest
+}
+
+// Check if device, interface or mount name is ignored by alert
+func (a *Alert) Ignored(name string) bool {
+	for _, ignore := range a.Ignores {
+		if ignore == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Alert) validateCondition() (errData *errortypes.ErrorData) {
+	switch a.Resource {
+	case SystemCpuLevel, SystemMemoryLevel, SystemSwapLevel,
+		SystemHugePagesLevel, DiskUsageLevel:
+
+		break
+	default:
+		a.Duration = 0
+		a.ClearInt = 0
+		a.Aggregate = Latest
+		a.Window = 0
+		a.Quantile = 0
+		return
+	}
+
+	if a.Duration < 0 || a.Duration > 86400 || a.Duration%60 != 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_duration_invalid",
+			Message: "Alert duration must be whole minutes up to one day",
+		}
+		return
+	}
+
+	if a.ClearInt < 0 || a.ClearInt > a.ValueInt {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_clear_invalid",
+			Message: "Alert clear value cannot exceed trigger value",
+		}
+		return
+	}
+
+	switch a.Aggregate {
+	case Latest:
+		a.Window = 0
+		a.Quantile = 0
+		return
+	case Average, Maximum:
+		a.Quantile = 0
+		break
+	case Percentile:
+		if a.Quantile < 1 || a.Quantile > 99 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_quantile_invalid",
+				Message: "Alert percentile must be between 1 and 99",
+			}
+			return
+		}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "alert_aggregate_invalid",
+			Message: "Alert aggregate is invalid",
+		}
+		return
+	}
+
+	if a.Window < 60 || a.Window > 86400 || a.Window%60 != 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_window_invalid",
+			Message: "Alert window must be whole minutes up to one day",
+		}
+		return
+	}
+
+	return
 }
 
 func (a *Alert) Validate(db *database.Database) (
@@ -54,7 +142,7 @@ func (a *Alert) Validate(db *database.Database) (
 		return
 	}
 
-	if a.Ignores != nil {
+	if a.Ignores == nil {
 		a.Ignores = []string{}
 	}
 
@@ -103,6 +191,72 @@ func (a *Alert) Validate(db *database.Database) (
 		a.ValueInt = 0
 		a.ValueStr = ""
 		break
+	case SystemPackageUpdates:
+		if a.ValueInt < 1 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
+	case SystemRebootRequired:
+		a.ValueInt = 0
+		a.ValueStr = ""
+		break
+	case SystemUptimeLevel:
+		if a.ValueInt < 1 || a.ValueInt > 3650 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
+	case SystemClockDrift:
+		if a.ValueInt < 1 || a.ValueInt > 3600 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
+	case LoadLevel:
+		if a.ValueInt < 1 || a.ValueInt > 10000 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
+	case DiskIoUtilLevel:
+		if a.ValueInt < 1 || a.ValueInt > 100 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
+	case DiskIoAwaitLevel, NetworkErrorRate, NetworkDropRate,
+		NetworkThroughput:
+
+		if a.ValueInt < 1 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
 	case DiskUsageLevel:
 		if a.ValueInt < 1 || a.ValueInt > 100 {
 			errData = &errortypes.ErrorData{
@@ -123,10 +277,20 @@ func (a *Alert) Validate(db *database.Database) (
 		}
 		a.ValueInt = 0
 		break
-	case CheckHttpFailed:
+	case CheckHttpFailed, CheckTcpFailed, CheckDnsFailed, CheckTlsFailed:
 		a.ValueInt = 0
 		a.ValueStr = ""
 		break
+	case CheckTlsExpiry:
+		if a.ValueInt < 1 || a.ValueInt > 365 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_value_invalid",
+				Message: "Alert value is invalid",
+			}
+			return
+		}
+		a.ValueStr = ""
+		break
 	default:
 		errData = &errortypes.ErrorData{
 			Error:   "alert_resource_invalid",
@@ -135,6 +299,11 @@ func (a *Alert) Validate(db *database.Database) (
 		return
 	}
 
+	errData = a.validateCondition()
+	if errData != nil {
+		return
+	}
+
 	switch a.Level {
 	case Low, Medium, High:
 		break
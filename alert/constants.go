@@ -13,7 +13,28 @@ const (
 	SystemSwapLevel      = "system_swap_level"
 	SystemHugePagesLevel = "system_hugepages_level"
 	SystemMdFailed       = "system_md_failed"
+	SystemPackageUpdates = "system_package_updates"
+	SystemRebootRequired = "system_reboot_required"
+	SystemUptimeLevel    = "system_uptime_level"
+	SystemClockDrift     = "system_clock_drift"
+	LoadLevel            = "load_level"
 	DiskUsageLevel       = "disk_usage_level"
+	DiskIoUtilLevel      = "diskio_util_level"
+	DiskIoAwaitLevel     = "diskio_await_level"
+	NetworkErrorRate     = "network_error_rate"
+	NetworkDropRate      = "network_drop_rate"
+	NetworkThroughput    = "network_throughput"
 	KmsgKeyword          = "kmsg_keyword"
 	CheckHttpFailed      = "check_http_failed"
+	CheckTcpFailed       = "check_tcp_failed"
+	CheckDnsFailed       = "check_dns_failed"
+	CheckTlsFailed       = "check_tls_failed"
+	CheckTlsExpiry       = "check_tls_expiry"
+)
+
+const (
+	Latest     = ""
+	Average    = "average"
+	Maximum    = "maximum"
+	Percentile = "percentile"
 )
@@ -0,0 +1,170 @@
+package alertchannel
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Channel struct {
+	Id          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name        string             `bson:"name" json:"name"`
+	Type        string             `bson:"type" json:"type"`
+	Disabled    bool               `bson:"disabled" json:"disabled"`
+	Roles       []string           `bson:"roles" json:"roles"`
+	AlertLevels []int              `bson:"alert_levels" json:"alert_levels"`
+	Url         string             `bson:"url" json:"url"`
+	Secret      string             `bson:"secret" json:"-"`
+	Template    string             `bson:"template" json:"template"`
+	Recipients  []string           `bson:"recipients" json:"recipients"`
+}
+
+func (c *Channel) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	c.Name = strings.TrimSpace(c.Name)
+	c.Url = strings.TrimSpace(c.Url)
+
+	if c.Roles == nil {
+		c.Roles = []string{}
+	}
+
+	if c.AlertLevels == nil {
+		c.AlertLevels = []int{}
+	}
+
+	recipients := []string{}
+	for _, recipient := range c.Recipients {
+		recipient = strings.TrimSpace(recipient)
+		if recipient != "" {
+			recipients = append(recipients, recipient)
+		}
+	}
+	c.Recipients = recipients
+
+	switch c.Type {
+	case Webhook, Slack, Mattermost:
+		u, e := url.Parse(c.Url)
+		if e != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+			u.Host == "" {
+
+			errData = &errortypes.ErrorData{
+				Error:   "alert_channel_url_invalid",
+				Message: "Alert channel URL is invalid",
+			}
+			return
+		}
+
+		if c.Type == Webhook && c.Template != "" {
+			_, e = render(c.Template, testMessage())
+			if e != nil {
+				errData = &errortypes.ErrorData{
+					Error:   "alert_channel_template_invalid",
+					Message: e.Error(),
+				}
+				return
+			}
+		}
+
+		if c.Type != Webhook {
+			c.Secret = ""
+			c.Template = ""
+		}
+		c.Recipients = []string{}
+		break
+	case Email:
+		if len(c.Recipients) == 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_channel_recipients_invalid",
+				Message: "Email alert channel requires a recipient",
+			}
+			return
+		}
+
+		c.Url = ""
+		c.Secret = ""
+		c.Template = ""
+		break
+	case PagerDuty:
+		if c.Secret == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "alert_channel_secret_invalid",
+				Message: "PagerDuty alert channel requires a routing key",
+			}
+			return
+		}
+
+		c.Url = ""
+		c.Template = ""
+		c.Recipients = []string{}
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "alert_channel_type_invalid",
+			Message: "Alert channel type is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+func (c *Channel) CheckLevel(level int) bool {
+	for _, lvl := range c.AlertLevels {
+		if level == lvl {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Channel) Commit(db *database.Database) (err error) {
+	coll := db.AlertChannels()
+
+	err = coll.Commit(c.Id, c)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *Channel) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.AlertChannels()
+
+	err = coll.CommitFields(c.Id, c, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *Channel) Insert(db *database.Database) (err error) {
+	coll := db.AlertChannels()
+
+	if !c.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("alertchannel: Channel already exists"),
+		}
+		return
+	}
+
+	c.Id = primitive.NewObjectID()
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
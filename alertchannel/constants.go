@@ -0,0 +1,22 @@
+package alertchannel
+
+import (
+	"time"
+)
+
+const (
+	Webhook    = "webhook"
+	Email      = "email"
+	Slack      = "slack"
+	Mattermost = "mattermost"
+	PagerDuty  = "pagerduty"
+
+	Firing   = "firing"
+	Resolved = "resolved"
+
+	SignatureHeader = "X-Pritunl-Signature"
+	PagerDutyUrl    = "https://events.pagerduty.com/v2/enqueue"
+
+	Attempts     = 3
+	RetryBackoff = 10 * time.Second
+)
@@ -0,0 +1,255 @@
+package alertchannel
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/mail"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	client = &http.Client{
+		Timeout: 15 * time.Second,
+	}
+)
+
+type pagerDutyPayload struct {
+	Summary   string `json:"summary"`
+	Source    string `json:"source"`
+	Severity  string `json:"severity"`
+	Component string `json:"component"`
+	Timestamp string `json:"timestamp"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload"`
+}
+
+func post(url string, body []byte, headers map[string]string) (
+	statusCode int, err error) {
+
+	req, err := http.NewRequest("POST", url, bytes.NewBuffer(body))
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "alertchannel: Failed to create request"),
+		}
+		return
+	}
+
+	req.Header.Set("User-Agent", "pritunl-zero")
+	req.Header.Set("Content-Type", "application/json")
+	for key, val := range headers {
+		req.Header.Set(key, val)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "alertchannel: Request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	statusCode = resp.StatusCode
+	if statusCode < 200 || statusCode >= 300 {
+		data, _ := ioutil.ReadAll(resp.Body)
+		err = &errortypes.RequestError{
+			errors.Newf("alertchannel: Bad status %d - %s",
+				statusCode, strings.TrimSpace(string(data))),
+		}
+		return
+	}
+
+	return
+}
+
+func sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (c *Channel) send(msg *Message) (statusCode int, err error) {
+	switch c.Type {
+	case Webhook:
+		var body []byte
+		if c.Template != "" {
+			body, err = render(c.Template, msg)
+		} else {
+			body, err = json.Marshal(msg)
+		}
+		if err != nil {
+			return
+		}
+
+		headers := map[string]string{}
+		if c.Secret != "" {
+			headers[SignatureHeader] = sign(c.Secret, body)
+		}
+
+		statusCode, err = post(c.Url, body, headers)
+		break
+	case Slack, Mattermost:
+		label := strings.ToUpper(msg.LevelName())
+		if msg.IsResolved() {
+			label = "RESOLVED"
+		}
+
+		text := fmt.Sprintf("*[%s] %s*\n%s: %s",
+			label, msg.Name, msg.SourceName, msg.Message)
+		if msg.AckUrl != "" && !msg.IsResolved() {
+			text += fmt.Sprintf("\n<%s|Acknowledge>", msg.AckUrl)
+		}
+
+		body, _ := json.Marshal(map[string]string{
+			"text": text,
+		})
+
+		statusCode, err = post(c.Url, body, nil)
+		break
+	case PagerDuty:
+		severity := "info"
+		switch msg.LevelName() {
+		case "high":
+			severity = "critical"
+		case "medium":
+			severity = "warning"
+		}
+
+		action := "trigger"
+		if msg.IsResolved() {
+			action = "resolve"
+		}
+
+		body, _ := json.Marshal(&pagerDutyEvent{
+			RoutingKey:  c.Secret,
+			EventAction: action,
+			DedupKey:    msg.dedupKey(),
+			Payload: &pagerDutyPayload{
+				Summary:   msg.Summary(),
+				Source:    msg.SourceName,
+				Severity:  severity,
+				Component: msg.Resource,
+				Timestamp: msg.Timestamp.Format(time.RFC3339),
+			},
+		})
+
+		statusCode, err = post(PagerDutyUrl, body, nil)
+		break
+	case Email:
+		subject := "Pritunl Zero Alert: " + msg.Name
+		if msg.IsResolved() {
+			subject = "Pritunl Zero Alert Resolved: " + msg.Name
+		}
+
+		body := fmt.Sprintf(
+			"Alert: %s\nSource: %s\nResource: %s\n"+
+				"Level: %s\nTime: %s\n\n%s\n",
+			msg.Name, msg.SourceName, msg.Resource,
+			msg.LevelName(), msg.Timestamp.Format(time.RFC1123),
+			msg.Message)
+		if msg.AckUrl != "" && !msg.IsResolved() {
+			body += fmt.Sprintf("\nAcknowledge: %s\n", msg.AckUrl)
+		}
+
+		err = mail.Send(&mail.Message{
+			To:      c.Recipients,
+			Subject: subject,
+			Body:    body,
+		})
+		break
+	default:
+		err = &errortypes.UnknownError{
+			errors.Newf("alertchannel: Unknown channel type '%s'", c.Type),
+		}
+	}
+
+	return
+}
+
+// Deliver message with retries, each attempt is logged
+func (c *Channel) Deliver(db *database.Database, msg *Message) (err error) {
+	for attempt := 1; attempt <= Attempts; attempt++ {
+		if attempt > 1 {
+			time.Sleep(time.Duration(attempt-1) * RetryBackoff)
+		}
+
+		statusCode, e := c.send(msg)
+
+		lg := &Log{
+			Channel:    c.Id,
+			Alert:      msg.Id,
+			Attempt:    attempt,
+			Success:    e == nil,
+			StatusCode: statusCode,
+			Timestamp:  time.Now(),
+		}
+		if e != nil {
+			lg.Error = e.Error()
+		}
+
+		err = lg.Insert(db)
+		if err != nil {
+			return
+		}
+
+		if e == nil {
+			return
+		}
+
+		logrus.WithFields(logrus.Fields{
+			"channel_id": c.Id.Hex(),
+			"alert":      msg.Id,
+			"attempt":    attempt,
+			"error":      e,
+		}).Warn("alertchannel: Alert delivery attempt failed")
+
+		err = e
+	}
+
+	return
+}
+
+// Deliver message to channels in background
+func Dispatch(channels []*Channel, msg *Message) {
+	for _, chnl := range channels {
+		go func(chnl *Channel) {
+			db := database.GetDatabase()
+			defer db.Close()
+
+			err := chnl.Deliver(db, msg)
+			if err != nil {
+				logrus.WithFields(logrus.Fields{
+					"channel_id": chnl.Id.Hex(),
+					"alert":      msg.Id,
+					"error":      err,
+				}).Error("alertchannel: Failed to deliver alert")
+			}
+		}(chnl)
+	}
+}
+
+func SendTest(db *database.Database, chnl *Channel) (err error) {
+	err = chnl.Deliver(db, testMessage())
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,74 @@
+package alertchannel
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+)
+
+type Log struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Channel    primitive.ObjectID `bson:"channel" json:"channel"`
+	Alert      string             `bson:"alert" json:"alert"`
+	Attempt    int                `bson:"attempt" json:"attempt"`
+	Success    bool               `bson:"success" json:"success"`
+	StatusCode int                `bson:"status_code" json:"status_code"`
+	Error      string             `bson:"error" json:"error"`
+	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
+}
+
+func (l *Log) Insert(db *database.Database) (err error) {
+	coll := db.AlertChannelLogs()
+
+	_, err = coll.InsertOne(db, l)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Get recent delivery attempts for channel newest first
+func GetLogs(db *database.Database, chnlId primitive.ObjectID,
+	limit int64) (logs []*Log, err error) {
+
+	coll := db.AlertChannelLogs()
+	logs = []*Log{}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"channel": chnlId,
+	}, &options.FindOptions{
+		Sort: &bson.D{
+			{"timestamp", -1},
+		},
+		Limit: &limit,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		lg := &Log{}
+		err = cursor.Decode(lg)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		logs = append(logs, lg)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
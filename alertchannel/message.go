@@ -0,0 +1,110 @@
+package alertchannel
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"text/template"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Message struct {
+	Id         string    `json:"id"`
+	Instance   string    `json:"instance"`
+	State      string    `json:"state"`
+	AckUrl     string    `json:"ack_url"`
+	Name       string    `json:"name"`
+	Source     string    `json:"source"`
+	SourceName string    `json:"source_name"`
+	Resource   string    `json:"resource"`
+	Level      int       `json:"level"`
+	Message    string    `json:"message"`
+	Timestamp  time.Time `json:"timestamp"`
+}
+
+func (m *Message) LevelName() string {
+	switch {
+	case m.Level >= 10:
+		return "high"
+	case m.Level >= 5:
+		return "medium"
+	default:
+		return "low"
+	}
+}
+
+func (m *Message) IsResolved() bool {
+	return m.State == Resolved
+}
+
+func (m *Message) Summary() string {
+	if m.IsResolved() {
+		return fmt.Sprintf("Resolved %s: %s - %s",
+			m.Name, m.SourceName, m.Message)
+	}
+	return fmt.Sprintf("%s: %s - %s", m.Name, m.SourceName, m.Message)
+}
+
+func (m *Message) dedupKey() string {
+	if m.Instance != "" {
+		return m.Instance
+	}
+	return m.Source + "-" + m.Resource
+}
+
+func testMessage() *Message {
+	return &Message{
+		Id:         "test",
+		State:      Firing,
+		Name:       "Test Alert",
+		Source:     "000000000000000000000000",
+		SourceName: "test",
+		Resource:   "test",
+		Level:      5,
+		Message:    "Test alert message",
+		Timestamp:  time.Now(),
+	}
+}
+
+var templateFuncs = template.FuncMap{
+	"json": func(val interface{}) (string, error) {
+		data, err := json.Marshal(val)
+		if err != nil {
+			return "", err
+		}
+		return string(data), nil
+	},
+}
+
+// Render webhook body template, the result must be valid JSON
+func render(tmpl string, msg *Message) (body []byte, err error) {
+	t, err := template.New("webhook").Funcs(templateFuncs).Parse(tmpl)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "alertchannel: Failed to parse template"),
+		}
+		return
+	}
+
+	buf := &bytes.Buffer{}
+	err = t.Execute(buf, msg)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "alertchannel: Failed to execute template"),
+		}
+		return
+	}
+
+	body = buf.Bytes()
+	if !json.Valid(body) {
+		err = &errortypes.ParseError{
+			errors.New("alertchannel: Template output is not valid JSON"),
+		}
+		return
+	}
+
+	return
+}
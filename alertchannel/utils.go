@@ -0,0 +1,159 @@
+package alertchannel
+
+import (
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+func Get(db *database.Database, chnlId primitive.ObjectID) (
+	chnl *Channel, err error) {
+
+	coll := db.AlertChannels()
+	chnl = &Channel{}
+
+	err = coll.FindOneId(chnlId, chnl)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetAllPaged(db *database.Database, query *bson.M,
+	page, pageCount int64) (channels []*Channel, count int64, err error) {
+
+	coll := db.AlertChannels()
+	channels = []*Channel{}
+
+	count, err = coll.CountDocuments(db, query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	maxPage := count / pageCount
+	if count == pageCount {
+		maxPage = 0
+	}
+	page = utils.Min64(page, maxPage)
+	skip := utils.Min64(page*pageCount, count)
+
+	cursor, err := coll.Find(
+		db,
+		query,
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"name", 1},
+			},
+			Skip:  &skip,
+			Limit: &pageCount,
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		chnl := &Channel{}
+		err = cursor.Decode(chnl)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		channels = append(channels, chnl)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Get enabled channels assigned to any of the roles for alert level
+func GetRolesLevel(db *database.Database, roles []string, level int) (
+	channels []*Channel, err error) {
+
+	coll := db.AlertChannels()
+	channels = []*Channel{}
+
+	if roles == nil {
+		roles = []string{}
+	}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"roles": &bson.M{
+				"$in": roles,
+			},
+			"disabled": false,
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		chnl := &Channel{}
+		err = cursor.Decode(chnl)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		if chnl.CheckLevel(level) {
+			channels = append(channels, chnl)
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, chnlId primitive.ObjectID) (err error) {
+	coll := db.AlertChannels()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": chnlId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemoveMulti(db *database.Database, chnlIds []primitive.ObjectID) (
+	err error) {
+
+	coll := db.AlertChannels()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": &bson.M{
+			"$in": chnlIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -6,6 +6,8 @@ import (
 
 	"github.com/pritunl/mongo-go-driver/bson"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/alertchannel"
+	"github.com/pritunl/pritunl-zero/alertinstance"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/device"
 	"github.com/pritunl/pritunl-zero/user"
@@ -14,6 +16,8 @@ import (
 
 type Alert struct {
 	Id         string             `bson:"_id" json:"_id"`
+	Alert      primitive.ObjectID `bson:"alert,omitempty" json:"alert"`
+	Instance   primitive.ObjectID `bson:"instance,omitempty" json:"instance"`
 	Name       string             `bson:"name" json:"name"`
 	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
 	Roles      []string           `bson:"roles" json:"roles"`
@@ -23,6 +27,7 @@ type Alert struct {
 	Resource   string             `bson:"resource" json:"resource"`
 	Message    string             `bson:"message" json:"message"`
 	Frequency  time.Duration      `bson:"frequency" json:"frequency"`
+	AckUrl     string             `bson:"-" json:"-"`
 }
 
 func (a *Alert) GetFrequency() (frequency time.Duration) {
@@ -58,13 +63,67 @@ func (a *Alert) Key(devc *device.Device) string {
 	)
 }
 
+func (a *Alert) ChannelKey(chnl *alertchannel.Channel) string {
+	timestamp := a.Timestamp.Unix()
+	timekey := timestamp - (timestamp % int64(a.GetFrequency().Seconds()))
+
+	return fmt.Sprintf(
+		"%s-%s-%s-%d",
+		a.Source.Hex(),
+		a.Resource,
+		chnl.Id.Hex(),
+		timekey,
+	)
+}
+
 func (a *Alert) Lock(db *database.Database, devc *device.Device) (
 	success bool, err error) {
 
+	success, err = lock(db, a.Key(devc))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (a *Alert) LockChannel(db *database.Database,
+	chnl *alertchannel.Channel) (success bool, err error) {
+
+	success, err = lock(db, a.ChannelKey(chnl))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (a *Alert) ChannelMessage() *alertchannel.Message {
+	instance := ""
+	if !a.Instance.IsZero() {
+		instance = a.Instance.Hex()
+	}
+
+	return &alertchannel.Message{
+		Id:         a.Id,
+		Instance:   instance,
+		State:      alertchannel.Firing,
+		AckUrl:     a.AckUrl,
+		Name:       a.Name,
+		Source:     a.Source.Hex(),
+		SourceName: a.SourceName,
+		Resource:   a.Resource,
+		Level:      a.Level,
+		Message:    a.Message,
+		Timestamp:  a.Timestamp,
+	}
+}
+
+func lock(db *database.Database, key string) (success bool, err error) {
 	coll := db.AlertsEventLock()
 
 	_, err = coll.InsertOne(db, &bson.M{
-		"_id":       a.Key(devc),
+		"_id":       key,
 		"timestamp": time.Now(),
 	})
 	if err != nil {
@@ -160,6 +219,26 @@ func (a *Alert) Send(db *database.Database, roles []string) (err error) {
 		}
 	}
 
+	channels, err := alertchannel.GetRolesLevel(db, roles, a.Level)
+	if err != nil {
+		return
+	}
+
+	locked := []*alertchannel.Channel{}
+	for _, chnl := range channels {
+		success, e := a.LockChannel(db, chnl)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if success {
+			locked = append(locked, chnl)
+		}
+	}
+
+	alertchannel.Dispatch(locked, a.ChannelMessage())
+
 	_, err = coll.InsertOne(db, a)
 	if err != nil {
 		err = database.ParseError(err)
@@ -172,7 +251,7 @@ func (a *Alert) Send(db *database.Database, roles []string) (err error) {
 	return
 }
 
-func New(roles []string, source primitive.ObjectID,
+func New(roles []string, source, check, alertId primitive.ObjectID,
 	name, sourceName, resource, message string, level int,
 	frequency time.Duration) {
 
@@ -180,6 +259,7 @@ func New(roles []string, source primitive.ObjectID,
 	defer db.Close()
 
 	alrt := &Alert{
+		Alert:      alertId,
 		Name:       name,
 		Timestamp:  time.Now(),
 		Roles:      roles,
@@ -193,7 +273,56 @@ func New(roles []string, source primitive.ObjectID,
 
 	alrt.Id = alrt.DocId()
 
-	err := alrt.Send(db, roles)
+	inst := &alertinstance.Instance{
+		Alert:      alertId,
+		Source:     source,
+		Check:      check,
+		SourceName: sourceName,
+		Name:       name,
+		Resource:   resource,
+		Level:      level,
+		Roles:      roles,
+		Message:    message,
+	}
+
+	notify, err := alertinstance.Fire(db, inst, alrt.GetFrequency())
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("alert: Failed to record alert instance")
+		return
+	}
+
+	if !notify {
+		return
+	}
+
+	suppressed, err := alertinstance.Suppressed(db, inst)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("alert: Failed to check alert silences")
+		return
+	}
+
+	if suppressed != inst.Silenced {
+		err = inst.SetSilenced(db, suppressed)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": err,
+			}).Error("alert: Failed to update alert instance")
+			return
+		}
+	}
+
+	if suppressed {
+		return
+	}
+
+	alrt.Instance = inst.Id
+	alrt.AckUrl = inst.AckUrl(db)
+
+	err = alrt.Send(db, roles)
 	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error": err,
@@ -202,3 +331,112 @@ func New(roles []string, source primitive.ObjectID,
 
 	return
 }
+
+// Resolve firing instances for alerts evaluated against source that did
+// not fire and send resolution notifications
+func Resolve(source, check primitive.ObjectID,
+	evaluated, fired []primitive.ObjectID) {
+
+	db := database.GetDatabase()
+	defer db.Close()
+
+	instances, err := alertinstance.ResolveMissing(
+		db, source, check, evaluated, fired)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("alert: Failed to resolve alert instances")
+		return
+	}
+
+	for _, inst := range instances {
+		if inst.Notified.IsZero() || inst.Silenced {
+			continue
+		}
+
+		suppressed, e := alertinstance.Suppressed(db, inst)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": e,
+			}).Error("alert: Failed to check alert silences")
+			continue
+		}
+
+		if suppressed {
+			continue
+		}
+
+		e = sendResolved(db, inst)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": e,
+			}).Error("alert: Failed to send alert resolution")
+		}
+	}
+}
+
+func sendResolved(db *database.Database, inst *alertinstance.Instance) (
+	err error) {
+
+	channels, err := alertchannel.GetRolesLevel(db, inst.Roles, inst.Level)
+	if err != nil {
+		return
+	}
+
+	alertchannel.Dispatch(channels, &alertchannel.Message{
+		Id:         inst.Id.Hex(),
+		Instance:   inst.Id.Hex(),
+		State:      alertchannel.Resolved,
+		Name:       inst.Name,
+		Source:     inst.Source.Hex(),
+		SourceName: inst.SourceName,
+		Resource:   inst.Resource,
+		Level:      inst.Level,
+		Message:    inst.Message,
+		Timestamp:  inst.Resolved,
+	})
+
+	users, _, err := user.GetAll(db, &bson.M{
+		"roles": &bson.D{
+			{"$in", inst.Roles},
+		},
+	}, 0, 0)
+	if err != nil {
+		return
+	}
+
+	msg := fmt.Sprintf("Resolved %s:%s", inst.Name, inst.SourceName)
+
+	for _, usr := range users {
+		devices, e := usr.GetDevices(db)
+		if e != nil {
+			err = e
+			return
+		}
+
+		for _, devc := range devices {
+			if devc.Mode != device.Phone || devc.Type == device.Call ||
+				!devc.CheckLevel(inst.Level) {
+
+				continue
+			}
+
+			errData, e := Send(devc.Number, msg, devc.Type)
+			if e != nil {
+				if errData != nil {
+					logrus.WithFields(logrus.Fields{
+						"server_error":   errData.Error,
+						"server_message": errData.Message,
+						"error":          e,
+					}).Error("alert: Failed to send alert resolution")
+				} else {
+					logrus.WithFields(logrus.Fields{
+						"error": e,
+					}).Error("alert: Failed to send alert resolution")
+				}
+			}
+		}
+	}
+
+	return
+}
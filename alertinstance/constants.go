@@ -0,0 +1,7 @@
+package alertinstance
+
+const (
+	Firing       = "firing"
+	Acknowledged = "acknowledged"
+	Resolved     = "resolved"
+)
@@ -0,0 +1,140 @@
+package alertinstance
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/node"
+)
+
+type Instance struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	ActiveKey    string             `bson:"active_key" json:"-"`
+	Alert        primitive.ObjectID `bson:"alert" json:"alert"`
+	Source       primitive.ObjectID `bson:"source" json:"source"`
+	Check        primitive.ObjectID `bson:"check,omitempty" json:"check"`
+	SourceName   string             `bson:"source_name" json:"source_name"`
+	Name         string             `bson:"name" json:"name"`
+	Resource     string             `bson:"resource" json:"resource"`
+	Level        int                `bson:"level" json:"level"`
+	Roles        []string           `bson:"roles" json:"roles"`
+	Message      string             `bson:"message" json:"message"`
+	State        string             `bson:"state" json:"state"`
+	Active       bool               `bson:"active" json:"active"`
+	Silenced     bool               `bson:"silenced" json:"silenced"`
+	Count        int                `bson:"count" json:"count"`
+	Start        time.Time          `bson:"start" json:"start"`
+	LastSeen     time.Time          `bson:"last_seen" json:"last_seen"`
+	Notified     time.Time          `bson:"notified" json:"notified"`
+	Acknowledged time.Time          `bson:"acknowledged" json:"acknowledged"`
+	AckUser      primitive.ObjectID `bson:"ack_user,omitempty" json:"ack_user"`
+	AckName      string             `bson:"ack_name" json:"ack_name"`
+	Resolved     time.Time          `bson:"resolved" json:"resolved"`
+	AckToken     string             `bson:"ack_token" json:"-"`
+}
+
+// Active key of instance, check alerts are also keyed by check to keep
+// the checks of an endpoint from resolving each other
+func activeKey(source, alertId, checkId primitive.ObjectID) string {
+	if !checkId.IsZero() {
+		return fmt.Sprintf("%s-%s-%s",
+			source.Hex(), alertId.Hex(), checkId.Hex())
+	}
+	return fmt.Sprintf("%s-%s", source.Hex(), alertId.Hex())
+}
+
+// Get acknowledgement link on management domain, empty when no node has
+// a management domain
+func (i *Instance) AckUrl(db *database.Database) string {
+	domain := node.Self.ManagementDomain
+	if domain == "" {
+		nodes, err := node.GetAll(db)
+		if err != nil {
+			return ""
+		}
+
+		for _, nde := range nodes {
+			if nde.ManagementDomain != "" {
+				domain = nde.ManagementDomain
+				break
+			}
+		}
+	}
+
+	if domain == "" || i.AckToken == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("https://%s/alert_ack/%s", domain, i.AckToken)
+}
+
+func (i *Instance) Acknowledge(db *database.Database,
+	userId primitive.ObjectID, username string) (
+	errData *errortypes.ErrorData, err error) {
+
+	coll := db.AlertInstances()
+
+	if i.State != Firing {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_instance_not_firing",
+			Message: "Alert is not firing",
+		}
+		return
+	}
+
+	now := time.Now()
+
+	resp, err := coll.UpdateOne(db, &bson.M{
+		"_id":   i.Id,
+		"state": Firing,
+	}, &bson.M{
+		"$set": &bson.M{
+			"state":        Acknowledged,
+			"acknowledged": now,
+			"ack_user":     userId,
+			"ack_name":     username,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_instance_not_firing",
+			Message: "Alert is not firing",
+		}
+		return
+	}
+
+	i.State = Acknowledged
+	i.Acknowledged = now
+	i.AckUser = userId
+	i.AckName = username
+
+	return
+}
+
+func (i *Instance) SetSilenced(db *database.Database, silenced bool) (
+	err error) {
+
+	coll := db.AlertInstances()
+
+	i.Silenced = silenced
+
+	err = coll.UpdateId(i.Id, &bson.M{
+		"$set": &bson.M{
+			"silenced": silenced,
+		},
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
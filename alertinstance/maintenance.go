@@ -0,0 +1,119 @@
+package alertinstance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Maintenance struct {
+	Id        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name      string               `bson:"name" json:"name"`
+	Comment   string               `bson:"comment" json:"comment"`
+	Endpoints []primitive.ObjectID `bson:"endpoints" json:"endpoints"`
+	Roles     []string             `bson:"roles" json:"roles"`
+	Start     time.Time            `bson:"start" json:"start"`
+	End       time.Time            `bson:"end" json:"end"`
+}
+
+func (m *Maintenance) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	m.Name = strings.TrimSpace(m.Name)
+
+	if m.Endpoints == nil {
+		m.Endpoints = []primitive.ObjectID{}
+	}
+	if m.Roles == nil {
+		m.Roles = []string{}
+	}
+
+	if len(m.Endpoints) == 0 && len(m.Roles) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_maintenance_match_invalid",
+			Message: "Maintenance window must match an endpoint or role",
+		}
+		return
+	}
+
+	if m.Start.IsZero() || !m.End.After(m.Start) {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_maintenance_time_invalid",
+			Message: "Maintenance window end must be after start",
+		}
+		return
+	}
+
+	return
+}
+
+func (m *Maintenance) Active(now time.Time) bool {
+	return !now.Before(m.Start) && now.Before(m.End)
+}
+
+// Check if endpoint or any of the endpoint roles are in the window
+func (m *Maintenance) Matches(source primitive.ObjectID,
+	roles []string) bool {
+
+	if containsId(m.Endpoints, source) {
+		return true
+	}
+
+	for _, role := range roles {
+		if containsStr(m.Roles, role) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *Maintenance) Commit(db *database.Database) (err error) {
+	coll := db.AlertMaintenances()
+
+	err = coll.Commit(m.Id, m)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (m *Maintenance) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.AlertMaintenances()
+
+	err = coll.CommitFields(m.Id, m, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (m *Maintenance) Insert(db *database.Database) (err error) {
+	coll := db.AlertMaintenances()
+
+	if !m.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("alertinstance: Maintenance already exists"),
+		}
+		return
+	}
+
+	m.Id = primitive.NewObjectID()
+
+	_, err = coll.InsertOne(db, m)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,130 @@
+package alertinstance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Silence struct {
+	Id        primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	Name      string               `bson:"name" json:"name"`
+	Comment   string               `bson:"comment" json:"comment"`
+	Endpoints []primitive.ObjectID `bson:"endpoints" json:"endpoints"`
+	Alerts    []primitive.ObjectID `bson:"alerts" json:"alerts"`
+	Resources []string             `bson:"resources" json:"resources"`
+	Start     time.Time            `bson:"start" json:"start"`
+	End       time.Time            `bson:"end" json:"end"`
+	User      primitive.ObjectID   `bson:"user,omitempty" json:"user"`
+}
+
+func (s *Silence) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	s.Name = strings.TrimSpace(s.Name)
+
+	if s.Endpoints == nil {
+		s.Endpoints = []primitive.ObjectID{}
+	}
+	if s.Alerts == nil {
+		s.Alerts = []primitive.ObjectID{}
+	}
+	if s.Resources == nil {
+		s.Resources = []string{}
+	}
+
+	if len(s.Endpoints) == 0 && len(s.Alerts) == 0 &&
+		len(s.Resources) == 0 {
+
+		errData = &errortypes.ErrorData{
+			Error:   "alert_silence_match_invalid",
+			Message: "Silence must match an endpoint, alert or resource",
+		}
+		return
+	}
+
+	if s.Start.IsZero() {
+		s.Start = time.Now()
+	}
+
+	if !s.End.After(s.Start) {
+		errData = &errortypes.ErrorData{
+			Error:   "alert_silence_time_invalid",
+			Message: "Silence end must be after start",
+		}
+		return
+	}
+
+	return
+}
+
+func (s *Silence) Active(now time.Time) bool {
+	return !now.Before(s.Start) && now.Before(s.End)
+}
+
+// Check if instance matches all selectors set on silence
+func (s *Silence) Matches(inst *Instance) bool {
+	if len(s.Endpoints) != 0 && !containsId(s.Endpoints, inst.Source) {
+		return false
+	}
+
+	if len(s.Alerts) != 0 && !containsId(s.Alerts, inst.Alert) {
+		return false
+	}
+
+	if len(s.Resources) != 0 && !containsStr(s.Resources, inst.Resource) {
+		return false
+	}
+
+	return true
+}
+
+func (s *Silence) Commit(db *database.Database) (err error) {
+	coll := db.AlertSilences()
+
+	err = coll.Commit(s.Id, s)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Silence) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.AlertSilences()
+
+	err = coll.CommitFields(s.Id, s, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (s *Silence) Insert(db *database.Database) (err error) {
+	coll := db.AlertSilences()
+
+	if !s.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("alertinstance: Silence already exists"),
+		}
+		return
+	}
+
+	s.Id = primitive.NewObjectID()
+
+	_, err = coll.InsertOne(db, s)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
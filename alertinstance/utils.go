@@ -0,0 +1,601 @@
+package alertinstance
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+func containsId(ids []primitive.ObjectID, id primitive.ObjectID) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func containsStr(items []string, item string) bool {
+	for _, i := range items {
+		if i == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Match instances of check, instances recorded before instances were keyed
+// by check are also matched
+func checkQuery(check primitive.ObjectID) *bson.M {
+	return &bson.M{
+		"$in": []interface{}{check, nil},
+	}
+}
+
+func fire(db *database.Database, inst *Instance, now time.Time) (
+	cur *Instance, err error) {
+
+	coll := db.AlertInstances()
+	cur = &Instance{}
+
+	token, err := utils.RandStr(32)
+	if err != nil {
+		return
+	}
+
+	setOnInsert := bson.M{
+		"alert":        inst.Alert,
+		"source":       inst.Source,
+		"resource":     inst.Resource,
+		"state":        Firing,
+		"active":       true,
+		"silenced":     false,
+		"start":        now,
+		"notified":     time.Time{},
+		"acknowledged": time.Time{},
+		"resolved":     time.Time{},
+		"ack_name":     "",
+		"ack_token":    token,
+	}
+	if !inst.Check.IsZero() {
+		setOnInsert["check"] = inst.Check
+	}
+
+	opts := &options.FindOneAndUpdateOptions{}
+	opts.SetUpsert(true)
+	opts.SetReturnDocument(options.After)
+
+	err = coll.FindOneAndUpdate(
+		db,
+		&bson.M{
+			"active_key": activeKey(inst.Source, inst.Alert, inst.Check),
+		},
+		&bson.M{
+			"$set": &bson.M{
+				"source_name": inst.SourceName,
+				"name":        inst.Name,
+				"level":       inst.Level,
+				"roles":       inst.Roles,
+				"message":     inst.Message,
+				"last_seen":   now,
+			},
+			"$inc": &bson.M{
+				"count": 1,
+			},
+			"$setOnInsert": &setOnInsert,
+		},
+		opts,
+	).Decode(cur)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Record firing alert, returns true when this caller should send the
+// notification. Acknowledged instances are not notified again.
+func Fire(db *database.Database, inst *Instance,
+	frequency time.Duration) (notify bool, err error) {
+
+	coll := db.AlertInstances()
+	now := time.Now()
+
+	cur, err := fire(db, inst, now)
+	if err != nil {
+		if _, ok := err.(*database.DuplicateKeyError); !ok {
+			return
+		}
+
+		cur, err = fire(db, inst, now)
+		if err != nil {
+			return
+		}
+	}
+
+	*inst = *cur
+
+	if inst.State != Firing ||
+		(!inst.Notified.IsZero() && now.Sub(inst.Notified) < frequency) {
+
+		return
+	}
+
+	resp, err := coll.UpdateOne(db, &bson.M{
+		"_id":      inst.Id,
+		"notified": inst.Notified,
+	}, &bson.M{
+		"$set": &bson.M{
+			"notified": now,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if resp.MatchedCount == 1 {
+		inst.Notified = now
+		notify = true
+	}
+
+	return
+}
+
+// Resolve active instances for source with alerts that were evaluated and
+// did not fire, returns instances resolved by this caller
+func ResolveMissing(db *database.Database, source, check primitive.ObjectID,
+	evaluated, fired []primitive.ObjectID) (
+	resolved []*Instance, err error) {
+
+	coll := db.AlertInstances()
+	resolved = []*Instance{}
+
+	if len(evaluated) == 0 {
+		return
+	}
+
+	if fired == nil {
+		fired = []primitive.ObjectID{}
+	}
+
+	query := bson.M{
+		"source": source,
+		"active": true,
+		"alert": &bson.M{
+			"$in":  evaluated,
+			"$nin": fired,
+		},
+	}
+	if !check.IsZero() {
+		query["check"] = checkQuery(check)
+	}
+
+	cursor, err := coll.Find(db, &query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	instances := []*Instance{}
+	for cursor.Next(db) {
+		inst := &Instance{}
+		err = cursor.Decode(inst)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		instances = append(instances, inst)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	now := time.Now()
+	for _, inst := range instances {
+		resp, e := coll.UpdateOne(db, &bson.M{
+			"_id":    inst.Id,
+			"active": true,
+		}, &bson.M{
+			"$set": &bson.M{
+				"active":     false,
+				"active_key": inst.Id.Hex(),
+				"state":      Resolved,
+				"resolved":   now,
+			},
+		})
+		if e != nil {
+			err = database.ParseError(e)
+			return
+		}
+
+		if resp.MatchedCount == 1 {
+			inst.Active = false
+			inst.State = Resolved
+			inst.Resolved = now
+			resolved = append(resolved, inst)
+		}
+	}
+
+	return
+}
+
+// Get ids of alerts with an active instance on source
+func GetActiveAlerts(db *database.Database,
+	source, check primitive.ObjectID) (
+	alertIds []primitive.ObjectID, err error) {
+
+	coll := db.AlertInstances()
+	alertIds = []primitive.ObjectID{}
+
+	query := bson.M{
+		"source": source,
+		"active": true,
+	}
+	if !check.IsZero() {
+		query["check"] = checkQuery(check)
+	}
+
+	cursor, err := coll.Find(
+		db,
+		&query,
+		&options.FindOptions{
+			Projection: &bson.D{
+				{"alert", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		inst := &Instance{}
+		err = cursor.Decode(inst)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		alertIds = append(alertIds, inst.Alert)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Get instances for sources started or still active since time
+func GetSources(db *database.Database, sources []primitive.ObjectID,
+	since time.Time) (instances []*Instance, err error) {
+
+	coll := db.AlertInstances()
+	instances = []*Instance{}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"source": &bson.M{
+				"$in": sources,
+			},
+			"$or": []*bson.M{
+				&bson.M{
+					"start": &bson.M{
+						"$gte": since,
+					},
+				},
+				&bson.M{
+					"active": true,
+				},
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"start", -1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		inst := &Instance{}
+		err = cursor.Decode(inst)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		instances = append(instances, inst)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Check if notifications for instance are suppressed by an active silence
+// or maintenance window
+func Suppressed(db *database.Database, inst *Instance) (
+	suppressed bool, err error) {
+
+	now := time.Now()
+	query := &bson.M{
+		"start": &bson.M{
+			"$lte": now,
+		},
+		"end": &bson.M{
+			"$gt": now,
+		},
+	}
+
+	silences, err := GetSilences(db, query)
+	if err != nil {
+		return
+	}
+
+	for _, slnc := range silences {
+		if slnc.Matches(inst) {
+			suppressed = true
+			return
+		}
+	}
+
+	maintenances, err := GetMaintenances(db, query)
+	if err != nil {
+		return
+	}
+
+	for _, mntc := range maintenances {
+		if mntc.Matches(inst.Source, inst.Roles) {
+			suppressed = true
+			return
+		}
+	}
+
+	return
+}
+
+func Get(db *database.Database, instId primitive.ObjectID) (
+	inst *Instance, err error) {
+
+	coll := db.AlertInstances()
+	inst = &Instance{}
+
+	err = coll.FindOneId(instId, inst)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetToken(db *database.Database, token string) (
+	inst *Instance, err error) {
+
+	coll := db.AlertInstances()
+	inst = &Instance{}
+
+	err = coll.FindOne(db, &bson.M{
+		"ack_token": token,
+	}).Decode(inst)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAllPaged(db *database.Database, query *bson.M,
+	page, pageCount int64) (instances []*Instance, count int64, err error) {
+
+	coll := db.AlertInstances()
+	instances = []*Instance{}
+
+	count, err = coll.CountDocuments(db, query)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	maxPage := count / pageCount
+	if count == pageCount {
+		maxPage = 0
+	}
+	page = utils.Min64(page, maxPage)
+	skip := utils.Min64(page*pageCount, count)
+
+	cursor, err := coll.Find(
+		db,
+		query,
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"start", -1},
+			},
+			Skip:  &skip,
+			Limit: &pageCount,
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		inst := &Instance{}
+		err = cursor.Decode(inst)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		instances = append(instances, inst)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetSilence(db *database.Database, slncId primitive.ObjectID) (
+	slnc *Silence, err error) {
+
+	coll := db.AlertSilences()
+	slnc = &Silence{}
+
+	err = coll.FindOneId(slncId, slnc)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetSilences(db *database.Database, query *bson.M) (
+	silences []*Silence, err error) {
+
+	coll := db.AlertSilences()
+	silences = []*Silence{}
+
+	cursor, err := coll.Find(db, query, &options.FindOptions{
+		Sort: &bson.D{
+			{"start", -1},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		slnc := &Silence{}
+		err = cursor.Decode(slnc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		silences = append(silences, slnc)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemoveSilence(db *database.Database, slncId primitive.ObjectID) (
+	err error) {
+
+	coll := db.AlertSilences()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": slncId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetMaintenance(db *database.Database, mntcId primitive.ObjectID) (
+	mntc *Maintenance, err error) {
+
+	coll := db.AlertMaintenances()
+	mntc = &Maintenance{}
+
+	err = coll.FindOneId(mntcId, mntc)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetMaintenances(db *database.Database, query *bson.M) (
+	maintenances []*Maintenance, err error) {
+
+	coll := db.AlertMaintenances()
+	maintenances = []*Maintenance{}
+
+	cursor, err := coll.Find(db, query, &options.FindOptions{
+		Sort: &bson.D{
+			{"start", -1},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		mntc := &Maintenance{}
+		err = cursor.Decode(mntc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		maintenances = append(maintenances, mntc)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemoveMaintenance(db *database.Database,
+	mntcId primitive.ObjectID) (err error) {
+
+	coll := db.AlertMaintenances()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": mntcId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
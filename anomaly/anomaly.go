@@ -0,0 +1,174 @@
+package anomaly
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/policy"
+)
+
+type Anomaly struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+func distance(lat1, lon1, lat2, lon2 float64) float64 {
+	rad := math.Pi / 180
+	dLat := (lat2 - lat1) * rad
+	dLon := (lon2 - lon1) * rad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*rad)*math.Cos(lat2*rad)*
+			math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return EarthRadius * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
+
+func hasLocation(agnt *agent.Agent) bool {
+	return agnt.Latitude != 0 || agnt.Longitude != 0
+}
+
+func location(agnt *agent.Agent) string {
+	if agnt.City != "" {
+		return fmt.Sprintf("%s, %s", agnt.City, agnt.CountryCode)
+	}
+	return agnt.CountryCode
+}
+
+// Compare agent against previous successful logins newest first
+func Detect(agnt *agent.Agent, history []*audit.Audit, checks []string,
+	now time.Time) (anomalies []*Anomaly) {
+
+	anomalies = []*Anomaly{}
+
+	previous := []*agent.Agent{}
+	timestamps := []time.Time{}
+	for _, adt := range history {
+		if adt.Agent != nil {
+			previous = append(previous, adt.Agent)
+			timestamps = append(timestamps, adt.Timestamp)
+		}
+	}
+
+	if len(previous) == 0 {
+		return
+	}
+
+	for _, check := range checks {
+		switch check {
+		case policy.ImpossibleTravel:
+			last := previous[0]
+			if !hasLocation(agnt) || !hasLocation(last) {
+				break
+			}
+
+			dist := distance(last.Latitude, last.Longitude,
+				agnt.Latitude, agnt.Longitude)
+			if dist < MinDistance {
+				break
+			}
+
+			hours := now.Sub(timestamps[0]).Hours()
+			if hours <= 0 || dist/hours > MaxSpeed {
+				anomalies = append(anomalies, &Anomaly{
+					Type: policy.ImpossibleTravel,
+					Message: fmt.Sprintf(
+						"Impossible travel of %.0f km from %s to %s "+
+							"in %.1f hours",
+						dist, location(last), location(agnt), hours),
+				})
+			}
+			break
+		case policy.NewCountry:
+			if agnt.CountryCode == "" {
+				break
+			}
+
+			seen := false
+			for _, prev := range previous {
+				if prev.CountryCode == agnt.CountryCode {
+					seen = true
+					break
+				}
+			}
+
+			if !seen {
+				anomalies = append(anomalies, &Anomaly{
+					Type: policy.NewCountry,
+					Message: fmt.Sprintf("First login from country %s",
+						agnt.CountryCode),
+				})
+			}
+			break
+		case policy.NewIsp:
+			if agnt.Isp == "" {
+				break
+			}
+
+			seen := false
+			for _, prev := range previous {
+				if prev.Isp == agnt.Isp {
+					seen = true
+					break
+				}
+			}
+
+			if !seen {
+				anomalies = append(anomalies, &Anomaly{
+					Type: policy.NewIsp,
+					Message: fmt.Sprintf("First login from network %s",
+						agnt.Isp),
+				})
+			}
+			break
+		case policy.NewOperatingSystem:
+			if agnt.OperatingSystem == "" {
+				break
+			}
+
+			seen := false
+			for _, prev := range previous {
+				if prev.OperatingSystem == agnt.OperatingSystem {
+					seen = true
+					break
+				}
+			}
+
+			if !seen {
+				anomalies = append(anomalies, &Anomaly{
+					Type: policy.NewOperatingSystem,
+					Message: fmt.Sprintf(
+						"First login from operating system %s",
+						agnt.OperatingSystem),
+				})
+			}
+			break
+		case policy.NewBrowser:
+			if agnt.Browser == "" {
+				break
+			}
+
+			seen := false
+			for _, prev := range previous {
+				if prev.Browser == agnt.Browser {
+					seen = true
+					break
+				}
+			}
+
+			if !seen {
+				anomalies = append(anomalies, &Anomaly{
+					Type: policy.NewBrowser,
+					Message: fmt.Sprintf("First login from browser %s",
+						agnt.Browser),
+				})
+			}
+			break
+		}
+	}
+
+	return
+}
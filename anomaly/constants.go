@@ -0,0 +1,25 @@
+package anomaly
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-zero/audit"
+)
+
+const (
+	MaxSpeed      = 1000.0 // km/h
+	MinDistance   = 300.0  // km
+	EarthRadius   = 6371.0 // km
+	HistoryWindow = 90 * 24 * time.Hour
+	HistoryLimit  = 200
+)
+
+var LoginTypes = []string{
+	audit.AdminLogin,
+	audit.AdminPasskeyLogin,
+	audit.UserLogin,
+	audit.UserPasskeyLogin,
+	audit.ProxyLogin,
+	audit.ProxyPasskeyLogin,
+	audit.SshApprove,
+}
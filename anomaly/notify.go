@@ -0,0 +1,49 @@
+package anomaly
+
+import (
+	"github.com/pritunl/pritunl-zero/alertevent"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/sirupsen/logrus"
+)
+
+// Notify user phone devices of anomalous login
+func notifyUser(usr *user.User, msg string) {
+	go func() {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		devices, err := usr.GetDevices(db)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"user_id": usr.Id.Hex(),
+				"error":   err,
+			}).Error("anomaly: Failed to get user devices")
+			return
+		}
+
+		for _, devc := range devices {
+			if devc.Mode != device.Phone || !devc.CheckLevel(device.High) {
+				continue
+			}
+
+			errData, err := alertevent.Send(devc.Number, msg, devc.Type)
+			if err != nil {
+				if errData != nil {
+					logrus.WithFields(logrus.Fields{
+						"user_id":        usr.Id.Hex(),
+						"server_error":   errData.Error,
+						"server_message": errData.Message,
+						"error":          err,
+					}).Error("anomaly: Failed to send notification")
+				} else {
+					logrus.WithFields(logrus.Fields{
+						"user_id": usr.Id.Hex(),
+						"error":   err,
+					}).Error("anomaly: Failed to send notification")
+				}
+			}
+		}
+	}()
+}
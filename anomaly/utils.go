@@ -0,0 +1,151 @@
+package anomaly
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+// Check login against user history for policies with anomaly rules,
+// detected anomalies are audited and the strictest rule action applies
+func Check(db *database.Database, usr *user.User,
+	policies []*policy.Policy, r *http.Request) (stepUp bool,
+	errAudit audit.Fields, errData *errortypes.ErrorData, err error) {
+
+	rules := []*policy.Rule{}
+	for _, polcy := range policies {
+		if polcy.Disabled {
+			continue
+		}
+
+		for _, rule := range polcy.Rules {
+			if rule.Type == policy.Anomaly {
+				rules = append(rules, rule)
+			}
+		}
+	}
+
+	if len(rules) == 0 {
+		return
+	}
+
+	agnt, err := agent.Parse(db, r)
+	if err != nil || agnt == nil {
+		return
+	}
+
+	now := time.Now()
+	history, err := audit.GetHistory(db, usr.Id, LoginTypes,
+		now.Add(-HistoryWindow), HistoryLimit)
+	if err != nil {
+		return
+	}
+
+	var denyRule *policy.Rule
+	alert := false
+	detected := []*Anomaly{}
+	detectedTypes := set.NewSet()
+
+	for _, rule := range rules {
+		anomalies := Detect(agnt, history, rule.AnomalyChecks(), now)
+		if len(anomalies) == 0 {
+			continue
+		}
+
+		for _, anmly := range anomalies {
+			if !detectedTypes.Contains(anmly.Type) {
+				detectedTypes.Add(anmly.Type)
+				detected = append(detected, anmly)
+			}
+		}
+
+		switch rule.Action {
+		case policy.Deny:
+			if denyRule == nil || rule.Disable {
+				denyRule = rule
+			}
+			break
+		case policy.StepUp:
+			stepUp = true
+			break
+		default:
+			alert = true
+			break
+		}
+	}
+
+	if len(detected) == 0 {
+		return
+	}
+
+	messages := []string{}
+	for _, anmly := range detected {
+		messages = append(messages, anmly.Message)
+	}
+	message := strings.Join(messages, ", ")
+
+	action := policy.Alert
+	if denyRule != nil {
+		action = policy.Deny
+	} else if stepUp {
+		action = policy.StepUp
+	}
+
+	err = audit.New(
+		db,
+		r,
+		usr.Id,
+		audit.LoginAnomaly,
+		audit.Fields{
+			"action":    action,
+			"anomalies": detected,
+			"message":   message,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	if alert || denyRule != nil {
+		notifyUser(usr, fmt.Sprintf(
+			"Anomalous login to %s: %s", usr.Username, message))
+	}
+
+	if denyRule != nil {
+		stepUp = false
+		errAudit = audit.Fields{
+			"error":   "anomaly_policy",
+			"message": message,
+		}
+
+		if denyRule.Disable {
+			usr.Disabled = true
+			err = usr.CommitFields(db, set.NewSet("disabled"))
+			if err != nil {
+				return
+			}
+
+			errData = &errortypes.ErrorData{
+				Error:   "unauthorized",
+				Message: "Not authorized",
+			}
+		} else {
+			errData = &errortypes.ErrorData{
+				Error:   "anomaly_policy",
+				Message: "Login blocked due to unusual activity",
+			}
+		}
+		return
+	}
+
+	return
+}
@@ -0,0 +1,227 @@
+package apikey
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type ApiKey struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	User       primitive.ObjectID `bson:"user" json:"user"`
+	Name       string             `bson:"name" json:"name"`
+	TokenHash  string             `bson:"token_hash" json:"-"`
+	Token      string             `bson:"-" json:"token,omitempty"`
+	Secret     string             `bson:"-" json:"secret,omitempty"`
+	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
+	Expires    time.Time          `bson:"expires" json:"expires"`
+	ReadOnly   bool               `bson:"read_only" json:"read_only"`
+	Resources  []string           `bson:"resources" json:"resources"`
+	Service    primitive.ObjectID `bson:"service,omitempty" json:"service"`
+	LastActive time.Time          `bson:"last_active" json:"last_active"`
+	LastIp     string             `bson:"last_ip" json:"last_ip"`
+}
+
+func (k *ApiKey) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	k.Name = utils.FilterStr(k.Name, 32)
+
+	if len(k.Name) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "api_key_name_missing",
+			Message: "API key name is required",
+		}
+		return
+	}
+
+	if k.Resources == nil {
+		k.Resources = []string{}
+	}
+
+	resourcesSet := set.NewSet()
+	for _, resource := range k.Resources {
+		if !resources.Contains(resource) {
+			errData = &errortypes.ErrorData{
+				Error:   "api_key_resource_invalid",
+				Message: "API key resource is invalid",
+			}
+			return
+		}
+		resourcesSet.Add(resource)
+	}
+
+	k.Resources = []string{}
+	for resource := range resourcesSet.Iter() {
+		k.Resources = append(k.Resources, resource.(string))
+	}
+
+	if !k.Service.IsZero() {
+		if len(k.Resources) != 0 {
+			errData = &errortypes.ErrorData{
+				Error: "api_key_service_resources",
+				Message: "API key cannot be limited to both a service " +
+					"and management resources",
+			}
+			return
+		}
+
+		coll := db.Services()
+		count, e := coll.CountDocuments(db, &bson.M{
+			"_id": k.Service,
+		})
+		if e != nil {
+			err = database.ParseError(e)
+			return
+		}
+
+		if count == 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "api_key_service_invalid",
+				Message: "API key service does not exist",
+			}
+			return
+		}
+	}
+
+	return
+}
+
+func (k *ApiKey) GenerateToken() (err error) {
+	k.Token, err = utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	k.TokenHash = HashToken(k.Token)
+	k.Secret = DeriveSecret(k.Token)
+
+	return
+}
+
+func (k *ApiKey) IsExpired() bool {
+	return !k.Expires.IsZero() && k.Expires.Before(time.Now())
+}
+
+// Check if key can access management handler with method and path
+func (k *ApiKey) Permit(method, path string) bool {
+	if !k.Service.IsZero() {
+		return false
+	}
+
+	if k.ReadOnly && method != "GET" && method != "HEAD" {
+		return false
+	}
+
+	if len(k.Resources) == 0 {
+		return true
+	}
+
+	resource := pathResources[strings.SplitN(
+		strings.TrimPrefix(path, "/"), "/", 2)[0]]
+	if resource == "" {
+		return false
+	}
+
+	for _, res := range k.Resources {
+		if res == resource {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if key can access proxy service
+func (k *ApiKey) PermitService(method string,
+	serviceId primitive.ObjectID) bool {
+
+	if len(k.Resources) != 0 {
+		return false
+	}
+
+	if k.ReadOnly && method != "GET" && method != "HEAD" {
+		return false
+	}
+
+	if !k.Service.IsZero() && k.Service != serviceId {
+		return false
+	}
+
+	return true
+}
+
+// Check if key can access user handlers
+func (k *ApiKey) PermitUser(method string) bool {
+	if !k.Service.IsZero() || len(k.Resources) != 0 {
+		return false
+	}
+
+	if k.ReadOnly && method != "GET" && method != "HEAD" {
+		return false
+	}
+
+	return true
+}
+
+func (k *ApiKey) SetActive(db *database.Database, addr string) (err error) {
+	k.LastActive = time.Now()
+	k.LastIp = addr
+
+	err = k.CommitFields(db, set.NewSet("last_active", "last_ip"))
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (k *ApiKey) Commit(db *database.Database) (err error) {
+	coll := db.ApiKeys()
+
+	err = coll.Commit(k.Id, k)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (k *ApiKey) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.ApiKeys()
+
+	err = coll.CommitFields(k.Id, k, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (k *ApiKey) Insert(db *database.Database) (err error) {
+	coll := db.ApiKeys()
+
+	if !k.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("apikey: Api key already exists"),
+		}
+		return
+	}
+
+	_, err = coll.InsertOne(db, k)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
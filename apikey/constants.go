@@ -0,0 +1,69 @@
+package apikey
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+)
+
+const (
+	Alerts       = "alerts"
+	Audits       = "audits"
+	Authorities  = "authorities"
+	Certificates = "certificates"
+	Checks       = "checks"
+	Devices      = "devices"
+	Endpoints    = "endpoints"
+	Logs         = "logs"
+	Nodes        = "nodes"
+	Policies     = "policies"
+	Services     = "services"
+	Sessions     = "sessions"
+	Settings     = "settings"
+	Users        = "users"
+)
+
+var (
+	resources = set.NewSet(
+		Alerts,
+		Audits,
+		Authorities,
+		Certificates,
+		Checks,
+		Devices,
+		Endpoints,
+		Logs,
+		Nodes,
+		Policies,
+		Services,
+		Sessions,
+		Settings,
+		Users,
+	)
+	pathResources = map[string]string{
+		"access_request":    Users,
+		"alert":             Alerts,
+		"alert_channel":     Alerts,
+		"alert_instance":    Alerts,
+		"alert_maintenance": Alerts,
+		"alert_silence":     Alerts,
+		"audit":             Audits,
+		"authority":         Authorities,
+		"certificate":       Certificates,
+		"checks":            Checks,
+		"device":            Devices,
+		"endpoint":          Endpoints,
+		"log":               Logs,
+		"node":              Nodes,
+		"oidc_client":       Services,
+		"policy":            Policies,
+		"policy_simulate":   Policies,
+		"service":           Services,
+		"session":           Sessions,
+		"settings":          Settings,
+		"sshcertificate":    Users,
+		"status_page":       Checks,
+		"status_post":       Checks,
+		"subscription":      Settings,
+		"user":              Users,
+		"apikey":            Users,
+	}
+)
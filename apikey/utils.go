@@ -0,0 +1,200 @@
+package apikey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/requires"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func DeriveSecret(token string) string {
+	hash := hmac.New(sha512.New, settings.System.ApiKeySecret)
+	hash.Write([]byte(token))
+	return base64.RawURLEncoding.EncodeToString(hash.Sum(nil))
+}
+
+func Get(db *database.Database, keyId primitive.ObjectID) (
+	key *ApiKey, err error) {
+
+	coll := db.ApiKeys()
+	key = &ApiKey{}
+
+	err = coll.FindOneId(keyId, key)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetUser(db *database.Database, keyId primitive.ObjectID,
+	userId primitive.ObjectID) (key *ApiKey, err error) {
+
+	coll := db.ApiKeys()
+	key = &ApiKey{}
+
+	err = coll.FindOne(db, &bson.M{
+		"_id":  keyId,
+		"user": userId,
+	}).Decode(key)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetToken(db *database.Database, token string) (
+	key *ApiKey, err error) {
+
+	coll := db.ApiKeys()
+	key = &ApiKey{}
+
+	err = coll.FindOne(db, &bson.M{
+		"token_hash": HashToken(token),
+	}).Decode(key)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database, userId primitive.ObjectID) (
+	keys []*ApiKey, err error) {
+
+	coll := db.ApiKeys()
+	keys = []*ApiKey{}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"user": userId,
+	}, &options.FindOptions{
+		Sort: &bson.D{
+			{"name", 1},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		key := &ApiKey{}
+		err = cursor.Decode(key)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		keys = append(keys, key)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func New(userId primitive.ObjectID) (key *ApiKey) {
+	key = &ApiKey{
+		User:      userId,
+		Timestamp: time.Now(),
+		Resources: []string{},
+	}
+
+	return
+}
+
+func Remove(db *database.Database, keyId primitive.ObjectID) (err error) {
+	coll := db.ApiKeys()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": keyId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func RemoveUser(db *database.Database, keyId primitive.ObjectID,
+	userId primitive.ObjectID) (err error) {
+
+	coll := db.ApiKeys()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id":  keyId,
+		"user": userId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+func init() {
+	module := requires.New("apikey")
+	module.After("settings")
+
+	module.Handler = func() (err error) {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		if len(settings.System.ApiKeySecret) == 0 {
+			secret, e := utils.RandBytes(64)
+			if e != nil {
+				err = e
+				return
+			}
+
+			settings.System.ApiKeySecret = secret
+
+			err = settings.Commit(db, settings.System, set.NewSet(
+				"api_key_secret",
+			))
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	}
+}
@@ -10,6 +10,8 @@ const (
 	AdminDeviceApprove         = "admin_device_approve"
 	AdminDeviceRegisterRequest = "admin_device_register_request"
 	AdminDeviceRegister        = "admin_device_register"
+	AdminPasskeyLogin          = "admin_passkey_login"
+	AdminPasskeyLoginFailed    = "admin_passkey_login_failed"
 
 	ProxyLogin                 = "proxy_login"
 	ProxyLoginFailed           = "proxy_login_failed"
@@ -20,6 +22,8 @@ const (
 	ProxyDeviceApprove         = "proxy_device_approve"
 	ProxyDeviceRegisterRequest = "proxy_device_register_request"
 	ProxyDeviceRegister        = "proxy_device_register"
+	ProxyPasskeyLogin          = "proxy_passkey_login"
+	ProxyPasskeyLoginFailed    = "proxy_passkey_login_failed"
 
 	UserLogin                 = "user_login"
 	UserLoginFailed           = "user_login_failed"
@@ -31,7 +35,16 @@ const (
 	UserDeviceApprove         = "user_device_approve"
 	UserDeviceRegisterRequest = "user_device_register_request"
 	UserDeviceRegister        = "user_device_register"
+	UserPasskeyLogin          = "user_passkey_login"
+	UserPasskeyLoginFailed    = "user_passkey_login_failed"
+	UserPasskeyRegister       = "user_passkey_register"
+	UserPasskeyRegisterFailed = "user_passkey_register_failed"
 	UserAccountDisable        = "user_account_disable"
+	UserOidcAuthorize         = "user_oidc_authorize"
+	UserOidcAuthorizeFailed   = "user_oidc_authorize_failed"
+	LoginAnomaly              = "login_anomaly"
+	PolicyNetworkBlocked      = "policy_network_blocked"
+	RiskAssessment            = "risk_assessment"
 
 	DeviceRegister       = "device_register"
 	DeviceRegisterFailed = "device_register_failed"
@@ -43,4 +56,11 @@ const (
 	OktaDeny             = "okta_deny"
 	SshApprove           = "ssh_approve"
 	SshDeny              = "ssh_deny"
+
+	AccessRequest       = "access_request"
+	AccessRequestCancel = "access_request_cancel"
+	AccessApprove       = "access_approve"
+	AccessDeny          = "access_deny"
+	AccessRevoke        = "access_revoke"
+	AccessExpire        = "access_expire"
 )
@@ -87,6 +87,77 @@ func GetAll(db *database.Database, userId primitive.ObjectID,
 	return
 }
 
+// Get recent entries of types for user newest first
+func GetHistory(db *database.Database, userId primitive.ObjectID,
+	types []string, since time.Time, limit int64) (
+	audits []*Audit, err error) {
+
+	coll := db.Audits()
+	audits = []*Audit{}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"u": userId,
+		"y": &bson.M{
+			"$in": types,
+		},
+		"t": &bson.M{
+			"$gte": since,
+		},
+	}, &options.FindOptions{
+		Sort: &bson.D{
+			{"t", -1},
+		},
+		Limit: &limit,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		adt := &Audit{}
+		err = cursor.Decode(adt)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		audits = append(audits, adt)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Count entries of types for user since time
+func Count(db *database.Database, userId primitive.ObjectID,
+	types []string, since time.Time) (count int64, err error) {
+
+	coll := db.Audits()
+
+	count, err = coll.CountDocuments(db, &bson.M{
+		"u": userId,
+		"y": &bson.M{
+			"$in": types,
+		},
+		"t": &bson.M{
+			"$gte": since,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
 func New(db *database.Database, r *http.Request,
 	userId primitive.ObjectID, typ string, fields Fields) (err error) {
 
@@ -114,3 +185,26 @@ func New(db *database.Database, r *http.Request,
 
 	return
 }
+
+// Create audit entry for background jobs without a request
+func NewSystem(db *database.Database, userId primitive.ObjectID,
+	typ string, fields Fields) (err error) {
+
+	if settings.System.Demo {
+		return
+	}
+
+	adt := &Audit{
+		User:      userId,
+		Timestamp: time.Now(),
+		Type:      typ,
+		Fields:    fields,
+	}
+
+	err = adt.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -37,7 +37,7 @@ func CookieSessionAdmin(db *database.Database,
 		return
 	}
 
-	sess, err = cook.GetSession(db, r, session.Admin)
+	sess, err = cook.GetSession(db, r, session.Admin, nil)
 	if err != nil {
 		switch err.(type) {
 		case *errortypes.NotFoundError:
@@ -52,7 +52,7 @@ func CookieSessionAdmin(db *database.Database,
 }
 
 func CookieSessionProxy(db *database.Database, srvc *service.Service,
-	w http.ResponseWriter, r *http.Request) (
+	limits *session.Limits, w http.ResponseWriter, r *http.Request) (
 	cook *cookie.Cookie, sess *session.Session, err error) {
 
 	cook, err = cookie.GetProxy(srvc, w, r)
@@ -62,7 +62,7 @@ func CookieSessionProxy(db *database.Database, srvc *service.Service,
 		return
 	}
 
-	sess, err = cook.GetSession(db, r, session.Proxy)
+	sess, err = cook.GetSession(db, r, session.Proxy, limits)
 	if err != nil {
 		switch err.(type) {
 		case *errortypes.NotFoundError:
@@ -86,7 +86,7 @@ func CookieSessionUser(db *database.Database, w http.ResponseWriter,
 		return
 	}
 
-	sess, err = cook.GetSession(db, r, session.User)
+	sess, err = cook.GetSession(db, r, session.User, nil)
 	if err != nil {
 		switch err.(type) {
 		case *errortypes.NotFoundError:
@@ -1684,6 +1684,8 @@ func (a *Authority) Insert(db *database.Database) (err error) {
 		return
 	}
 
+	a.Id = primitive.NewObjectID()
+
 	_, err = coll.InsertOne(db, a)
 	if err != nil {
 		err = database.ParseError(err)
@@ -3,9 +3,13 @@ package authorizer
 import (
 	"net/http"
 
+	"github.com/dropbox/godropbox/errors"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/apikey"
 	"github.com/pritunl/pritunl-zero/cookie"
 	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/node"
 	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/session"
 	"github.com/pritunl/pritunl-zero/signature"
@@ -30,13 +34,42 @@ func (a *Authorizer) IsValid() bool {
 }
 
 func (a *Authorizer) AddSignature(db *database.Database,
-	sig *signature.Signature) (err error) {
+	sig *signature.Signature, r *http.Request) (err error) {
 
 	err = sig.Validate(db)
 	if err != nil {
 		return
 	}
 
+	key := sig.GetApiKey()
+	if key != nil {
+		permit := false
+
+		switch a.typ {
+		case Admin:
+			permit = key.Permit(r.Method, r.URL.Path)
+			break
+		case Proxy:
+			permit = a.srvc != nil && key.PermitService(r.Method, a.srvc.Id)
+			break
+		case User:
+			permit = key.PermitUser(r.Method)
+			break
+		}
+
+		if !permit {
+			err = &errortypes.AuthenticationError{
+				errors.New("authorizer: Api key not permitted"),
+			}
+			return
+		}
+
+		err = key.SetActive(db, node.Self.GetRemoteAddr(r))
+		if err != nil {
+			return
+		}
+	}
+
 	a.sig = sig
 
 	return
@@ -152,6 +185,14 @@ func (a *Authorizer) ServiceId() primitive.ObjectID {
 	return primitive.NilObjectID
 }
 
+func (a *Authorizer) GetApiKey() *apikey.ApiKey {
+	if a.sig != nil {
+		return a.sig.GetApiKey()
+	}
+
+	return nil
+}
+
 func (a *Authorizer) GetSession() *session.Session {
 	return a.sess
 }
@@ -5,6 +5,7 @@ import (
 
 	"github.com/pritunl/pritunl-zero/auth"
 	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/policy"
 	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/signature"
 )
@@ -34,7 +35,7 @@ func AuthorizeAdmin(db *database.Database, w http.ResponseWriter,
 			return
 		}
 
-		err = authr.AddSignature(db, sig)
+		err = authr.AddSignature(db, sig, r)
 		if err != nil {
 			return
 		}
@@ -79,12 +80,19 @@ func AuthorizeProxy(db *database.Database, srvc *service.Service,
 			return
 		}
 
-		err = authr.AddSignature(db, sig)
+		err = authr.AddSignature(db, sig, r)
 		if err != nil {
 			return
 		}
 	} else {
-		cook, sess, e := auth.CookieSessionProxy(db, srvc, w, r)
+		policies, e := policy.GetService(db, srvc.Id)
+		if e != nil {
+			err = e
+			return
+		}
+
+		cook, sess, e := auth.CookieSessionProxy(
+			db, srvc, policy.SessionLimits(policies), w, r)
 		if e != nil {
 			err = e
 			return
@@ -124,7 +132,7 @@ func AuthorizeUser(db *database.Database, w http.ResponseWriter,
 			return
 		}
 
-		err = authr.AddSignature(db, sig)
+		err = authr.AddSignature(db, sig, r)
 		if err != nil {
 			return
 		}
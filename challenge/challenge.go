@@ -10,11 +10,14 @@ import (
 	"github.com/pritunl/mongo-go-driver/bson"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
 	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/anomaly"
 	"github.com/pritunl/pritunl-zero/authority"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/device"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/risk"
+	"github.com/pritunl/pritunl-zero/session"
 	"github.com/pritunl/pritunl-zero/settings"
 	"github.com/pritunl/pritunl-zero/ssh"
 	"github.com/pritunl/pritunl-zero/user"
@@ -30,7 +33,8 @@ type Challenge struct {
 }
 
 func (c *Challenge) Approve(db *database.Database, usr *user.User,
-	r *http.Request, deviceSec, secondary bool) (deviceAuth bool,
+	sess *session.Session, r *http.Request, deviceSec, secondary bool) (
+	deviceAuth bool,
 	secProvider primitive.ObjectID, err error,
 	errData *errortypes.ErrorData) {
 
@@ -83,6 +87,63 @@ func (c *Challenge) Approve(db *database.Database, usr *user.User,
 		}
 	}
 
+	if !deviceSec && !secondary && sess != nil {
+		limits := policy.SessionLimits(policies)
+		if limits != nil {
+			// Authority secondary is always performed with the challenge
+			limits.ReauthSecondary = 0
+
+			if !limits.Allowed(sess) {
+				errData = &errortypes.ErrorData{
+					Error:   "reauth_required",
+					Message: "Authentication required",
+				}
+				return
+			}
+		}
+	}
+
+	if !deviceSec && !secondary {
+		stepUp, _, anomalyErrData, e := anomaly.Check(db, usr, policies, r)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if anomalyErrData != nil {
+			errData = anomalyErrData
+			err = c.Deny(db, usr)
+			if err != nil {
+				return
+			}
+			return
+		}
+
+		if stepUp {
+			deviceAuth = true
+		}
+
+		stepUp, _, _, riskErrData, e := risk.Check(
+			db, usr, policies, nil, r)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if riskErrData != nil {
+			errData = riskErrData
+			err = c.Deny(db, usr)
+			if err != nil {
+				return
+			}
+			return
+		}
+
+		if stepUp {
+			deviceAuth = true
+		}
+	}
+
 	if (deviceAuth && !deviceSec && !secondary) ||
 		(!secProvider.IsZero() && !secondary) {
 
@@ -1,6 +1,7 @@
 package check
 
 import (
+	"net"
 	"strings"
 	"time"
 
@@ -23,6 +24,13 @@ type Check struct {
 	Method     string             `bson:"method" json:"method"`
 	StatusCode int                `bson:"status_code" json:"status_code"`
 	Headers    []*Header          `bson:"headers" json:"headers"`
+	Banner     string             `bson:"banner" json:"banner"`
+	RecordType string             `bson:"record_type" json:"record_type"`
+	Answers    []string           `bson:"answers" json:"answers"`
+	Resolver   string             `bson:"resolver" json:"resolver"`
+	ExpiryDays int                `bson:"expiry_days" json:"expiry_days"`
+	Assertions []*Assertion       `bson:"assertions" json:"assertions"`
+	Steps      []*Step            `bson:"steps" json:"steps"`
 	States     []*State           `bson:"states" json:"states"`
 }
 
@@ -32,6 +40,12 @@ type State struct {
 	Targets   []string           `bson:"x" json:"x"`
 	Latency   []int              `bson:"l" json:"l"`
 	Errors    []string           `bson:"r" json:"r"`
+	Banners   []string           `bson:"b,omitempty" json:"b"`
+	Answers   [][]string         `bson:"a,omitempty" json:"a"`
+	Valid     []bool             `bson:"v,omitempty" json:"v"`
+	Hostname  []bool             `bson:"h,omitempty" json:"h"`
+	Expiry    []int              `bson:"y,omitempty" json:"y"`
+	Steps     []*StepResult      `bson:"s,omitempty" json:"s"`
 }
 
 type Header struct {
@@ -84,6 +98,80 @@ func (c *Check) Validate(db *database.Database) (
 		c.Method = ""
 		c.Headers = []*Header{}
 		break
+	case Tcp, Tls:
+		c.Method = ""
+		c.Headers = []*Header{}
+
+		for _, target := range c.Targets {
+			_, _, e := net.SplitHostPort(target)
+			if e != nil {
+				errData = &errortypes.ErrorData{
+					Error:   "check_target_invalid",
+					Message: "Check target must be host:port",
+				}
+				return
+			}
+		}
+		break
+	case Scripted:
+		c.Method = ""
+		c.Headers = []*Header{}
+		c.Targets = []string{}
+
+		if len(c.Steps) == 0 {
+			errData = &errortypes.ErrorData{
+				Error:   "check_steps_invalid",
+				Message: "Check requires at least one step",
+			}
+			return
+		}
+
+		if len(c.Steps) > maxSteps {
+			errData = &errortypes.ErrorData{
+				Error:   "check_steps_invalid",
+				Message: "Check has too many steps",
+			}
+			return
+		}
+
+		for _, step := range c.Steps {
+			errData = step.Validate()
+			if errData != nil {
+				return
+			}
+		}
+		break
+	case Dns:
+		c.Method = ""
+		c.Headers = []*Header{}
+
+		c.RecordType = strings.ToUpper(c.RecordType)
+		if c.RecordType == "" {
+			c.RecordType = "A"
+		}
+		if !recordTypes[c.RecordType] {
+			errData = &errortypes.ErrorData{
+				Error:   "check_record_type_invalid",
+				Message: "Check record type is invalid",
+			}
+			return
+		}
+
+		if c.Resolver != "" {
+			host := c.Resolver
+			h, _, e := net.SplitHostPort(c.Resolver)
+			if e == nil {
+				host = h
+			}
+			if net.ParseIP(host) == nil {
+				errData = &errortypes.ErrorData{
+					Error:   "check_resolver_invalid",
+					Message: "Check resolver must be an IP address",
+				}
+				return
+			}
+		}
+		break
 	default:
 		errData = &errortypes.ErrorData{
 			Error:   "check_type_invalid",
@@ -116,6 +204,45 @@ func (c *Check) Validate(db *database.Database) (
 		c.Headers = []*Header{}
 	}
 
+	if c.Type != Http {
+		c.Assertions = []*Assertion{}
+	} else if c.Assertions == nil {
+		c.Assertions = []*Assertion{}
+	} else {
+		errData = validateAssertions(c.Assertions)
+		if errData != nil {
+			return
+		}
+	}
+
+	if c.Type != Scripted {
+		c.Steps = []*Step{}
+	}
+
+	if c.Type != Tcp {
+		c.Banner = ""
+	}
+
+	if c.Type != Dns {
+		c.RecordType = ""
+		c.Answers = []string{}
+		c.Resolver = ""
+	} else if c.Answers == nil {
+		c.Answers = []string{}
+	}
+
+	if c.Type != Tls {
+		c.ExpiryDays = 0
+	} else if c.ExpiryDays == 0 {
+		c.ExpiryDays = 14
+	} else if c.ExpiryDays < 0 || c.ExpiryDays > 365 {
+		errData = &errortypes.ErrorData{
+			Error:   "check_expiry_days_invalid",
+			Message: "Check expiry days is invalid",
+		}
+		return
+	}
+
 	if c.StatusCode <= 0 || c.StatusCode > 900 {
 		c.StatusCode = 200
 	}
@@ -3,4 +3,40 @@ package check
 const (
 	Http = "http"
 	Ping = "ping"
+	Tcp  = "tcp"
+	Dns  = "dns"
+	Tls  = "tls"
+
+	Scripted = "scripted"
+)
+
+const (
+	BodyContains  = "body_contains"
+	BodyRegex     = "body_regex"
+	JsonEquals    = "json_equals"
+	HeaderEquals  = "header_equals"
+	HeaderRegex   = "header_regex"
+	LatencyBelow  = "latency_below"
+	maxSteps      = 20
+	maxAssertions = 20
 )
+
+var stepMethods = map[string]bool{
+	"GET":    true,
+	"HEAD":   true,
+	"POST":   true,
+	"PUT":    true,
+	"PATCH":  true,
+	"DELETE": true,
+}
+
+var recordTypes = map[string]bool{
+	"A":     true,
+	"AAAA":  true,
+	"CNAME": true,
+	"MX":    true,
+	"NS":    true,
+	"PTR":   true,
+	"SRV":   true,
+	"TXT":   true,
+}
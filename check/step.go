@@ -0,0 +1,192 @@
+package check
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type Assertion struct {
+	Type  string `bson:"type" json:"type"`
+	Key   string `bson:"key" json:"key"`
+	Value string `bson:"value" json:"value"`
+}
+
+// Steps run in order sharing a cookie jar, a failed step stops the check
+type Step struct {
+	Name       string       `bson:"name" json:"name"`
+	Method     string       `bson:"method" json:"method"`
+	Url        string       `bson:"url" json:"url"`
+	Headers    []*Header    `bson:"headers" json:"headers"`
+	Body       string       `bson:"body" json:"body"`
+	StatusCode int          `bson:"status_code" json:"status_code"`
+	Assertions []*Assertion `bson:"assertions" json:"assertions"`
+}
+
+type StepResult struct {
+	Name    string `bson:"n" json:"n"`
+	Status  int    `bson:"s" json:"s"`
+	Latency int    `bson:"l" json:"l"`
+	Error   string `bson:"r" json:"r"`
+}
+
+func (a *Assertion) Validate() (errData *errortypes.ErrorData) {
+	a.Key = utils.FilterStr(a.Key, 256)
+
+	switch a.Type {
+	case BodyContains:
+		a.Key = ""
+		if a.Value == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check body assertion value is empty",
+			}
+			return
+		}
+		break
+	case BodyRegex:
+		a.Key = ""
+		_, err := regexp.Compile(a.Value)
+		if err != nil {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check body assertion regex is invalid",
+			}
+			return
+		}
+		break
+	case JsonEquals:
+		if a.Key == "" || strings.HasPrefix(a.Key, ".") ||
+			strings.HasSuffix(a.Key, ".") || strings.Contains(a.Key, "..") {
+
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check JSON assertion path is invalid",
+			}
+			return
+		}
+		break
+	case HeaderEquals:
+		if a.Key == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check header assertion name is empty",
+			}
+			return
+		}
+		break
+	case HeaderRegex:
+		if a.Key == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check header assertion name is empty",
+			}
+			return
+		}
+
+		_, err := regexp.Compile(a.Value)
+		if err != nil {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check header assertion regex is invalid",
+			}
+			return
+		}
+		break
+	case LatencyBelow:
+		a.Key = ""
+		latency, _ := strconv.Atoi(a.Value)
+		if latency < 1 {
+			errData = &errortypes.ErrorData{
+				Error:   "check_assertion_invalid",
+				Message: "Check latency assertion must be milliseconds",
+			}
+			return
+		}
+		a.Value = strconv.Itoa(latency)
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "check_assertion_type_invalid",
+			Message: "Check assertion type is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+func validateAssertions(assertions []*Assertion) (
+	errData *errortypes.ErrorData) {
+
+	if len(assertions) > maxAssertions {
+		errData = &errortypes.ErrorData{
+			Error:   "check_assertion_invalid",
+			Message: "Check has too many assertions",
+		}
+		return
+	}
+
+	for _, assertion := range assertions {
+		errData = assertion.Validate()
+		if errData != nil {
+			return
+		}
+	}
+
+	return
+}
+
+func (s *Step) Validate() (errData *errortypes.ErrorData) {
+	s.Name = utils.FilterStr(s.Name, 128)
+
+	s.Method = strings.ToUpper(s.Method)
+	if s.Method == "" {
+		s.Method = "GET"
+	}
+	if !stepMethods[s.Method] {
+		errData = &errortypes.ErrorData{
+			Error:   "check_step_method_invalid",
+			Message: "Check step method is invalid",
+		}
+		return
+	}
+
+	u, err := url.Parse(s.Url)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") ||
+		u.Host == "" {
+
+		errData = &errortypes.ErrorData{
+			Error:   "check_step_url_invalid",
+			Message: "Check step URL is invalid",
+		}
+		return
+	}
+
+	if s.Headers == nil {
+		s.Headers = []*Header{}
+	}
+	for _, header := range s.Headers {
+		header.Key = utils.FilterStr(header.Key, 256)
+		header.Value = utils.FilterStr(header.Value, 2048)
+	}
+
+	if s.StatusCode <= 0 || s.StatusCode > 900 {
+		s.StatusCode = 200
+	}
+
+	if s.Assertions == nil {
+		s.Assertions = []*Assertion{}
+	}
+
+	errData = validateAssertions(s.Assertions)
+	if errData != nil {
+		return
+	}
+
+	return
+}
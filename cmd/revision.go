@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/revision"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/sirupsen/logrus"
+)
+
+func Revisions() (err error) {
+	resource := flag.Arg(1)
+	db := database.GetDatabase()
+	defer db.Close()
+
+	switch resource {
+	case "", revision.Policy, revision.Service, revision.Authority:
+		break
+	default:
+		err = &errortypes.ParseError{
+			errors.Newf("cmd.revision: Unknown resource '%s'", resource),
+		}
+		return
+	}
+
+	revs, err := revision.GetRecent(db, resource, 30)
+	if err != nil {
+		return
+	}
+
+	for _, rev := range revs {
+		fields := []string{}
+		for _, change := range rev.Changes {
+			fields = append(fields, change.Field)
+		}
+
+		fmt.Printf("%s %s %s %s v%d %s %s [%s]\n",
+			rev.Id.Hex(),
+			rev.Timestamp.Format("2006-01-02 15:04:05"),
+			rev.Resource,
+			rev.ResourceId.Hex(),
+			rev.Version,
+			rev.Action,
+			rev.Username,
+			strings.Join(fields, ","),
+		)
+	}
+
+	return
+}
+
+func RevisionRollback() (err error) {
+	db := database.GetDatabase()
+	defer db.Close()
+
+	revId, ok := utils.ParseObjectId(flag.Arg(1))
+	if !ok {
+		err = &errortypes.ParseError{
+			errors.New("cmd.revision: Invalid revision ID"),
+		}
+		return
+	}
+
+	rev, err := revision.Get(db, revId)
+	if err != nil {
+		return
+	}
+
+	newRev, errData, err := rev.Rollback(db, nil)
+	if err != nil {
+		return
+	}
+
+	if errData != nil {
+		err = &errortypes.ParseError{
+			errors.Newf("cmd.revision: %s", errData.Message),
+		}
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"resource":    newRev.Resource,
+		"resource_id": newRev.ResourceId.Hex(),
+		"revision":    rev.Version,
+	}).Info("cmd.revision: Revision restored")
+
+	return
+}
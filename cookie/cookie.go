@@ -40,7 +40,7 @@ func (c *Cookie) Set(key string, val string) {
 }
 
 func (c *Cookie) GetSession(db *database.Database, r *http.Request,
-	typ string) (sess *session.Session, err error) {
+	typ string, limits *session.Limits) (sess *session.Session, err error) {
 
 	sessId := c.Get("id")
 	if sessId == "" {
@@ -58,7 +58,7 @@ func (c *Cookie) GetSession(db *database.Database, r *http.Request,
 		return
 	}
 
-	sess, err = session.GetUpdate(db, sessId, r, typ, sig)
+	sess, err = session.GetUpdate(db, sessId, r, typ, sig, limits)
 	if err != nil {
 		switch err.(type) {
 		case *database.NotFoundError:
@@ -77,10 +77,10 @@ func (c *Cookie) GetSession(db *database.Database, r *http.Request,
 }
 
 func (c *Cookie) NewSession(db *database.Database, r *http.Request,
-	id primitive.ObjectID, remember bool, typ string) (
+	id primitive.ObjectID, remember bool, typ string, secondary bool) (
 	sess *session.Session, err error) {
 
-	sess, sig, err := session.New(db, r, id, typ)
+	sess, sig, err := session.New(db, r, id, typ, secondary)
 	if err != nil {
 		err = &errortypes.UnknownError{
 			errors.Wrap(err, "cookie: Unknown session error"),
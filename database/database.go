@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/dropbox/godropbox/errors"
@@ -108,6 +109,51 @@ func (d *Database) Devices() (coll *Collection) {
 	return
 }
 
+func (d *Database) AdminRoles() (coll *Collection) {
+	coll = d.getCollection("admin_roles")
+	return
+}
+
+func (d *Database) ApiKeys() (coll *Collection) {
+	coll = d.getCollection("api_keys")
+	return
+}
+
+func (d *Database) AccessRequests() (coll *Collection) {
+	coll = d.getCollection("access_requests")
+	return
+}
+
+func (d *Database) Netlists() (coll *Collection) {
+	coll = d.getCollection("netlists")
+	return
+}
+
+func (d *Database) NetlistChunks() (coll *Collection) {
+	coll = d.getCollection("netlist_chunks")
+	return
+}
+
+func (d *Database) Revisions() (coll *Collection) {
+	coll = d.getCollection("revisions")
+	return
+}
+
+func (d *Database) OidcClients() (coll *Collection) {
+	coll = d.getCollection("oidc_clients")
+	return
+}
+
+func (d *Database) OidcCodes() (coll *Collection) {
+	coll = d.getCollection("oidc_codes")
+	return
+}
+
+func (d *Database) OidcTokens() (coll *Collection) {
+	coll = d.getCollection("oidc_tokens")
+	return
+}
+
 func (d *Database) Alerts() (coll *Collection) {
 	coll = d.getCollection("alerts")
 	return
@@ -123,6 +169,31 @@ func (d *Database) AlertsEventLock() (coll *Collection) {
 	return
 }
 
+func (d *Database) AlertChannels() (coll *Collection) {
+	coll = d.getCollection("alert_channels")
+	return
+}
+
+func (d *Database) AlertChannelLogs() (coll *Collection) {
+	coll = d.getCollection("alert_channel_logs")
+	return
+}
+
+func (d *Database) AlertInstances() (coll *Collection) {
+	coll = d.getCollection("alert_instances")
+	return
+}
+
+func (d *Database) AlertSilences() (coll *Collection) {
+	coll = d.getCollection("alert_silences")
+	return
+}
+
+func (d *Database) AlertMaintenances() (coll *Collection) {
+	coll = d.getCollection("alert_maintenances")
+	return
+}
+
 func (d *Database) Checks() (coll *Collection) {
 	coll = d.getCollection("checks")
 	return
@@ -133,6 +204,16 @@ func (d *Database) Endpoints() (coll *Collection) {
 	return
 }
 
+func (d *Database) EndpointChallenges() (coll *Collection) {
+	coll = d.getCollection("endpoint_challenges")
+	return
+}
+
+func (d *Database) EndpointBindings() (coll *Collection) {
+	coll = d.getCollection("endpoint_bindings")
+	return
+}
+
 func (d *Database) EndpointsSystem() (coll *Collection) {
 	coll = d.getCollection("endpoints_system")
 	return
@@ -168,6 +249,18 @@ func (d *Database) EndpointsCheckLog() (coll *Collection) {
 	return
 }
 
+func (d *Database) EndpointsRollup(typ string, interval int) (
+	coll *Collection) {
+
+	coll = d.getCollection(fmt.Sprintf("endpoints_%s_%dm", typ, interval))
+	return
+}
+
+func (d *Database) EndpointsRollupMarks() (coll *Collection) {
+	coll = d.getCollection("endpoints_rollup_marks")
+	return
+}
+
 func (d *Database) EndpointsKmsg() (coll *Collection) {
 	coll = d.getCollection("endpoints_kmsg")
 	return
@@ -198,6 +291,11 @@ func (d *Database) SecondaryTokens() (coll *Collection) {
 	return
 }
 
+func (d *Database) PasskeyChallenges() (coll *Collection) {
+	coll = d.getCollection("passkey_challenges")
+	return
+}
+
 func (d *Database) Nonces() (coll *Collection) {
 	coll = d.getCollection("nonces")
 	return
@@ -208,6 +306,16 @@ func (d *Database) Rokeys() (coll *Collection) {
 	return
 }
 
+func (d *Database) StatusPages() (coll *Collection) {
+	coll = d.getCollection("status_pages")
+	return
+}
+
+func (d *Database) StatusPosts() (coll *Collection) {
+	coll = d.getCollection("status_posts")
+	return
+}
+
 func (d *Database) Settings() (coll *Collection) {
 	coll = d.getCollection("settings")
 	return
@@ -428,6 +536,38 @@ func addIndexes() (err error) {
 	if err != nil {
 		return
 	}
+	index = &Index{
+		Collection: db.Users(),
+		Keys: &bson.D{
+			{"admin_roles", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.ApiKeys(),
+		Keys: &bson.D{
+			{"user", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+	index = &Index{
+		Collection: db.ApiKeys(),
+		Keys: &bson.D{
+			{"token_hash", 1},
+		},
+		Unique: true,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
 
 	index = &Index{
 		Collection: db.Audits(),
@@ -440,6 +580,19 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.Audits(),
+		Keys: &bson.D{
+			{"u", 1},
+			{"y", 1},
+			{"t", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Policies(),
 		Keys: &bson.D{
@@ -483,6 +636,126 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.AccessRequests(),
+		Keys: &bson.D{
+			{"user", 1},
+			{"role", 1},
+			{"state", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AccessRequests(),
+		Keys: &bson.D{
+			{"state", 1},
+			{"expires", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.NetlistChunks(),
+		Keys: &bson.D{
+			{"list", 1},
+			{"version", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.OidcClients(),
+		Keys: &bson.D{
+			{"client_id", 1},
+		},
+		Unique: true,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.OidcCodes(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 3 * time.Minute,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.PasskeyChallenges(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 3 * time.Minute,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.Revisions(),
+		Keys: &bson.D{
+			{"resource", 1},
+			{"resource_id", 1},
+			{"version", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.Revisions(),
+		Keys: &bson.D{
+			{"timestamp", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.OidcTokens(),
+		Keys: &bson.D{
+			{"client", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.OidcTokens(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 1 * time.Hour,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.SecondaryTokens(),
 		Keys: &bson.D{
@@ -623,6 +896,135 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.AlertChannels(),
+		Keys: &bson.D{
+			{"roles", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertChannelLogs(),
+		Keys: &bson.D{
+			{"channel", 1},
+			{"timestamp", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertChannelLogs(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 720 * time.Hour,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertInstances(),
+		Keys: &bson.D{
+			{"active_key", 1},
+		},
+		Unique: true,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertInstances(),
+		Keys: &bson.D{
+			{"source", 1},
+			{"active", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertInstances(),
+		Keys: &bson.D{
+			{"active", 1},
+			{"start", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertInstances(),
+		Keys: &bson.D{
+			{"ack_token", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertSilences(),
+		Keys: &bson.D{
+			{"start", 1},
+			{"end", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.AlertMaintenances(),
+		Keys: &bson.D{
+			{"start", 1},
+			{"end", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.StatusPages(),
+		Keys: &bson.D{
+			{"domain", 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.StatusPosts(),
+		Keys: &bson.D{
+			{"page", 1},
+			{"timestamp", -1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Checks(),
 		Keys: &bson.D{
@@ -656,6 +1058,30 @@ func addIndexes() (err error) {
 		return
 	}
 
+	index = &Index{
+		Collection: db.EndpointChallenges(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 3 * time.Minute,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	index = &Index{
+		Collection: db.EndpointBindings(),
+		Keys: &bson.D{
+			{"timestamp", 1},
+		},
+		Expire: 12 * time.Hour,
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
 	index = &Index{
 		Collection: db.Authorities(),
 		Keys: &bson.D{
@@ -36,6 +36,8 @@ func Blocked(c *gin.Context) bool {
 var Agent = &agent.Agent{
 	OperatingSystem: agent.Linux,
 	Browser:         agent.Chrome,
+	Platform:        agent.PlatformLinux,
+	BrowserVersion:  "120.0.0",
 	Ip:              "8.8.8.8",
 	Isp:             "Google",
 	Continent:       "North America",
@@ -7,6 +7,7 @@ const (
 	Ssh       = "ssh"
 	Secondary = "secondary"
 	Phone     = "phone"
+	Passkey   = "passkey"
 	Call      = "call"
 	Message   = "message"
 	Low       = 1
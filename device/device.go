@@ -97,6 +97,23 @@ func (d *Device) Validate(db *database.Database) (
 			return
 		}
 		break
+	case Passkey:
+		if d.Type != WebAuthn {
+			errData = &errortypes.ErrorData{
+				Error:   "device_type_invalid",
+				Message: "Device type is invalid",
+			}
+			return
+		}
+
+		if d.WanRpId == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "device_rp_id_missing",
+				Message: "Passkey relying party is required",
+			}
+			return
+		}
+		break
 	case Phone:
 		if d.Type != Call && d.Type != Message {
 			errData = &errortypes.ErrorData{
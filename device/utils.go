@@ -169,6 +169,46 @@ func CountSecondary(db *database.Database, userId primitive.ObjectID) (
 	return
 }
 
+func CountPasskey(db *database.Database, userId primitive.ObjectID) (
+	count int64, err error) {
+
+	coll := db.Devices()
+
+	count, err = coll.CountDocuments(db, &bson.M{
+		"user": userId,
+		"mode": Passkey,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetTypes(db *database.Database, userId primitive.ObjectID) (
+	types []string, err error) {
+
+	coll := db.Devices()
+	types = []string{}
+
+	typesInf, err := coll.Distinct(db, "type", &bson.M{
+		"user": userId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	for _, typInf := range typesInf {
+		if typ, ok := typInf.(string); ok {
+			types = append(types, typ)
+		}
+	}
+
+	return
+}
+
 func New(userId primitive.ObjectID, typ, mode string) (devc *Device) {
 	devc = &Device{
 		Id:         primitive.NewObjectID(),
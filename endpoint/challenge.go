@@ -0,0 +1,186 @@
+package endpoint
+
+import (
+	"crypto/subtle"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+// Challenge issued to a browser and answered by the endpoint agent, the
+// secret is only known to the browser that requested the challenge
+type Challenge struct {
+	Id        string             `bson:"_id" json:"id"`
+	Secret    string             `bson:"secret" json:"secret"`
+	Endpoint  primitive.ObjectID `bson:"endpoint,omitempty" json:"-"`
+	Timestamp time.Time          `bson:"timestamp" json:"-"`
+}
+
+func (c *Challenge) Insert(db *database.Database) (err error) {
+	coll := db.EndpointChallenges()
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Binding of a browser to an endpoint created from an answered challenge,
+// the binding id is stored in the endpoint token cookie
+type Binding struct {
+	Id        string             `bson:"_id"`
+	Endpoint  primitive.ObjectID `bson:"endpoint"`
+	Timestamp time.Time          `bson:"timestamp"`
+}
+
+func (b *Binding) Insert(db *database.Database) (err error) {
+	coll := db.EndpointBindings()
+
+	_, err = coll.InsertOne(db, b)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func NewChallenge(db *database.Database) (chal *Challenge, err error) {
+	chalId, err := utils.RandStr(32)
+	if err != nil {
+		return
+	}
+
+	secret, err := utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	chal = &Challenge{
+		Id:        chalId,
+		Secret:    secret,
+		Timestamp: time.Now(),
+	}
+
+	err = chal.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Answer challenge from endpoint agent, each challenge can only be
+// answered once
+func (e *Endpoint) AnswerChallenge(db *database.Database, chalId string) (
+	errData *errortypes.ErrorData, err error) {
+
+	coll := db.EndpointChallenges()
+
+	if chalId == "" || e.User.IsZero() {
+		errData = invalidChallenge()
+		return
+	}
+
+	resp, err := coll.UpdateOne(db, &bson.M{
+		"_id": chalId,
+		"endpoint": &bson.M{
+			"$exists": false,
+		},
+		"timestamp": &bson.M{
+			"$gte": time.Now().Add(-ChallengeTtl),
+		},
+	}, &bson.M{
+		"$set": &bson.M{
+			"endpoint": e.Id,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if resp.MatchedCount == 0 {
+		errData = invalidChallenge()
+		return
+	}
+
+	return
+}
+
+// Redeem answered challenge for an endpoint binding, returns nil binding
+// while the challenge has not been answered. The challenge is removed
+// when redeemed.
+func RedeemChallenge(db *database.Database, chalId, secret string) (
+	bind *Binding, errData *errortypes.ErrorData, err error) {
+
+	coll := db.EndpointChallenges()
+	chal := &Challenge{}
+
+	err = coll.FindOneId(chalId, chal)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			errData = invalidChallenge()
+		}
+		return
+	}
+
+	if subtle.ConstantTimeCompare(
+		[]byte(chal.Secret), []byte(secret)) != 1 ||
+		time.Since(chal.Timestamp) > ChallengeTtl {
+
+		errData = invalidChallenge()
+		return
+	}
+
+	if chal.Endpoint.IsZero() {
+		return
+	}
+
+	resp, err := coll.DeleteOne(db, &bson.M{
+		"_id":      chal.Id,
+		"endpoint": chal.Endpoint,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if resp.DeletedCount == 0 {
+		errData = invalidChallenge()
+		return
+	}
+
+	bindId, err := utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	bind = &Binding{
+		Id:        bindId,
+		Endpoint:  chal.Endpoint,
+		Timestamp: time.Now(),
+	}
+
+	err = bind.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func invalidChallenge() *errortypes.ErrorData {
+	return &errortypes.ErrorData{
+		Error:   "endpoint_challenge_invalid",
+		Message: "Endpoint challenge is invalid or expired",
+	}
+}
@@ -0,0 +1,11 @@
+package endpoint
+
+import (
+	"time"
+)
+
+const (
+	TokenCookie  = "pritunl-endpoint-token"
+	ChallengeTtl = 3 * time.Minute
+	BindingTtl   = 12 * time.Hour
+)
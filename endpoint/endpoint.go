@@ -20,6 +20,7 @@ import (
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
 	"github.com/pritunl/pritunl-zero/alert"
 	"github.com/pritunl/pritunl-zero/alertevent"
+	"github.com/pritunl/pritunl-zero/alertinstance"
 	"github.com/pritunl/pritunl-zero/check"
 	"github.com/pritunl/pritunl-zero/constants"
 	"github.com/pritunl/pritunl-zero/database"
@@ -27,7 +28,9 @@ import (
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/node"
 	"github.com/pritunl/pritunl-zero/nonce"
+	"github.com/pritunl/pritunl-zero/prometheus"
 	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
 	"github.com/pritunl/pritunl-zero/utils"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/nacl/box"
@@ -64,12 +67,14 @@ type Data struct {
 	Uptime         uint64     `bson:"uptime" json:"uptime"`
 	Platform       string     `bson:"platform" json:"platform"`
 	PackageUpdates int        `bson:"package_updates" json:"package_updates"`
+	RebootRequired bool       `bson:"reboot_required" json:"reboot_required"`
 	Virtualization string     `bson:"virtualization" json:"virtualization"`
 	CpuCores       int        `bson:"cpu_cores" json:"cpu_cores"`
 	MemTotal       int        `bson:"mem_total" json:"mem_total"`
 	SwapTotal      int        `bson:"swap_total" json:"swap_total"`
 	HugeTotal      int        `bson:"huge_total" json:"huge_total"`
 	Mdadm          []*MdState `bson:"md_stat" json:"md_stat"`
+	Timestamp      time.Time  `bson:"timestamp" json:"timestamp"`
 }
 
 type MdState struct {
@@ -173,6 +178,22 @@ func (e *Endpoint) Validate(db *database.Database) (
 		e.Roles = []string{}
 	}
 
+	if !e.User.IsZero() {
+		_, err = user.Get(db, e.User)
+		if err != nil {
+			if _, ok := err.(*database.NotFoundError); !ok {
+				return
+			}
+			err = nil
+
+			errData = &errortypes.ErrorData{
+				Error:   "user_invalid",
+				Message: "Endpoint user does not exist",
+			}
+			return
+		}
+	}
+
 	if e.ClientKey == nil || e.ServerKey == nil {
 		err = e.GenerateKey()
 		if err != nil {
@@ -531,6 +552,10 @@ func (e *Endpoint) InsertDoc(db *database.Database,
 
 	timestamp := doc.Format(e.Id)
 
+	if load, ok := doc.(*endpoints.Load); ok && e.Data != nil {
+		load.CpuCores = e.Data.CpuCores
+	}
+
 	staticData := doc.StaticData()
 	if staticData != nil && (!constants.Production ||
 		timestamp == timestamp.Truncate(5*time.Minute)) {
@@ -559,6 +584,12 @@ func (e *Endpoint) InsertDoc(db *database.Database,
 			} else {
 				return
 			}
+		} else {
+			prometheus.Write(&prometheus.Target{
+				Id:    e.Id,
+				Name:  e.Name,
+				Roles: e.Roles,
+			}, doc)
 		}
 	}
 
@@ -569,13 +600,37 @@ func (e *Endpoint) InsertDoc(db *database.Database,
 			return
 		}
 
-		actAlrts := doc.CheckAlerts(alerts)
+		checkId := primitive.NilObjectID
+		if chck, ok := doc.(*endpoints.Check); ok {
+			checkId = chck.Check
+		}
+
+		active, er := alertinstance.GetActiveAlerts(db, e.Id, checkId)
+		if er != nil {
+			err = er
+			return
+		}
+
+		condAlrts, er := endpoints.CheckConditions(db, e.Id,
+			string(docType), timestamp, alerts, active)
+		if er != nil {
+			err = er
+			return
+		}
+
+		fired := []primitive.ObjectID{}
+		actAlrts := append(doc.CheckAlerts(alerts), condAlrts...)
 		if actAlrts != nil && len(actAlrts) > 0 {
 			for _, alrt := range actAlrts {
-				go alertevent.New(e.Roles, e.Id, alrt.Name, e.Name, alrt.Resource,
-					alrt.Message, alrt.Level, alrt.Frequency)
+				fired = append(fired, alrt.AlertId)
+				go alertevent.New(e.Roles, e.Id, checkId, alrt.AlertId,
+					alrt.Name, e.Name, alrt.Resource, alrt.Message,
+					alrt.Level, alrt.Frequency)
 			}
 		}
+
+		go alertevent.Resolve(e.Id, checkId,
+			endpoints.Evaluated(string(docType), alerts), fired)
 	}
 
 	return
@@ -0,0 +1,92 @@
+package endpoint
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+func invalidToken(msg string) *errortypes.ErrorData {
+	return &errortypes.ErrorData{
+		Error:   "endpoint_token_invalid",
+		Message: msg,
+	}
+}
+
+// Get endpoint token from request cookie
+func GetToken(r *http.Request) (token string) {
+	if r == nil {
+		return
+	}
+
+	cook, err := r.Cookie(TokenCookie)
+	if err == nil {
+		token = cook.Value
+	}
+
+	return
+}
+
+// Set endpoint token cookie from binding
+func SetToken(w http.ResponseWriter, bind *Binding) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     TokenCookie,
+		Value:    bind.Id,
+		Path:     "/",
+		MaxAge:   int(BindingTtl / time.Second),
+		Secure:   true,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// Parse endpoint token, the token is the id of a binding created from a
+// challenge answered by the endpoint agent. The endpoint must belong to
+// the user.
+func ParseToken(db *database.Database, token string,
+	userId primitive.ObjectID) (endpt *Endpoint,
+	errData *errortypes.ErrorData, err error) {
+
+	if token == "" {
+		errData = invalidToken("Endpoint verification required")
+		return
+	}
+
+	coll := db.EndpointBindings()
+	bind := &Binding{}
+
+	err = coll.FindOneId(token, bind)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			errData = invalidToken("Endpoint verification expired")
+		}
+		return
+	}
+
+	if time.Since(bind.Timestamp) > BindingTtl {
+		errData = invalidToken("Endpoint verification expired")
+		return
+	}
+
+	endpt, err = Get(db, bind.Endpoint)
+	if err != nil {
+		endpt = nil
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			errData = invalidToken("Endpoint not found")
+		}
+		return
+	}
+
+	if endpt.User.IsZero() || endpt.User != userId {
+		endpt = nil
+		errData = invalidToken("Endpoint not registered to user")
+		return
+	}
+
+	return
+}
@@ -25,19 +25,27 @@ type Check struct {
 	TargetsDown int `bson:"d" json:"-"`
 	LatencyAvg  int `bson:"p" json:"-"`
 
-	TargetsIn []string `bson:"-" json:"x"`
-	LatencyIn []int    `bson:"-" json:"l"`
-	ErrorsIn  []string `bson:"-" json:"r"`
+	TargetsIn []string            `bson:"-" json:"x"`
+	LatencyIn []int               `bson:"-" json:"l"`
+	ErrorsIn  []string            `bson:"-" json:"r"`
+	BannersIn []string            `bson:"-" json:"b"`
+	AnswersIn [][]string          `bson:"-" json:"a"`
+	ValidIn   []bool              `bson:"-" json:"v"`
+	HostIn    []bool              `bson:"-" json:"h"`
+	ExpiryIn  []int               `bson:"-" json:"y"`
+	StepsIn   []*check.StepResult `bson:"-" json:"s"`
 
 	checkName string `bson:"-" json:"-"`
+	checkType string `bson:"-" json:"-"`
 }
 
 type CheckLog struct {
-	Id        primitive.ObjectID `bson:"_id" json:"id"`
-	Check     primitive.ObjectID `bson:"c" json:"c"`
-	Endpoint  primitive.ObjectID `bson:"e" json:"e"`
-	Timestamp time.Time          `bson:"t" json:"t"`
-	Log       []string           `bson:"l" json:"l"`
+	Id        primitive.ObjectID  `bson:"_id" json:"id"`
+	Check     primitive.ObjectID  `bson:"c" json:"c"`
+	Endpoint  primitive.ObjectID  `bson:"e" json:"e"`
+	Timestamp time.Time           `bson:"t" json:"t"`
+	Log       []string            `bson:"l" json:"l"`
+	Steps     []*check.StepResult `bson:"s,omitempty" json:"s"`
 }
 
 type CheckAgg struct {
@@ -73,6 +81,22 @@ func (d *Check) Format(id primitive.ObjectID) time.Time {
 		d.ErrorsIn = []string{}
 	}
 
+	if len(d.StepsIn) > 0 && len(d.TargetsIn) == 0 {
+		latency := 0
+		for _, step := range d.StepsIn {
+			latency += step.Latency
+		}
+
+		if d.stepFailed() {
+			d.TargetsDown = 1
+		} else {
+			d.TargetsUp = 1
+		}
+		d.LatencyAvg = latency
+
+		return d.Timestamp
+	}
+
 	count := 0
 	for _, e := range d.ErrorsIn {
 		if e == "" {
@@ -107,21 +131,180 @@ func (d *Check) StaticData() *bson.M {
 	return nil
 }
 
+func (d *Check) stepFailed() bool {
+	for _, step := range d.StepsIn {
+		if step.Error != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func (d *Check) stepLog() (log []string) {
+	log = []string{}
+
+	for i, step := range d.StepsIn {
+		name := step.Name
+		if name == "" {
+			name = fmt.Sprintf("step %d", i+1)
+		}
+
+		if step.Error != "" {
+			log = append(log, fmt.Sprintf("[%s] %d %dms %s",
+				name, step.Status, step.Latency, step.Error))
+		} else {
+			log = append(log, fmt.Sprintf("[%s] %d %dms ok",
+				name, step.Status, step.Latency))
+		}
+	}
+
+	return
+}
+
+func (d *Check) firstError() (target, er string) {
+	for i, step := range d.StepsIn {
+		if step.Error != "" {
+			target = step.Name
+			if target == "" {
+				target = fmt.Sprintf("step %d", i+1)
+			}
+			er = step.Error
+			return
+		}
+	}
+
+	for i, e := range d.ErrorsIn {
+		if e != "" {
+			if len(d.TargetsIn) > i {
+				target = d.TargetsIn[i]
+			}
+			er = e
+			return
+		}
+	}
+
+	for i, valid := range d.ValidIn {
+		if !valid {
+			if len(d.TargetsIn) > i {
+				target = d.TargetsIn[i]
+			}
+			er = "certificate chain invalid"
+			return
+		}
+	}
+
+	for i, match := range d.HostIn {
+		if !match {
+			if len(d.TargetsIn) > i {
+				target = d.TargetsIn[i]
+			}
+			er = "certificate hostname mismatch"
+			return
+		}
+	}
+
+	return
+}
+
 func (d *Check) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
 	alerts = []*Alert{}
 
 	for _, resource := range resources {
 		switch resource.Resource {
 		case alert.CheckHttpFailed:
+			if d.checkType == check.Scripted {
+				target, er := d.firstError()
+				if er != "" {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Check HTTP error: %s [%s] %s",
+						d.checkName,
+						target,
+						er,
+					)))
+				}
+				break
+			}
+
+			if d.checkType != "" && d.checkType != check.Http &&
+				d.checkType != check.Ping {
+
+				break
+			}
+
 			for _, er := range d.ErrorsIn {
 				if er != "" {
-					alerts = []*Alert{
-						NewAlert(resource, fmt.Sprintf(
-							"Check HTTP error: %s %s",
-							d.checkName,
-							er,
-						)),
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Check HTTP error: %s %s",
+						d.checkName,
+						er,
+					)))
+					break
+				}
+			}
+			break
+		case alert.CheckTcpFailed:
+			if d.checkType != check.Tcp {
+				break
+			}
+
+			target, er := d.firstError()
+			if er != "" {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"Check TCP error: %s [%s] %s",
+					d.checkName,
+					target,
+					er,
+				)))
+			}
+			break
+		case alert.CheckDnsFailed:
+			if d.checkType != check.Dns {
+				break
+			}
+
+			target, er := d.firstError()
+			if er != "" {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"Check DNS error: %s [%s] %s",
+					d.checkName,
+					target,
+					er,
+				)))
+			}
+			break
+		case alert.CheckTlsFailed:
+			if d.checkType != check.Tls {
+				break
+			}
+
+			target, er := d.firstError()
+			if er != "" {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"Check TLS error: %s [%s] %s",
+					d.checkName,
+					target,
+					er,
+				)))
+			}
+			break
+		case alert.CheckTlsExpiry:
+			if d.checkType != check.Tls {
+				break
+			}
+
+			for i, days := range d.ExpiryIn {
+				if days < resource.ValueInt {
+					target := ""
+					if len(d.TargetsIn) > i {
+						target = d.TargetsIn[i]
 					}
+
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Check TLS certificate expiring: %s [%s] %d days",
+						d.checkName,
+						target,
+						days,
+					)))
 					break
 				}
 			}
@@ -132,9 +315,25 @@ func (d *Check) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
 	return
 }
 
+func (d *Check) CheckName() string {
+	return d.checkName
+}
+
 func (d *Check) Handle(db *database.Database) (handled, checkAlerts bool,
 	err error) {
 
+	chck, err := check.Get(db, d.Check)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		} else {
+			return
+		}
+	} else {
+		d.checkName = chck.Name
+		d.checkType = chck.Type
+	}
+
 	if d.TargetsDown == 0 {
 		return
 	}
@@ -146,6 +345,12 @@ func (d *Check) Handle(db *database.Database) (handled, checkAlerts bool,
 		}
 	}
 
+	var steps []*check.StepResult
+	if d.stepFailed() {
+		log = append(log, d.stepLog()...)
+		steps = d.StepsIn
+	}
+
 	if len(log) == 0 {
 		return
 	}
@@ -156,6 +361,7 @@ func (d *Check) Handle(db *database.Database) (handled, checkAlerts bool,
 		Endpoint:  d.Endpoint,
 		Timestamp: d.Timestamp,
 		Log:       log,
+		Steps:     steps,
 	}
 
 	coll := d.GetLogCollection(db)
@@ -194,6 +400,12 @@ func (d *Check) HandleOld(db *database.Database) (handled, checkAlerts bool,
 		Targets:   d.TargetsIn,
 		Latency:   d.LatencyIn,
 		Errors:    d.ErrorsIn,
+		Banners:   d.BannersIn,
+		Answers:   d.AnswersIn,
+		Valid:     d.ValidIn,
+		Hostname:  d.HostIn,
+		Expiry:    d.ExpiryIn,
+		Steps:     d.StepsIn,
 	}
 
 	checkAlerts, err = chck.UpdateState(db, state)
@@ -311,7 +523,7 @@ func GetCheckChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsCheck()
+	coll := getChartCollection(db, "check", start, interval)
 	chart := NewChart(start, end, interval)
 
 	chck, err := check.Get(db, checkId)
@@ -0,0 +1,382 @@
+package endpoints
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/alert"
+	"github.com/pritunl/pritunl-zero/database"
+)
+
+type sample struct {
+	Timestamp time.Time
+	Value     float64
+}
+
+type series = map[string][]*sample
+
+func getSystemSeries(c context.Context, db *database.Database,
+	endpoint primitive.ObjectID, resource string, start, end time.Time) (
+	data series, err error) {
+
+	coll := db.EndpointsSystem()
+	data = series{}
+
+	cursor, err := coll.Find(
+		c,
+		&bson.M{
+			"e": endpoint,
+			"t": &bson.M{
+				"$gte": start,
+				"$lte": end,
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"t", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		doc := &System{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		var val float64
+		switch resource {
+		case alert.SystemCpuLevel:
+			val = doc.CpuUsage
+			break
+		case alert.SystemMemoryLevel:
+			val = doc.MemUsage
+			break
+		case alert.SystemSwapLevel:
+			val = doc.SwapUsage
+			break
+		case alert.SystemHugePagesLevel:
+			val = doc.HugeUsage
+			break
+		}
+
+		data[""] = append(data[""], &sample{
+			Timestamp: doc.Timestamp,
+			Value:     val,
+		})
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func getDiskSeries(c context.Context, db *database.Database,
+	endpoint primitive.ObjectID, start, end time.Time) (
+	data series, err error) {
+
+	coll := db.EndpointsDisk()
+	data = series{}
+
+	cursor, err := coll.Find(
+		c,
+		&bson.M{
+			"e": endpoint,
+			"t": &bson.M{
+				"$gte": start,
+				"$lte": end,
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"t", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(c)
+
+	for cursor.Next(c) {
+		doc := &Disk{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		for _, mount := range doc.Mounts {
+			data[mount.Path] = append(data[mount.Path], &sample{
+				Timestamp: doc.Timestamp,
+				Value:     mount.Used,
+			})
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func getSeries(c context.Context, db *database.Database,
+	endpoint primitive.ObjectID, resource string, start, end time.Time) (
+	data series, err error) {
+
+	switch resource {
+	case alert.SystemCpuLevel, alert.SystemMemoryLevel,
+		alert.SystemSwapLevel, alert.SystemHugePagesLevel:
+
+		data, err = getSystemSeries(c, db, endpoint, resource, start, end)
+		return
+	case alert.DiskUsageLevel:
+		data, err = getDiskSeries(c, db, endpoint, start, end)
+		return
+	default:
+		data = series{}
+		return
+	}
+}
+
+func aggregate(resource *alert.Alert, samples []*sample) float64 {
+	switch resource.Aggregate {
+	case alert.Average:
+		total := 0.0
+		for _, smpl := range samples {
+			total += smpl.Value
+		}
+		return total / float64(len(samples))
+	case alert.Maximum:
+		max := samples[0].Value
+		for _, smpl := range samples[1:] {
+			if smpl.Value > max {
+				max = smpl.Value
+			}
+		}
+		return max
+	case alert.Percentile:
+		vals := make([]float64, len(samples))
+		for i, smpl := range samples {
+			vals[i] = smpl.Value
+		}
+		sort.Float64s(vals)
+
+		rank := int(math.Ceil(
+			float64(resource.Quantile) / 100 * float64(len(vals))))
+		if rank < 1 {
+			rank = 1
+		}
+		return vals[rank-1]
+	default:
+		return samples[len(samples)-1].Value
+	}
+}
+
+// Get aggregated value at each sample, samples must be sorted
+func aggregateSeries(resource *alert.Alert, samples []*sample) (
+	values []*sample) {
+
+	values = []*sample{}
+	window := time.Duration(resource.Window) * time.Second
+
+	start := 0
+	for i, smpl := range samples {
+		if resource.Aggregate == alert.Latest {
+			values = append(values, smpl)
+			continue
+		}
+
+		for samples[start].Timestamp.Before(
+			smpl.Timestamp.Add(-window + time.Minute)) {
+
+			start += 1
+		}
+
+		values = append(values, &sample{
+			Timestamp: smpl.Timestamp,
+			Value:     aggregate(resource, samples[start:i+1]),
+		})
+	}
+
+	return
+}
+
+// Check if condition is met at end of series. When the alert is already
+// active the clear threshold is used and the condition holds until the
+// value drops to the clear threshold.
+func evaluate(resource *alert.Alert, samples []*sample, end time.Time,
+	active bool) (value float64, ok bool) {
+
+	values := aggregateSeries(resource, samples)
+	if len(values) == 0 {
+		return
+	}
+
+	last := values[len(values)-1]
+	if last.Timestamp.Before(end) {
+		return
+	}
+	value = last.Value
+
+	if active && resource.ClearInt > 0 {
+		ok = value > float64(resource.ClearInt)
+		return
+	}
+
+	if value <= float64(resource.ValueInt) {
+		return
+	}
+
+	if active || resource.Duration == 0 {
+		ok = true
+		return
+	}
+
+	since := end.Add(-time.Duration(resource.Duration)*time.Second +
+		time.Minute)
+	if values[0].Timestamp.After(since) {
+		return
+	}
+
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i].Timestamp.Before(since) {
+			break
+		}
+		if values[i].Value <= float64(resource.ValueInt) {
+			return
+		}
+	}
+
+	ok = true
+	return
+}
+
+func conditionMessage(resource *alert.Alert, key string,
+	value float64) string {
+
+	msg := ""
+	switch resource.Resource {
+	case alert.SystemCpuLevel:
+		msg = fmt.Sprintf("System cpu high usage (%.2f%%)", value)
+		break
+	case alert.SystemMemoryLevel:
+		msg = fmt.Sprintf("System low on memory (%.2f%%)", value)
+		break
+	case alert.SystemSwapLevel:
+		msg = fmt.Sprintf("System low on swap (%.2f%%)", value)
+		break
+	case alert.SystemHugePagesLevel:
+		msg = fmt.Sprintf("System low on hugepages (%.2f%%)", value)
+		break
+	case alert.DiskUsageLevel:
+		msg = fmt.Sprintf("Disk low on space %s (%.2f%%)", key, value)
+		break
+	}
+
+	switch resource.Aggregate {
+	case alert.Average:
+		msg += fmt.Sprintf(" average over %dm", resource.Window/60)
+		break
+	case alert.Maximum:
+		msg += fmt.Sprintf(" maximum over %dm", resource.Window/60)
+		break
+	case alert.Percentile:
+		msg += fmt.Sprintf(" p%d over %dm",
+			resource.Quantile, resource.Window/60)
+		break
+	}
+
+	if resource.Duration > 0 {
+		msg += fmt.Sprintf(" for %dm", resource.Duration/60)
+	}
+
+	return msg
+}
+
+// Evaluate alerts with conditions for doc type against the stored time
+// series ending at timestamp, active contains ids of alerts currently
+// firing on the endpoint
+func CheckConditions(db *database.Database, endpoint primitive.ObjectID,
+	typ string, timestamp time.Time, resources []*alert.Alert,
+	active []primitive.ObjectID) (alerts []*Alert, err error) {
+
+	alerts = []*Alert{}
+
+	for _, resource := range resources {
+		if !resource.HasCondition() {
+			continue
+		}
+
+		evaluated := false
+		for _, res := range docResources[typ] {
+			if resource.Resource == res {
+				evaluated = true
+				break
+			}
+		}
+		if !evaluated {
+			continue
+		}
+
+		isActive := false
+		for _, alertId := range active {
+			if alertId == resource.Id {
+				isActive = true
+				break
+			}
+		}
+
+		lookback := resource.Duration + resource.Window
+		start := timestamp.Add(-time.Duration(lookback) * time.Second)
+
+		data, e := getSeries(db, db, endpoint, resource.Resource,
+			start, timestamp)
+		if e != nil {
+			err = e
+			return
+		}
+
+		keys := make([]string, 0, len(data))
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			if key != "" && resource.Ignored(key) {
+				continue
+			}
+
+			value, ok := evaluate(resource, data[key], timestamp, isActive)
+			if ok {
+				alerts = append(alerts, NewAlert(
+					resource, conditionMessage(resource, key, value)))
+				break
+			}
+		}
+	}
+
+	return
+}
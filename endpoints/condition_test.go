@@ -0,0 +1,210 @@
+package endpoints
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/alert"
+)
+
+var testStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testSamples(values ...float64) (samples []*sample) {
+	samples = []*sample{}
+	for i, val := range values {
+		samples = append(samples, &sample{
+			Timestamp: testStart.Add(time.Duration(i) * time.Minute),
+			Value:     val,
+		})
+	}
+	return
+}
+
+func testEnd(samples []*sample) time.Time {
+	return samples[len(samples)-1].Timestamp
+}
+
+func TestAggregateSeries(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *alert.Alert
+		values   []float64
+		result   []float64
+	}{
+		{
+			"latest",
+			&alert.Alert{},
+			[]float64{10, 20, 30},
+			[]float64{10, 20, 30},
+		},
+		{
+			"window equal to sample interval",
+			&alert.Alert{Aggregate: alert.Average, Window: 60},
+			[]float64{10, 20, 30},
+			[]float64{10, 20, 30},
+		},
+		{
+			"average window",
+			&alert.Alert{Aggregate: alert.Average, Window: 180},
+			[]float64{10, 20, 30, 40},
+			[]float64{10, 15, 20, 30},
+		},
+		{
+			"maximum window",
+			&alert.Alert{Aggregate: alert.Maximum, Window: 120},
+			[]float64{50, 10, 30, 20},
+			[]float64{50, 50, 30, 30},
+		},
+		{
+			"single sample",
+			&alert.Alert{Aggregate: alert.Average, Window: 600},
+			[]float64{42},
+			[]float64{42},
+		},
+		{
+			"percentile rank",
+			&alert.Alert{Aggregate: alert.Percentile, Window: 240,
+				Quantile: 50},
+			[]float64{4, 3, 2, 1},
+			[]float64{4, 3, 3, 2},
+		},
+		{
+			"percentile upper rank",
+			&alert.Alert{Aggregate: alert.Percentile, Window: 240,
+				Quantile: 95},
+			[]float64{1, 2, 3, 4},
+			[]float64{1, 2, 3, 4},
+		},
+		{
+			"percentile zero quantile",
+			&alert.Alert{Aggregate: alert.Percentile, Window: 240},
+			[]float64{4, 3, 2, 1},
+			[]float64{4, 3, 2, 1},
+		},
+	}
+
+	for _, test := range tests {
+		values := aggregateSeries(test.resource, testSamples(test.values...))
+
+		if len(values) != len(test.result) {
+			t.Errorf("%s: got %d values want %d",
+				test.name, len(values), len(test.result))
+			continue
+		}
+
+		for i, val := range values {
+			if val.Value != test.result[i] {
+				t.Errorf("%s: value %d got %f want %f",
+					test.name, i, val.Value, test.result[i])
+			}
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *alert.Alert
+		values   []float64
+		active   bool
+		stale    bool
+		result   bool
+	}{
+		{
+			"above threshold",
+			&alert.Alert{ValueInt: 80, Aggregate: alert.Average,
+				Window: 60},
+			[]float64{50, 90},
+			false,
+			false,
+			true,
+		},
+		{
+			"equal to threshold",
+			&alert.Alert{ValueInt: 80, Aggregate: alert.Average,
+				Window: 60},
+			[]float64{50, 80},
+			false,
+			false,
+			false,
+		},
+		{
+			"stale series",
+			&alert.Alert{ValueInt: 80, Aggregate: alert.Average,
+				Window: 60},
+			[]float64{90, 90},
+			false,
+			true,
+			false,
+		},
+		{
+			"duration satisfied",
+			&alert.Alert{ValueInt: 80, Duration: 180},
+			[]float64{50, 90, 90, 90},
+			false,
+			false,
+			true,
+		},
+		{
+			"duration interrupted",
+			&alert.Alert{ValueInt: 80, Duration: 180},
+			[]float64{90, 90, 50, 90},
+			false,
+			false,
+			false,
+		},
+		{
+			"duration without enough samples",
+			&alert.Alert{ValueInt: 80, Duration: 180},
+			[]float64{90, 90},
+			false,
+			false,
+			false,
+		},
+		{
+			"duration ignored when active",
+			&alert.Alert{ValueInt: 80, Duration: 180},
+			[]float64{90},
+			true,
+			false,
+			true,
+		},
+		{
+			"clear hysteresis holds",
+			&alert.Alert{ValueInt: 80, ClearInt: 50},
+			[]float64{90, 60},
+			true,
+			false,
+			true,
+		},
+		{
+			"clear hysteresis at clear value",
+			&alert.Alert{ValueInt: 80, ClearInt: 50},
+			[]float64{90, 50},
+			true,
+			false,
+			false,
+		},
+		{
+			"clear ignored when inactive",
+			&alert.Alert{ValueInt: 80, ClearInt: 50},
+			[]float64{60},
+			false,
+			false,
+			false,
+		},
+	}
+
+	for _, test := range tests {
+		samples := testSamples(test.values...)
+		end := testEnd(samples)
+		if test.stale {
+			end = end.Add(time.Minute)
+		}
+
+		_, ok := evaluate(test.resource, samples, end, test.active)
+		if ok != test.result {
+			t.Errorf("%s: got %t want %t", test.name, ok, test.result)
+		}
+	}
+}
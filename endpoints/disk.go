@@ -76,16 +76,20 @@ func (d *Disk) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
 	alerts = []*Alert{}
 
 	for _, resource := range resources {
-		if resource.Resource == alert.DiskUsageLevel {
+		if resource.Resource == alert.DiskUsageLevel &&
+			!resource.HasCondition() {
+
 			for _, mount := range d.Mounts {
+				if resource.Ignored(mount.Path) {
+					continue
+				}
+
 				if mount.Used > float64(resource.ValueInt) {
-					alerts = []*Alert{
-						NewAlert(resource, fmt.Sprintf(
-							"Disk low on space %s (%.2f%%)",
-							mount.Path,
-							mount.Used,
-						)),
-					}
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Disk low on space %s (%.2f%%)",
+						mount.Path,
+						mount.Used,
+					)))
 				}
 			}
 		}
@@ -166,7 +170,7 @@ func GetDiskChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsDisk()
+	coll := getChartCollection(db, "disk", start, interval)
 	chart := NewChart(start, end, interval)
 
 	timeQuery := bson.D{
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pritunl/mongo-go-driver/bson"
@@ -78,6 +79,45 @@ func (d *DiskIo) StaticData() *bson.M {
 }
 
 func (d *DiskIo) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
+	alerts = []*Alert{}
+
+	for _, resource := range resources {
+		for _, dsk := range d.Disks {
+			if resource.Ignored(dsk.Name) {
+				continue
+			}
+
+			switch resource.Resource {
+			case alert.DiskIoUtilLevel:
+				util := float64(dsk.TimeIo) / 60000 * 100
+				if util > float64(resource.ValueInt) {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Disk IO utilization high %s (%.2f%%)",
+						dsk.Name,
+						util,
+					)))
+				}
+				break
+			case alert.DiskIoAwaitLevel:
+				count := dsk.CountRead + dsk.CountWrite
+				if count == 0 {
+					break
+				}
+
+				await := float64(dsk.TimeRead+dsk.TimeWrite) /
+					float64(count)
+				if await > float64(resource.ValueInt) {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Disk IO latency high %s (%.2fms)",
+						dsk.Name,
+						await,
+					)))
+				}
+				break
+			}
+		}
+	}
+
 	return
 }
 
@@ -160,7 +200,7 @@ func GetDiskIoChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsDiskIo()
+	coll := getChartCollection(db, "diskio", start, interval)
 	chart := NewChart(start, end, interval)
 
 	timeQuery := bson.D{
@@ -173,7 +173,66 @@ func NewChart(start, end time.Time, interval time.Duration) (chrt *Chart) {
 	return
 }
 
+// Alert resources evaluated by each doc type
+var docResources = map[string][]string{
+	"system": {
+		alert.SystemCpuLevel,
+		alert.SystemMemoryLevel,
+		alert.SystemSwapLevel,
+		alert.SystemHugePagesLevel,
+		alert.SystemMdFailed,
+		alert.SystemPackageUpdates,
+		alert.SystemRebootRequired,
+		alert.SystemUptimeLevel,
+		alert.SystemClockDrift,
+	},
+	"load": {
+		alert.LoadLevel,
+	},
+	"disk": {
+		alert.DiskUsageLevel,
+	},
+	"diskio": {
+		alert.DiskIoUtilLevel,
+		alert.DiskIoAwaitLevel,
+	},
+	"network": {
+		alert.NetworkErrorRate,
+		alert.NetworkDropRate,
+		alert.NetworkThroughput,
+	},
+	"kmsg": {
+		alert.KmsgKeyword,
+	},
+	"check": {
+		alert.CheckHttpFailed,
+		alert.CheckTcpFailed,
+		alert.CheckDnsFailed,
+		alert.CheckTlsFailed,
+		alert.CheckTlsExpiry,
+	},
+}
+
+// Get ids of alerts with resources evaluated by doc type
+func Evaluated(typ string, resources []*alert.Alert) (
+	alertIds []primitive.ObjectID) {
+
+	alertIds = []primitive.ObjectID{}
+
+	for _, resource := range resources {
+		for _, res := range docResources[typ] {
+			if resource.Resource == res {
+				alertIds = append(alertIds, resource.Id)
+				break
+			}
+		}
+	}
+
+	return
+}
+
 type Alert struct {
+	AlertId   primitive.ObjectID
 	Name      string
 	Resource  string
 	Message   string
@@ -183,6 +242,7 @@ type Alert struct {
 
 func NewAlert(resource *alert.Alert, message string) (alrt *Alert) {
 	alrt = &Alert{
+		AlertId:   resource.Id,
 		Name:      resource.Name,
 		Resource:  resource.Resource,
 		Message:   message,
@@ -64,13 +64,11 @@ func (d *Kmsg) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
 			if strings.Contains(strings.ToLower(d.Message),
 				strings.ToLower(resource.ValueStr)) {
 
-				alerts = []*Alert{
-					NewAlert(resource, fmt.Sprintf(
-						"Kmsg keyword match (%s): %s",
-						resource.ValueStr,
-						strings.Split(d.Message, "\n")[0],
-					)),
-				}
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"Kmsg keyword match (%s): %s",
+					resource.ValueStr,
+					strings.Split(d.Message, "\n")[0],
+				)))
 			}
 			break
 		}
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pritunl/mongo-go-driver/bson"
@@ -19,6 +20,8 @@ type Load struct {
 	Load1  float64 `bson:"lx" json:"lx"`
 	Load5  float64 `bson:"ly" json:"ly"`
 	Load15 float64 `bson:"lz" json:"lz"`
+
+	CpuCores int `bson:"-" json:"-"`
 }
 
 type LoadAgg struct {
@@ -44,6 +47,26 @@ func (d *Load) StaticData() *bson.M {
 }
 
 func (d *Load) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
+	alerts = []*Alert{}
+
+	if d.CpuCores < 1 {
+		return
+	}
+
+	for _, resource := range resources {
+		if resource.Resource != alert.LoadLevel {
+			continue
+		}
+
+		perCore := d.Load1 / float64(d.CpuCores) * 100
+		if perCore > float64(resource.ValueInt) {
+			alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+				"System load high (%.2f per core)",
+				perCore/100,
+			)))
+		}
+	}
+
 	return
 }
 
@@ -120,7 +143,7 @@ func GetLoadChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsLoad()
+	coll := getChartCollection(db, "load", start, interval)
 	chart := NewChart(start, end, interval)
 
 	timeQuery := bson.D{
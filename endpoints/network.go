@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/pritunl/mongo-go-driver/bson"
@@ -84,6 +85,50 @@ func (d *Network) StaticData() *bson.M {
 }
 
 func (d *Network) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
+	alerts = []*Alert{}
+
+	for _, resource := range resources {
+		for _, iface := range d.Interfaces {
+			if resource.Ignored(iface.Name) {
+				continue
+			}
+
+			switch resource.Resource {
+			case alert.NetworkErrorRate:
+				errs := iface.ErrorsSent + iface.ErrorsRecv
+				if errs > uint64(resource.ValueInt) {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Network interface errors %s (%d/min)",
+						iface.Name,
+						errs,
+					)))
+				}
+				break
+			case alert.NetworkDropRate:
+				drops := iface.DropsSent + iface.DropsRecv
+				if drops > uint64(resource.ValueInt) {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Network interface drops %s (%d/min)",
+						iface.Name,
+						drops,
+					)))
+				}
+				break
+			case alert.NetworkThroughput:
+				mbps := float64(iface.BytesSent+iface.BytesRecv) *
+					8 / 60 / 1000000
+				if mbps > float64(resource.ValueInt) {
+					alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+						"Network interface throughput high %s (%.2f Mbps)",
+						iface.Name,
+						mbps,
+					)))
+				}
+				break
+			}
+		}
+	}
+
 	return
 }
 
@@ -167,7 +212,7 @@ func GetNetworkChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsNetwork()
+	coll := getChartCollection(db, "network", start, interval)
 	chart := NewChart(start, end, interval)
 
 	timeQuery := bson.D{
@@ -0,0 +1,686 @@
+package endpoints
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/settings"
+)
+
+var (
+	rollupTypes = []string{
+		"system",
+		"load",
+		"disk",
+		"diskio",
+		"network",
+		"check",
+	}
+	tierIntervals = set.NewSet(5, 10, 15, 30, 60, 120, 360, 720, 1440)
+	rollupIndexes = set.NewSet()
+	rollupLock    = sync.Mutex{}
+)
+
+const (
+	rollupWindow   = 24 * time.Hour
+	rollupBackfill = 7 * 24 * time.Hour
+)
+
+type rollupField struct {
+	name    string
+	op      string
+	integer bool
+}
+
+type rollupSpec struct {
+	check  bool
+	array  string
+	key    string
+	fields []*rollupField
+}
+
+type rollupKey struct {
+	Check     primitive.ObjectID `bson:"c"`
+	Endpoint  primitive.ObjectID `bson:"e"`
+	Timestamp time.Time          `bson:"t"`
+}
+
+// Rollup document structure for each doc type, rollup documents match the
+// raw documents to allow the chart queries to run against any tier
+var rollupSpecs = map[string]*rollupSpec{
+	"system": {
+		fields: []*rollupField{
+			{"pc", "$avg", true},
+			{"cu", "$avg", false},
+			{"mu", "$avg", false},
+			{"hu", "$avg", false},
+			{"su", "$avg", false},
+		},
+	},
+	"load": {
+		fields: []*rollupField{
+			{"lx", "$avg", false},
+			{"ly", "$avg", false},
+			{"lz", "$avg", false},
+		},
+	},
+	"disk": {
+		array: "m",
+		key:   "p",
+		fields: []*rollupField{
+			{"u", "$avg", false},
+		},
+	},
+	"diskio": {
+		array: "d",
+		key:   "n",
+		fields: []*rollupField{
+			{"br", "$sum", false},
+			{"bw", "$sum", false},
+			{"cr", "$sum", false},
+			{"cw", "$sum", false},
+			{"tr", "$sum", false},
+			{"tw", "$sum", false},
+			{"ti", "$sum", false},
+		},
+	},
+	"network": {
+		array: "i",
+		key:   "n",
+		fields: []*rollupField{
+			{"bs", "$sum", false},
+			{"br", "$sum", false},
+			{"ps", "$sum", false},
+			{"pr", "$sum", false},
+			{"es", "$sum", false},
+			{"er", "$sum", false},
+			{"ds", "$sum", false},
+			{"dr", "$sum", false},
+			{"fs", "$sum", false},
+			{"fr", "$sum", false},
+		},
+	},
+	"check": {
+		check: true,
+		fields: []*rollupField{
+			{"u", "$min", false},
+			{"d", "$max", false},
+			{"p", "$avg", true},
+		},
+	},
+}
+
+// Get valid tiers sorted by interval, each tier interval must be a
+// multiple of the previous tier to allow rollups to cascade
+func FilterTiers(tiers []*settings.RetentionTier) (
+	filtered []*settings.RetentionTier) {
+
+	filtered = []*settings.RetentionTier{}
+
+	sorted := make([]*settings.RetentionTier, 0, len(tiers))
+	for _, tier := range tiers {
+		if tier != nil && tierIntervals.Contains(tier.Interval) {
+			sorted = append(sorted, tier)
+		}
+	}
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Interval < sorted[j].Interval
+	})
+
+	prev := 1
+	for _, tier := range sorted {
+		if tier.Interval == prev || tier.Interval%prev != 0 {
+			continue
+		}
+
+		retention := tier.Retention
+		if retention < 0 {
+			retention = 0
+		}
+
+		filtered = append(filtered, &settings.RetentionTier{
+			Interval:  tier.Interval,
+			Retention: retention,
+		})
+		prev = tier.Interval
+	}
+
+	return
+}
+
+func generateCheckId(checkId, endpointId primitive.ObjectID,
+	timestamp time.Time) primitive.ObjectID {
+
+	var b [12]byte
+
+	hash := fnv.New64a()
+	hash.Write(checkId[:])
+	hash.Write(endpointId[:])
+	sum := hash.Sum(nil)
+
+	binary.BigEndian.PutUint32(b[0:4], uint32(timestamp.Unix()))
+	copy(b[4:12], sum[:])
+
+	return b
+}
+
+func bucketTime(interval time.Duration) *bson.M {
+	return &bson.M{
+		"$let": &bson.M{
+			"vars": &bson.M{
+				"t": &bson.D{{"$toLong", "$t"}},
+			},
+			"in": &bson.M{
+				"$subtract": &bson.A{
+					"$$t",
+					&bson.M{
+						"$mod": &bson.A{
+							"$$t",
+							interval.Milliseconds(),
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func rollupPipeline(spec *rollupSpec, start, end time.Time,
+	interval time.Duration) (pipeline []*bson.M) {
+
+	pipeline = []*bson.M{
+		&bson.M{
+			"$match": &bson.M{
+				"t": &bson.M{
+					"$gte": start,
+					"$lt":  end,
+				},
+			},
+		},
+	}
+
+	prefix := "$"
+	groupId := bson.D{
+		{"e", "$e"},
+	}
+	if spec.check {
+		groupId = append(groupId, bson.E{"c", "$c"})
+	}
+	groupId = append(groupId, bson.E{"t", bucketTime(interval)})
+
+	if spec.array != "" {
+		prefix = "$" + spec.array + "."
+		groupId = append(groupId, bson.E{spec.key, prefix + spec.key})
+		pipeline = append(pipeline, &bson.M{
+			"$unwind": "$" + spec.array,
+		})
+	}
+
+	group := bson.M{
+		"_id": &groupId,
+	}
+	for _, field := range spec.fields {
+		group[field.name] = &bson.D{
+			{field.op, prefix + field.name},
+		}
+	}
+	pipeline = append(pipeline, &bson.M{
+		"$group": &group,
+	})
+
+	project := bson.M{
+		"_id": 0,
+		"e":   "$_id.e",
+		"t": &bson.M{
+			"$toDate": "$_id.t",
+		},
+	}
+	if spec.check {
+		project["c"] = "$_id.c"
+	}
+
+	if spec.array != "" {
+		item := bson.M{
+			spec.key: "$_id." + spec.key,
+		}
+		for _, field := range spec.fields {
+			item[field.name] = "$" + field.name
+		}
+
+		pipeline = append(pipeline, &bson.M{
+			"$group": &bson.M{
+				"_id": &bson.D{
+					{"e", "$_id.e"},
+					{"t", "$_id.t"},
+				},
+				spec.array: &bson.M{
+					"$push": &item,
+				},
+			},
+		})
+		project[spec.array] = "$" + spec.array
+	} else {
+		for _, field := range spec.fields {
+			if field.integer {
+				project[field.name] = &bson.M{
+					"$toLong": "$" + field.name,
+				}
+			} else {
+				project[field.name] = "$" + field.name
+			}
+		}
+	}
+
+	pipeline = append(pipeline, &bson.M{
+		"$project": &project,
+	})
+
+	return
+}
+
+func ensureRollupIndex(coll *database.Collection, spec *rollupSpec) (
+	err error) {
+
+	name := coll.Name()
+
+	rollupLock.Lock()
+	exists := rollupIndexes.Contains(name)
+	rollupLock.Unlock()
+
+	if exists {
+		return
+	}
+
+	key := "e"
+	if spec.check {
+		key = "c"
+	}
+
+	index := &database.Index{
+		Collection: coll,
+		Keys: &bson.D{
+			{"t", 1},
+			{key, 1},
+		},
+	}
+	err = index.Create()
+	if err != nil {
+		return
+	}
+
+	rollupLock.Lock()
+	rollupIndexes.Add(name)
+	rollupLock.Unlock()
+
+	return
+}
+
+func rollup(db *database.Database, spec *rollupSpec,
+	src, dst *database.Collection, start, end time.Time,
+	interval time.Duration) (err error) {
+
+	cursor, err := src.Aggregate(db, rollupPipeline(
+		spec, start, end, interval))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		key := &rollupKey{}
+		err = cursor.Decode(key)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		doc := bson.M{}
+		err = cursor.Decode(&doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		var docId primitive.ObjectID
+		if spec.check {
+			docId = generateCheckId(key.Check, key.Endpoint, key.Timestamp)
+		} else {
+			docId = GenerateId(key.Endpoint, key.Timestamp)
+		}
+		doc["_id"] = docId
+
+		_, err = dst.ReplaceOne(db, &bson.M{
+			"_id": docId,
+		}, doc, options.Replace().SetUpsert(true))
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func purge(db *database.Database, coll *database.Collection,
+	before time.Time) (err error) {
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"t": &bson.M{
+			"$lt": before,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Rollup high-water mark, all buckets of the tier before the mark have
+// been computed from complete source data
+type rollupMark struct {
+	Id   string    `bson:"_id"`
+	Mark time.Time `bson:"m"`
+}
+
+func markId(typ string, interval int) string {
+	return fmt.Sprintf("%s_%dm", typ, interval)
+}
+
+func getMark(db *database.Database, typ string, interval int) (
+	mark time.Time, err error) {
+
+	coll := db.EndpointsRollupMarks()
+	doc := &rollupMark{}
+
+	err = coll.FindOneId(markId(typ, interval), doc)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	mark = doc.Mark
+	return
+}
+
+func setMark(db *database.Database, typ string, interval int,
+	mark time.Time) (err error) {
+
+	coll := db.EndpointsRollupMarks()
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": markId(typ, interval),
+	}, &bson.M{
+		"$set": &bson.M{
+			"m": mark,
+		},
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func getEarliest(db *database.Database, coll *database.Collection) (
+	timestamp time.Time, err error) {
+
+	doc := &rollupKey{}
+
+	err = coll.FindOne(db, &bson.M{}, &options.FindOneOptions{
+		Sort: &bson.D{
+			{"t", 1},
+		},
+		Projection: &bson.D{
+			{"t", 1},
+		},
+	}).Decode(doc)
+	if err != nil {
+		err = database.ParseError(err)
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+		}
+		return
+	}
+
+	timestamp = doc.Timestamp
+	return
+}
+
+// Compute tier buckets from the mark up to the end of the source data,
+// the bucket before the mark is recomputed to include late samples.
+// Backfill is limited on each run and continued on the next run.
+func rollupTier(db *database.Database, spec *rollupSpec, typ string,
+	src, dst *database.Collection, tier *settings.RetentionTier,
+	srcMark time.Time, srcCurrent bool, now time.Time) (
+	mark time.Time, err error) {
+
+	interval := time.Duration(tier.Interval) * time.Minute
+
+	mark, err = getMark(db, typ, tier.Interval)
+	if err != nil {
+		return
+	}
+
+	var start time.Time
+	if mark.IsZero() {
+		start, err = getEarliest(db, src)
+		if err != nil {
+			return
+		}
+
+		if start.IsZero() {
+			start = srcMark
+		}
+		start = start.Truncate(interval)
+	} else {
+		start = mark.Add(-interval)
+	}
+
+	end := srcMark
+	if srcCurrent {
+		end = now
+	}
+	if end.Sub(start) > rollupBackfill {
+		end = start.Add(rollupBackfill)
+	}
+
+	for winStart := start; winStart.Before(end); {
+		winEnd := winStart.Add(rollupWindow)
+		if winEnd.After(end) {
+			winEnd = end
+		}
+
+		err = rollup(db, spec, src, dst, winStart, winEnd, interval)
+		if err != nil {
+			return
+		}
+
+		winStart = winEnd
+	}
+
+	if end.After(srcMark) {
+		end = srcMark
+	}
+	end = end.Truncate(interval)
+
+	if end.After(mark) {
+		mark = end
+		err = setMark(db, typ, tier.Interval, mark)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// Compute rollups of each tier up to the latest source data and remove
+// documents past the retention. Each tier is computed from the previous
+// tier and documents are only removed once covered by the next tier.
+func Rollup(db *database.Database, now time.Time) (err error) {
+	tiers := FilterTiers(settings.Endpoint.Tiers)
+	rawRetention := time.Duration(
+		settings.Endpoint.RawRetention) * time.Hour
+
+	for _, typ := range rollupTypes {
+		spec := rollupSpecs[typ]
+		raw := GetObj(typ).GetCollection(db)
+		src := raw
+		srcMark := now
+		srcCurrent := true
+		marks := make([]time.Time, len(tiers))
+
+		for i, tier := range tiers {
+			dst := db.EndpointsRollup(typ, tier.Interval)
+
+			err = ensureRollupIndex(dst, spec)
+			if err != nil {
+				return
+			}
+
+			marks[i], err = rollupTier(db, spec, typ, src, dst, tier,
+				srcMark, srcCurrent, now)
+			if err != nil {
+				return
+			}
+
+			srcCurrent = srcCurrent && marks[i].Equal(now.Truncate(
+				time.Duration(tier.Interval)*time.Minute))
+			srcMark = marks[i]
+			src = dst
+		}
+
+		if rawRetention > 0 {
+			before := now.Add(-rawRetention)
+			if len(tiers) > 0 && marks[0].Before(before) {
+				before = marks[0]
+			}
+
+			err = purge(db, raw, before)
+			if err != nil {
+				return
+			}
+		}
+
+		for i, tier := range tiers {
+			if tier.Retention == 0 {
+				continue
+			}
+
+			before := now.Add(-time.Duration(tier.Retention) * time.Hour)
+			if i+1 < len(tiers) && marks[i+1].Before(before) {
+				before = marks[i+1]
+			}
+
+			err = purge(db, db.EndpointsRollup(typ, tier.Interval), before)
+			if err != nil {
+				return
+			}
+		}
+	}
+
+	return
+}
+
+// Get collection and bucket interval for report queries starting at the
+// start time. Raw documents are used with a zero interval when the start
+// is within the raw retention, otherwise the finest tier retaining the
+// start is used.
+func GetReportCollection(db *database.Database, typ string,
+	start time.Time) (coll *database.Collection, interval time.Duration) {
+
+	coll = GetObj(typ).GetCollection(db)
+	now := time.Now()
+
+	rawRetention := settings.Endpoint.RawRetention
+	if rawRetention == 0 || !start.Before(
+		now.Add(-time.Duration(rawRetention)*time.Hour)) {
+
+		return
+	}
+
+	var fallback *settings.RetentionTier
+	for _, tier := range FilterTiers(settings.Endpoint.Tiers) {
+		if tier.Retention == 0 || !start.Before(
+			now.Add(-time.Duration(tier.Retention)*time.Hour)) {
+
+			coll = db.EndpointsRollup(typ, tier.Interval)
+			interval = time.Duration(tier.Interval) * time.Minute
+			return
+		}
+
+		if fallback == nil || tier.Retention > fallback.Retention {
+			fallback = tier
+		}
+	}
+
+	if fallback != nil {
+		coll = db.EndpointsRollup(typ, fallback.Interval)
+		interval = time.Duration(fallback.Interval) * time.Minute
+	}
+
+	return
+}
+
+// Get collection for chart query. Raw documents are used when the range
+// is within the raw retention, otherwise the finest tier with an interval
+// dividing the chart interval that retains the range is used.
+func getChartCollection(db *database.Database, typ string,
+	start time.Time, interval time.Duration) *database.Collection {
+
+	raw := GetObj(typ).GetCollection(db)
+	now := time.Now()
+
+	rawRetention := settings.Endpoint.RawRetention
+	if rawRetention == 0 || !start.Before(
+		now.Add(-time.Duration(rawRetention)*time.Hour)) {
+
+		return raw
+	}
+
+	var fallback *settings.RetentionTier
+	for _, tier := range FilterTiers(settings.Endpoint.Tiers) {
+		tierInterval := time.Duration(tier.Interval) * time.Minute
+		if interval < tierInterval || interval%tierInterval != 0 {
+			continue
+		}
+
+		if tier.Retention == 0 || !start.Before(
+			now.Add(-time.Duration(tier.Retention)*time.Hour)) {
+
+			return db.EndpointsRollup(typ, tier.Interval)
+		}
+
+		if fallback == nil || tier.Retention > fallback.Retention {
+			fallback = tier
+		}
+	}
+
+	if fallback != nil {
+		return db.EndpointsRollup(typ, fallback.Interval)
+	}
+
+	return raw
+}
@@ -12,6 +12,10 @@ import (
 	"github.com/pritunl/pritunl-zero/database"
 )
 
+// Maximum age of a doc without a send time for clock drift evaluation,
+// older docs were delayed and the age does not reflect the agent clock
+const driftDelayMax = time.Minute
+
 type System struct {
 	Id        primitive.ObjectID `bson:"_id" json:"id"`
 	Endpoint  primitive.ObjectID `bson:"e" json:"e"`
@@ -23,6 +27,7 @@ type System struct {
 	Virtualization string           `bson:"-" json:"v"`
 	Platform       string           `bson:"-" json:"p"`
 	PackageUpdates int              `bson:"-" json:"pu"`
+	RebootRequired bool             `bson:"-" json:"rr"`
 	Processes      uint64           `bson:"pc" json:"pc"`
 	CpuCores       int              `bson:"-" json:"cc"`
 	CpuUsage       float64          `bson:"cu" json:"cu"`
@@ -33,6 +38,10 @@ type System struct {
 	SwapTotal      int              `bson:"-" json:"st"`
 	SwapUsage      float64          `bson:"su" json:"su"`
 	MdStat         []*SystemMdState `bson:"-" json:"ra"`
+	Sent           time.Time        `bson:"-" json:"ts"`
+
+	drift      time.Duration
+	driftValid bool
 }
 
 type SystemMdState struct {
@@ -57,6 +66,14 @@ func (d *System) GetCollection(db *database.Database) *database.Collection {
 }
 
 func (d *System) Format(id primitive.ObjectID) time.Time {
+	if !d.Sent.IsZero() {
+		d.drift = time.Since(d.Sent)
+		d.driftValid = true
+	} else if !d.Timestamp.IsZero() {
+		d.drift = time.Since(d.Timestamp)
+		d.driftValid = d.drift < driftDelayMax
+	}
+
 	d.Endpoint = id
 	d.Timestamp = d.Timestamp.UTC().Truncate(1 * time.Minute)
 	d.Id = GenerateId(id, d.Timestamp)
@@ -71,11 +88,13 @@ func (d *System) StaticData() *bson.M {
 		"data.virtualization":  d.Virtualization,
 		"data.platform":        d.Platform,
 		"data.package_updates": d.PackageUpdates,
+		"data.reboot_required": d.RebootRequired,
 		"data.cpu_cores":       d.CpuCores,
 		"data.mem_total":       d.MemTotal,
 		"data.swap_total":      d.SwapTotal,
 		"data.huge_total":      d.HugeTotal,
 		"data.md_stat":         d.MdStat,
+		"data.timestamp":       d.Timestamp,
 	}
 }
 
@@ -83,58 +102,91 @@ func (d *System) CheckAlerts(resources []*alert.Alert) (alerts []*Alert) {
 	alerts = []*Alert{}
 
 	for _, resource := range resources {
+		if resource.HasCondition() {
+			continue
+		}
+
 		switch resource.Resource {
 		case alert.SystemCpuLevel:
 			if d.CpuUsage > float64(resource.ValueInt) {
-				alerts = []*Alert{
-					NewAlert(resource, fmt.Sprintf(
-						"System cpu high usage (%.2f%%)",
-						d.MemUsage,
-					)),
-				}
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System cpu high usage (%.2f%%)",
+					d.CpuUsage,
+				)))
 			}
 			break
 		case alert.SystemMemoryLevel:
 			if d.MemUsage > float64(resource.ValueInt) {
-				alerts = []*Alert{
-					NewAlert(resource, fmt.Sprintf(
-						"System low on memory (%.2f%%)",
-						d.MemUsage,
-					)),
-				}
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System low on memory (%.2f%%)",
+					d.MemUsage,
+				)))
 			}
 			break
 		case alert.SystemSwapLevel:
 			if d.SwapUsage > float64(resource.ValueInt) {
-				alerts = []*Alert{
-					NewAlert(resource, fmt.Sprintf(
-						"System low on swap (%.2f%%)",
-						d.SwapUsage,
-					)),
-				}
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System low on swap (%.2f%%)",
+					d.SwapUsage,
+				)))
 			}
 			break
 		case alert.SystemHugePagesLevel:
-			if d.SwapUsage > float64(resource.ValueInt) {
-				alerts = []*Alert{
-					NewAlert(resource, fmt.Sprintf(
-						"System low on hugepages (%.2f%%)",
-						d.SwapUsage,
-					)),
-				}
+			if d.HugeUsage > float64(resource.ValueInt) {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System low on hugepages (%.2f%%)",
+					d.HugeUsage,
+				)))
+			}
+			break
+		case alert.SystemPackageUpdates:
+			if d.PackageUpdates >= resource.ValueInt {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System pending package updates (%d)",
+					d.PackageUpdates,
+				)))
+			}
+			break
+		case alert.SystemRebootRequired:
+			if d.RebootRequired {
+				alerts = append(alerts, NewAlert(resource,
+					"System reboot required"))
+			}
+			break
+		case alert.SystemUptimeLevel:
+			days := d.Uptime / 86400
+			if days >= uint64(resource.ValueInt) {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System uptime high (%d days)",
+					days,
+				)))
+			}
+			break
+		case alert.SystemClockDrift:
+			if !d.driftValid {
+				break
+			}
+
+			drift := d.drift
+			if drift < 0 {
+				drift = -drift
+			}
+			if drift > time.Duration(resource.ValueInt)*time.Second {
+				alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+					"System clock drift (%.1fs)",
+					d.drift.Seconds(),
+				)))
 			}
 			break
 		case alert.SystemMdFailed:
 			if d.MdStat != nil {
 				for _, md := range d.MdStat {
 					if md.Failed > 0 {
-						alerts = []*Alert{
-							NewAlert(resource, fmt.Sprintf(
-								"System MD RAID device failed (%s %s)",
-								md.Name,
-								md.Level,
-							)),
-						}
+						alerts = append(alerts, NewAlert(resource, fmt.Sprintf(
+							"System MD RAID device failed (%s %s)",
+							md.Name,
+							md.Level,
+						)))
 					}
 				}
 			}
@@ -219,7 +271,7 @@ func GetSystemChart(c context.Context, db *database.Database,
 		return
 	}
 
-	coll := db.EndpointsSystem()
+	coll := getChartCollection(db, "system", start, interval)
 	chart := NewChart(start, end, interval)
 
 	timeQuery := bson.D{
@@ -0,0 +1,22 @@
+package expression
+
+const (
+	String = "string"
+	Number = "number"
+	Bool   = "bool"
+	List   = "list"
+)
+
+const (
+	tokenEof    = "eof"
+	tokenIdent  = "ident"
+	tokenString = "string"
+	tokenNumber = "number"
+	tokenOp     = "op"
+)
+
+var keywords = map[string]bool{
+	"true":  true,
+	"false": true,
+	"in":    true,
+}
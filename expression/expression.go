@@ -0,0 +1,75 @@
+package expression
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Expression struct {
+	Source string
+	root   node
+}
+
+// Evaluate expression, variables must match the compile schema
+func (e *Expression) Eval(vars map[string]interface{}) (
+	result bool, err error) {
+
+	val, err := e.root.eval(vars)
+	if err != nil {
+		return
+	}
+
+	result, ok := val.(bool)
+	if !ok {
+		err = &errortypes.UnknownError{
+			errors.New("expression: Result is not bool"),
+		}
+		return
+	}
+
+	return
+}
+
+// Parse and type check expression against schema of variable types
+func Compile(src string, schema map[string]string) (
+	expr *Expression, err error) {
+
+	tokens, err := lex(src)
+	if err != nil {
+		return
+	}
+
+	p := &parser{
+		tokens: tokens,
+	}
+
+	root, err := p.parseOr()
+	if err != nil {
+		return
+	}
+
+	tok := p.peek(0)
+	if tok.kind != tokenEof {
+		err = p.unexpected(tok)
+		return
+	}
+
+	typ, err := root.check(schema)
+	if err != nil {
+		return
+	}
+
+	if typ != Bool {
+		err = &errortypes.ParseError{
+			errors.Newf("expression: Result must be bool not %s", typ),
+		}
+		return
+	}
+
+	expr = &Expression{
+		Source: src,
+		root:   root,
+	}
+
+	return
+}
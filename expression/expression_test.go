@@ -0,0 +1,167 @@
+package expression
+
+import (
+	"testing"
+)
+
+var testSchema = map[string]string{
+	"user.username": String,
+	"user.roles":    List,
+	"user.admin":    Bool,
+	"agent.ip":      String,
+	"time.hour":     Number,
+	"missing":       Bool,
+}
+
+var testVars = map[string]interface{}{
+	"user.username": "alice",
+	"user.roles":    []interface{}{"admin", "ops"},
+	"user.admin":    true,
+	"agent.ip":      "10.0.1.5",
+	"time.hour":     float64(14),
+}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		src    string
+		result bool
+	}{
+		// Precedence
+		{"true || false && false", true},
+		{"(true || false) && false", false},
+		{"false && false || true", true},
+		{"!false && true", true},
+		{"!(true && false)", true},
+		{"!true || true", true},
+		{"1 < 2 && 2 < 3", true},
+		{"(1 < 2) == true", true},
+		{"!(1 < 2) == false", true},
+		{"-1 < 0", true},
+		{"--1 == 1", true},
+		{"time.hour >= 9 && time.hour < 17 || user.admin", true},
+
+		// Comparisons
+		{"user.username == 'alice'", true},
+		{"user.username != \"alice\"", false},
+		{"'abc' < 'abd'", true},
+		{"time.hour == 14", true},
+		{"time.hour > 14.5", false},
+		{"'ops' in user.roles", true},
+		{"'dev' in user.roles", false},
+		{"time.hour in [9, 14, 17]", true},
+		{"'a\\'b' == \"a'b\"", true},
+
+		// Functions
+		{"user.username.startsWith('al')", true},
+		{"user.username.endsWith('ce')", true},
+		{"user.username.contains('lic')", true},
+		{"user.username.matches('^a.*e$')", true},
+		{"user.username.upper() == 'ALICE'", true},
+		{"'ALICE'.lower() == user.username", true},
+		{"size(user.roles) == 2", true},
+		{"size(user.username) == 5", true},
+		{"size([]) == 0", true},
+		{"cidr(agent.ip, '10.0.0.0/16')", true},
+		{"cidr(agent.ip, '10.1.0.0/16')", false},
+		{"cidr('invalid', '10.0.0.0/8')", false},
+
+		// Short circuit skips evaluation of missing variables
+		{"false && missing", false},
+		{"true || missing", true},
+		{"user.admin || missing", true},
+		{"!user.admin && missing", false},
+	}
+
+	for _, test := range tests {
+		expr, err := Compile(test.src, testSchema)
+		if err != nil {
+			t.Errorf("compile %q: %s", test.src, err)
+			continue
+		}
+
+		result, err := expr.Eval(testVars)
+		if err != nil {
+			t.Errorf("eval %q: %s", test.src, err)
+			continue
+		}
+
+		if result != test.result {
+			t.Errorf("eval %q: got %t want %t",
+				test.src, result, test.result)
+		}
+	}
+}
+
+func TestEvalMissing(t *testing.T) {
+	tests := []string{
+		"true && missing",
+		"false || missing",
+		"missing == true",
+	}
+
+	for _, src := range tests {
+		expr, err := Compile(src, testSchema)
+		if err != nil {
+			t.Errorf("compile %q: %s", src, err)
+			continue
+		}
+
+		_, err = expr.Eval(testVars)
+		if err == nil {
+			t.Errorf("eval %q: expected missing variable error", src)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []string{
+		// Syntax
+		"",
+		"true &&",
+		"(true",
+		"true)",
+		"'unterminated",
+		"user.username == #",
+		"[1, 2",
+		"size(user.roles",
+		"in",
+		"1 < 2 < 3",
+
+		// Types
+		"1",
+		"'alice'",
+		"user.username",
+		"user.unknown == 'x'",
+		"true && 1",
+		"1 || false",
+		"!1",
+		"-'a'",
+		"1 == 'a'",
+		"user.roles == user.roles",
+		"true < false",
+		"'a' in 'abc'",
+		"user.roles in user.roles",
+		"[1, 'a'] == [1]",
+		"'a' in [true]",
+		"[[1]] == [1]",
+		"user.admin.startsWith('t')",
+		"time.hour.lower() == 'a'",
+		"user.username.startsWith(1)",
+		"user.username.startsWith()",
+		"size(1) == 1",
+		"size() == 0",
+		"unknown(1)",
+		"user.username.unknown()",
+		"cidr(agent.ip)",
+		"cidr(agent.ip, 'invalid')",
+		"user.username.matches('(')",
+		"size(user.roles)",
+	}
+
+	for _, src := range tests {
+		_, err := Compile(src, testSchema)
+		if err == nil {
+			t.Errorf("compile %q: expected error", src)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package expression
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type token struct {
+	kind  string
+	value string
+	pos   int
+}
+
+var operators = []string{
+	"&&", "||", "==", "!=", "<=", ">=",
+	"<", ">", "!", "-", "(", ")", "[", "]", ",", ".",
+}
+
+func lex(src string) (tokens []*token, err error) {
+	tokens = []*token{}
+	runes := []rune(src)
+	i := 0
+
+	for i < len(runes) {
+		ch := runes[i]
+
+		if unicode.IsSpace(ch) {
+			i += 1
+			continue
+		}
+
+		if ch == '"' || ch == '\'' {
+			start := i
+			quote := ch
+			value := strings.Builder{}
+			i += 1
+
+			closed := false
+			for i < len(runes) {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					value.WriteRune(runes[i+1])
+					i += 2
+					continue
+				}
+				if runes[i] == quote {
+					closed = true
+					i += 1
+					break
+				}
+				value.WriteRune(runes[i])
+				i += 1
+			}
+
+			if !closed {
+				err = &errortypes.ParseError{
+					errors.Newf("expression: Unterminated string at %d",
+						start),
+				}
+				return
+			}
+
+			tokens = append(tokens, &token{
+				kind:  tokenString,
+				value: value.String(),
+				pos:   start,
+			})
+			continue
+		}
+
+		if unicode.IsDigit(ch) {
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) ||
+				runes[i] == '.') {
+
+				i += 1
+			}
+
+			tokens = append(tokens, &token{
+				kind:  tokenNumber,
+				value: string(runes[start:i]),
+				pos:   start,
+			})
+			continue
+		}
+
+		if unicode.IsLetter(ch) || ch == '_' {
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) ||
+				unicode.IsDigit(runes[i]) || runes[i] == '_') {
+
+				i += 1
+			}
+
+			tokens = append(tokens, &token{
+				kind:  tokenIdent,
+				value: string(runes[start:i]),
+				pos:   start,
+			})
+			continue
+		}
+
+		matched := false
+		for _, op := range operators {
+			if strings.HasPrefix(string(runes[i:]), op) {
+				tokens = append(tokens, &token{
+					kind:  tokenOp,
+					value: op,
+					pos:   i,
+				})
+				i += len([]rune(op))
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			err = &errortypes.ParseError{
+				errors.Newf("expression: Unexpected character '%c' at %d",
+					ch, i),
+			}
+			return
+		}
+	}
+
+	tokens = append(tokens, &token{
+		kind: tokenEof,
+		pos:  len(runes),
+	})
+
+	return
+}
@@ -0,0 +1,463 @@
+package expression
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type node interface {
+	check(schema map[string]string) (typ string, err error)
+	eval(vars map[string]interface{}) (val interface{}, err error)
+}
+
+func typeError(msg string, args ...interface{}) error {
+	return &errortypes.ParseError{
+		errors.Newf("expression: "+msg, args...),
+	}
+}
+
+type literal struct {
+	typ   string
+	value interface{}
+}
+
+func (n *literal) check(schema map[string]string) (typ string, err error) {
+	typ = n.typ
+	return
+}
+
+func (n *literal) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	val = n.value
+	return
+}
+
+type variable struct {
+	name string
+}
+
+func (n *variable) check(schema map[string]string) (typ string, err error) {
+	typ = schema[n.name]
+	if typ == "" {
+		err = typeError("Unknown variable '%s'", n.name)
+		return
+	}
+	return
+}
+
+func (n *variable) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	val, ok := vars[n.name]
+	if !ok {
+		err = &errortypes.UnknownError{
+			errors.Newf("expression: Missing variable '%s'", n.name),
+		}
+		return
+	}
+	return
+}
+
+type list struct {
+	items []node
+}
+
+func (n *list) check(schema map[string]string) (typ string, err error) {
+	itemTyp := ""
+	for _, item := range n.items {
+		t, e := item.check(schema)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if t == List || t == Bool {
+			err = typeError("List items must be strings or numbers")
+			return
+		}
+
+		if itemTyp != "" && t != itemTyp {
+			err = typeError("List items must be the same type")
+			return
+		}
+		itemTyp = t
+	}
+
+	typ = List
+	return
+}
+
+func (n *list) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	items := []interface{}{}
+	for _, item := range n.items {
+		v, e := item.eval(vars)
+		if e != nil {
+			err = e
+			return
+		}
+		items = append(items, v)
+	}
+
+	val = items
+	return
+}
+
+type unary struct {
+	op      string
+	operand node
+}
+
+func (n *unary) check(schema map[string]string) (typ string, err error) {
+	t, err := n.operand.check(schema)
+	if err != nil {
+		return
+	}
+
+	if n.op == "!" {
+		if t != Bool {
+			err = typeError("Operator '!' requires bool")
+			return
+		}
+		typ = Bool
+	} else {
+		if t != Number {
+			err = typeError("Operator '-' requires number")
+			return
+		}
+		typ = Number
+	}
+
+	return
+}
+
+func (n *unary) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	v, err := n.operand.eval(vars)
+	if err != nil {
+		return
+	}
+
+	if n.op == "!" {
+		val = !v.(bool)
+	} else {
+		val = -v.(float64)
+	}
+
+	return
+}
+
+type binary struct {
+	op    string
+	left  node
+	right node
+}
+
+func (n *binary) check(schema map[string]string) (typ string, err error) {
+	left, err := n.left.check(schema)
+	if err != nil {
+		return
+	}
+
+	right, err := n.right.check(schema)
+	if err != nil {
+		return
+	}
+
+	typ = Bool
+
+	switch n.op {
+	case "&&", "||":
+		if left != Bool || right != Bool {
+			err = typeError("Operator '%s' requires bool operands", n.op)
+			return
+		}
+		break
+	case "==", "!=":
+		if left != right || left == List {
+			err = typeError("Operator '%s' cannot compare %s and %s",
+				n.op, left, right)
+			return
+		}
+		break
+	case "<", "<=", ">", ">=":
+		if left != right || (left != Number && left != String) {
+			err = typeError("Operator '%s' cannot compare %s and %s",
+				n.op, left, right)
+			return
+		}
+		break
+	case "in":
+		if right != List || left == List || left == Bool {
+			err = typeError("Operator 'in' requires value in list")
+			return
+		}
+		break
+	}
+
+	return
+}
+
+func (n *binary) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	left, err := n.left.eval(vars)
+	if err != nil {
+		return
+	}
+
+	switch n.op {
+	case "&&":
+		if !left.(bool) {
+			val = false
+			return
+		}
+		val, err = n.right.eval(vars)
+		return
+	case "||":
+		if left.(bool) {
+			val = true
+			return
+		}
+		val, err = n.right.eval(vars)
+		return
+	}
+
+	right, err := n.right.eval(vars)
+	if err != nil {
+		return
+	}
+
+	switch n.op {
+	case "==":
+		val = left == right
+		break
+	case "!=":
+		val = left != right
+		break
+	case "<", "<=", ">", ">=":
+		cmp := 0
+		if l, ok := left.(float64); ok {
+			r := right.(float64)
+			if l < r {
+				cmp = -1
+			} else if l > r {
+				cmp = 1
+			}
+		} else {
+			cmp = strings.Compare(left.(string), right.(string))
+		}
+
+		switch n.op {
+		case "<":
+			val = cmp < 0
+		case "<=":
+			val = cmp <= 0
+		case ">":
+			val = cmp > 0
+		case ">=":
+			val = cmp >= 0
+		}
+		break
+	case "in":
+		found := false
+		for _, item := range right.([]interface{}) {
+			if item == left {
+				found = true
+				break
+			}
+		}
+		val = found
+		break
+	}
+
+	return
+}
+
+type call struct {
+	name     string
+	receiver node
+	args     []node
+	regex    *regexp.Regexp
+}
+
+func (n *call) signature() (receiver string, args []string, result string,
+	ok bool) {
+
+	ok = true
+
+	if n.receiver == nil {
+		switch n.name {
+		case "size":
+			args = []string{""}
+			result = Number
+		case "cidr":
+			args = []string{String, String}
+			result = Bool
+		default:
+			ok = false
+		}
+		return
+	}
+
+	switch n.name {
+	case "startsWith", "endsWith", "contains", "matches":
+		receiver = String
+		args = []string{String}
+		result = Bool
+	case "lower", "upper":
+		receiver = String
+		args = []string{}
+		result = String
+	default:
+		ok = false
+	}
+
+	return
+}
+
+func (n *call) check(schema map[string]string) (typ string, err error) {
+	receiver, args, result, ok := n.signature()
+	if !ok {
+		err = typeError("Unknown function '%s'", n.name)
+		return
+	}
+
+	if n.receiver != nil {
+		t, e := n.receiver.check(schema)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if t != receiver {
+			err = typeError("Function '%s' requires %s receiver",
+				n.name, receiver)
+			return
+		}
+	}
+
+	if len(n.args) != len(args) {
+		err = typeError("Function '%s' requires %d arguments",
+			n.name, len(args))
+		return
+	}
+
+	for i, arg := range n.args {
+		t, e := arg.check(schema)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if args[i] == "" {
+			if t != List && t != String {
+				err = typeError("Function '%s' requires list or string",
+					n.name)
+				return
+			}
+		} else if t != args[i] {
+			err = typeError("Function '%s' requires %s argument",
+				n.name, args[i])
+			return
+		}
+	}
+
+	if n.name == "matches" {
+		if lit, ok := n.args[0].(*literal); ok {
+			n.regex, err = regexp.Compile(lit.value.(string))
+			if err != nil {
+				err = typeError("Invalid regular expression '%s'",
+					lit.value)
+				return
+			}
+		}
+	}
+
+	if n.name == "cidr" {
+		if lit, ok := n.args[1].(*literal); ok {
+			_, _, err = net.ParseCIDR(lit.value.(string))
+			if err != nil {
+				err = typeError("Invalid network '%s'", lit.value)
+				return
+			}
+		}
+	}
+
+	typ = result
+	return
+}
+
+func (n *call) eval(vars map[string]interface{}) (
+	val interface{}, err error) {
+
+	var recv interface{}
+	if n.receiver != nil {
+		recv, err = n.receiver.eval(vars)
+		if err != nil {
+			return
+		}
+	}
+
+	args := []interface{}{}
+	for _, arg := range n.args {
+		v, e := arg.eval(vars)
+		if e != nil {
+			err = e
+			return
+		}
+		args = append(args, v)
+	}
+
+	switch n.name {
+	case "size":
+		if s, ok := args[0].(string); ok {
+			val = float64(len(s))
+		} else {
+			val = float64(len(args[0].([]interface{})))
+		}
+		break
+	case "cidr":
+		ip := net.ParseIP(args[0].(string))
+		_, network, e := net.ParseCIDR(args[1].(string))
+		val = ip != nil && e == nil && network.Contains(ip)
+		break
+	case "startsWith":
+		val = strings.HasPrefix(recv.(string), args[0].(string))
+		break
+	case "endsWith":
+		val = strings.HasSuffix(recv.(string), args[0].(string))
+		break
+	case "contains":
+		val = strings.Contains(recv.(string), args[0].(string))
+		break
+	case "matches":
+		regex := n.regex
+		if regex == nil {
+			regex, err = regexp.Compile(args[0].(string))
+			if err != nil {
+				err = &errortypes.ParseError{
+					errors.Wrap(err, "expression: Invalid regex"),
+				}
+				return
+			}
+		}
+		val = regex.MatchString(recv.(string))
+		break
+	case "lower":
+		val = strings.ToLower(recv.(string))
+		break
+	case "upper":
+		val = strings.ToUpper(recv.(string))
+		break
+	}
+
+	return
+}
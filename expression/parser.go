@@ -0,0 +1,340 @@
+package expression
+
+import (
+	"strconv"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type parser struct {
+	tokens []*token
+	pos    int
+}
+
+func (p *parser) peek(offset int) *token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) next() *token {
+	tok := p.peek(0)
+	if tok.kind != tokenEof {
+		p.pos += 1
+	}
+	return tok
+}
+
+func (p *parser) isOp(value string) bool {
+	tok := p.peek(0)
+	return tok.kind == tokenOp && tok.value == value
+}
+
+func (p *parser) expect(value string) (err error) {
+	tok := p.next()
+	if tok.kind != tokenOp || tok.value != value {
+		err = p.unexpected(tok)
+		return
+	}
+	return
+}
+
+func (p *parser) unexpected(tok *token) error {
+	if tok.kind == tokenEof {
+		return &errortypes.ParseError{
+			errors.New("expression: Unexpected end of expression"),
+		}
+	}
+	return &errortypes.ParseError{
+		errors.Newf("expression: Unexpected '%s' at %d",
+			tok.value, tok.pos),
+	}
+}
+
+func (p *parser) parseOr() (n node, err error) {
+	n, err = p.parseAnd()
+	if err != nil {
+		return
+	}
+
+	for p.isOp("||") {
+		p.next()
+
+		right, e := p.parseAnd()
+		if e != nil {
+			err = e
+			return
+		}
+
+		n = &binary{
+			op:    "||",
+			left:  n,
+			right: right,
+		}
+	}
+
+	return
+}
+
+func (p *parser) parseAnd() (n node, err error) {
+	n, err = p.parseCompare()
+	if err != nil {
+		return
+	}
+
+	for p.isOp("&&") {
+		p.next()
+
+		right, e := p.parseCompare()
+		if e != nil {
+			err = e
+			return
+		}
+
+		n = &binary{
+			op:    "&&",
+			left:  n,
+			right: right,
+		}
+	}
+
+	return
+}
+
+func (p *parser) parseCompare() (n node, err error) {
+	n, err = p.parseUnary()
+	if err != nil {
+		return
+	}
+
+	tok := p.peek(0)
+	op := ""
+	if tok.kind == tokenOp {
+		switch tok.value {
+		case "==", "!=", "<", "<=", ">", ">=":
+			op = tok.value
+		}
+	} else if tok.kind == tokenIdent && tok.value == "in" {
+		op = "in"
+	}
+
+	if op == "" {
+		return
+	}
+	p.next()
+
+	right, err := p.parseUnary()
+	if err != nil {
+		return
+	}
+
+	n = &binary{
+		op:    op,
+		left:  n,
+		right: right,
+	}
+
+	return
+}
+
+func (p *parser) parseUnary() (n node, err error) {
+	if p.isOp("!") || p.isOp("-") {
+		op := p.next().value
+
+		operand, e := p.parseUnary()
+		if e != nil {
+			err = e
+			return
+		}
+
+		n = &unary{
+			op:      op,
+			operand: operand,
+		}
+		return
+	}
+
+	n, err = p.parsePostfix()
+	return
+}
+
+func (p *parser) parsePostfix() (n node, err error) {
+	n, err = p.parsePrimary()
+	if err != nil {
+		return
+	}
+
+	for p.isOp(".") {
+		p.next()
+
+		tok := p.next()
+		if tok.kind != tokenIdent {
+			err = p.unexpected(tok)
+			return
+		}
+
+		args, e := p.parseArgs()
+		if e != nil {
+			err = e
+			return
+		}
+
+		n = &call{
+			name:     tok.value,
+			receiver: n,
+			args:     args,
+		}
+	}
+
+	return
+}
+
+func (p *parser) parseArgs() (args []node, err error) {
+	args = []node{}
+
+	err = p.expect("(")
+	if err != nil {
+		return
+	}
+
+	if p.isOp(")") {
+		p.next()
+		return
+	}
+
+	for {
+		arg, e := p.parseOr()
+		if e != nil {
+			err = e
+			return
+		}
+		args = append(args, arg)
+
+		if p.isOp(",") {
+			p.next()
+			continue
+		}
+
+		err = p.expect(")")
+		return
+	}
+}
+
+func (p *parser) parsePrimary() (n node, err error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenString:
+		n = &literal{
+			typ:   String,
+			value: tok.value,
+		}
+		return
+	case tokenNumber:
+		num, e := strconv.ParseFloat(tok.value, 64)
+		if e != nil {
+			err = &errortypes.ParseError{
+				errors.Newf("expression: Invalid number '%s' at %d",
+					tok.value, tok.pos),
+			}
+			return
+		}
+
+		n = &literal{
+			typ:   Number,
+			value: num,
+		}
+		return
+	case tokenIdent:
+		if tok.value == "true" || tok.value == "false" {
+			n = &literal{
+				typ:   Bool,
+				value: tok.value == "true",
+			}
+			return
+		}
+
+		if keywords[tok.value] {
+			err = p.unexpected(tok)
+			return
+		}
+
+		if p.isOp("(") {
+			args, e := p.parseArgs()
+			if e != nil {
+				err = e
+				return
+			}
+
+			n = &call{
+				name: tok.value,
+				args: args,
+			}
+			return
+		}
+
+		// Extend variable path unless segment is a method call
+		name := tok.value
+		for p.isOp(".") && p.peek(1).kind == tokenIdent &&
+			!(p.peek(2).kind == tokenOp && p.peek(2).value == "(") {
+
+			p.next()
+			name += "." + p.next().value
+		}
+
+		n = &variable{
+			name: name,
+		}
+		return
+	case tokenOp:
+		switch tok.value {
+		case "(":
+			n, err = p.parseOr()
+			if err != nil {
+				return
+			}
+
+			err = p.expect(")")
+			return
+		case "[":
+			items := []node{}
+			if p.isOp("]") {
+				p.next()
+				n = &list{
+					items: items,
+				}
+				return
+			}
+
+			for {
+				item, e := p.parseOr()
+				if e != nil {
+					err = e
+					return
+				}
+				items = append(items, item)
+
+				if p.isOp(",") {
+					p.next()
+					continue
+				}
+
+				err = p.expect("]")
+				if err != nil {
+					return
+				}
+				break
+			}
+
+			n = &list{
+				items: items,
+			}
+			return
+		}
+	}
+
+	err = p.unexpected(tok)
+	return
+}
@@ -71,6 +71,69 @@ func EndpointRegisterPut(c *gin.Context) {
 	c.JSON(200, resData)
 }
 
+type endpointChallengeData struct {
+	Challenge string `json:"challenge"`
+}
+
+// Answer browser challenge from endpoint agent to prove the browser is
+// running on the endpoint for device posture policies
+func EndpointChallengePut(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	data := &endpointChallengeData{}
+
+	endpointId, ok := utils.ParseObjectId(c.Param("endpoint_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	timestamp := c.Request.Header.Get("Pritunl-Endpoint-Timestamp")
+	nonce := c.Request.Header.Get("Pritunl-Endpoint-Nonce")
+	sig := c.Request.Header.Get("Pritunl-Endpoint-Signature")
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	endpt, err := endpoint.Get(db, endpointId)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			utils.AbortWithError(c, 404, err)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	errData, err := endpt.ValidateSignature(
+		db, timestamp, nonce, sig, "challenge")
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+	if errData != nil {
+		c.JSON(401, errData)
+		return
+	}
+
+	errData, err = endpt.AnswerChallenge(db, data.Challenge)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	c.JSON(200, nil)
+}
+
 func EndpointCommGet(c *gin.Context) {
 	db := c.MustGet("db").(*database.Database)
 	socket := &endpoint.WebSocket{}
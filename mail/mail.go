@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/settings"
+)
+
+type Message struct {
+	To          []string
+	Subject     string
+	Body        string
+	ContentType string
+}
+
+func (m *Message) build(from string) []byte {
+	contentType := m.ContentType
+	if contentType == "" {
+		contentType = "text/plain; charset=utf-8"
+	}
+
+	buf := &bytes.Buffer{}
+	fmt.Fprintf(buf, "From: %s\r\n", from)
+	fmt.Fprintf(buf, "To: %s\r\n", strings.Join(m.To, ", "))
+	fmt.Fprintf(buf, "Subject: %s\r\n",
+		mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(buf, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	fmt.Fprintf(buf, "MIME-Version: 1.0\r\n")
+	fmt.Fprintf(buf, "Content-Type: %s\r\n", contentType)
+	fmt.Fprintf(buf, "\r\n")
+	buf.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+
+	return buf.Bytes()
+}
+
+// Send message with configured SMTP server, STARTTLS is used when offered
+func Send(msg *Message) (err error) {
+	host := settings.Smtp.Host
+	if host == "" {
+		err = &errortypes.RequestError{
+			errors.New("mail: SMTP server not configured"),
+		}
+		return
+	}
+
+	port := settings.Smtp.Port
+	if port == 0 {
+		port = 587
+	}
+
+	from := settings.Smtp.From
+	if from == "" {
+		from = settings.Smtp.Username
+	}
+
+	if len(msg.To) == 0 {
+		err = &errortypes.RequestError{
+			errors.New("mail: Message has no recipients"),
+		}
+		return
+	}
+
+	var auth smtp.Auth
+	if settings.Smtp.Username != "" {
+		auth = smtp.PlainAuth("", settings.Smtp.Username,
+			settings.Smtp.Password, host)
+	}
+
+	err = smtp.SendMail(
+		net.JoinHostPort(host, strconv.Itoa(port)),
+		auth,
+		from,
+		msg.To,
+		msg.build(from),
+	)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "mail: Failed to send message"),
+		}
+		return
+	}
+
+	return
+}
@@ -25,6 +25,8 @@ Commands:
   default-password  Get default administrator password
   reset-password    Reset administrator password
   disable-policies  Disable all policies
+  revisions         List recent policy, service and authority revisions
+  rollback-revision Restore a policy, service or authority revision
   export-ssh        Export SSH authorities for emergency client
 `
 
@@ -93,6 +95,20 @@ func main() {
 			panic(err)
 		}
 		return
+	case "revisions":
+		Init()
+		err := cmd.Revisions()
+		if err != nil {
+			panic(err)
+		}
+		return
+	case "rollback-revision":
+		Init()
+		err := cmd.RevisionRollback()
+		if err != nil {
+			panic(err)
+		}
+		return
 	case "set":
 		Init()
 		err := cmd.SettingsSet()
@@ -0,0 +1,210 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/pritunl-zero/accessrequest"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type accessDecisionData struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+}
+
+func accessRequestsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	query := bson.M{}
+
+	state := c.Query("state")
+	if state != "" {
+		query["state"] = state
+	}
+
+	userId, ok := utils.ParseObjectId(c.Query("user"))
+	if ok {
+		query["user"] = userId
+	}
+
+	reqs, err := accessrequest.GetAll(db, &query, 500)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, reqs)
+}
+
+func accessRequestPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &accessDecisionData{}
+
+	reqId, ok := utils.ParseObjectId(c.Param("request_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req, err := accessrequest.Get(db, reqId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := user.Get(db, req.User)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	ok, err = canManageUser(c, db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	approver, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	var errData *errortypes.ErrorData
+	typ := ""
+	if data.Approve {
+		typ = audit.AccessApprove
+		errData, err = req.Approve(db, approver, data.Comment)
+	} else {
+		typ = audit.AccessDeny
+		errData, err = req.Deny(db, approver, data.Comment)
+	}
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		req.User,
+		typ,
+		audit.Fields{
+			"access_request_id": req.Id.Hex(),
+			"type":              req.Type,
+			"resource":          req.ResourceName,
+			"role":              req.Role,
+			"approver_id":       approver.Id.Hex(),
+			"approver":          approver.Username,
+			"comment":           req.Comment,
+			"expires":           req.Expires,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req.NotifyUser()
+
+	_ = event.PublishDispatch(db, "user.change")
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	c.JSON(200, req)
+}
+
+func accessRequestDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	reqId, ok := utils.ParseObjectId(c.Param("request_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	req, err := accessrequest.Get(db, reqId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := req.End(db, accessrequest.Revoked)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		req.User,
+		audit.AccessRevoke,
+		audit.Fields{
+			"access_request_id": req.Id.Hex(),
+			"type":              req.Type,
+			"resource":          req.ResourceName,
+			"role":              req.Role,
+			"admin_id":          usr.Id.Hex(),
+			"admin":             usr.Username,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req.NotifyUser()
+
+	_ = event.PublishDispatch(db, "user.change")
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	c.JSON(200, nil)
+}
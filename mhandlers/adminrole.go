@@ -0,0 +1,166 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type adminRoleData struct {
+	Id          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Permissions []string           `json:"permissions"`
+	Roles       []string           `json:"roles"`
+}
+
+func adminRolePut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &adminRoleData{}
+
+	roleId, ok := utils.ParseObjectId(c.Param("admin_role_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	role, err := adminrole.Get(db, roleId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	role.Name = data.Name
+	role.Permissions = data.Permissions
+	role.Roles = data.Roles
+
+	fields := set.NewSet(
+		"name",
+		"permissions",
+		"roles",
+	)
+
+	errData, err := role.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = role.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "admin_role.change")
+
+	c.JSON(200, role)
+}
+
+func adminRolePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &adminRoleData{
+		Name: "New Admin Role",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	role := &adminrole.AdminRole{
+		Name:        data.Name,
+		Permissions: data.Permissions,
+		Roles:       data.Roles,
+	}
+
+	errData, err := role.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = role.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "admin_role.change")
+
+	c.JSON(200, role)
+}
+
+func adminRoleDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	roleId, ok := utils.ParseObjectId(c.Param("admin_role_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := adminrole.Remove(db, roleId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "admin_role.change")
+	_ = event.PublishDispatch(db, "user.change")
+
+	c.JSON(200, nil)
+}
+
+func adminRolesGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	roles, err := adminrole.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, roles)
+}
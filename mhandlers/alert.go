@@ -29,6 +29,11 @@ type alertData struct {
 	Ignores   []string           `json:"ignores"`
 	ValueInt  int                `json:"value_int"`
 	ValueStr  string             `json:"value_str"`
+	Duration  int                `json:"duration"`
+	ClearInt  int                `json:"clear_int"`
+	Aggregate string             `json:"aggregate"`
+	Window    int                `json:"window"`
+	Quantile  int                `json:"quantile"`
 }
 
 type alertsData struct {
@@ -73,6 +78,11 @@ func alertPut(c *gin.Context) {
 	alrt.Ignores = data.Ignores
 	alrt.ValueInt = data.ValueInt
 	alrt.ValueStr = data.ValueStr
+	alrt.Duration = data.Duration
+	alrt.ClearInt = data.ClearInt
+	alrt.Aggregate = data.Aggregate
+	alrt.Window = data.Window
+	alrt.Quantile = data.Quantile
 
 	fields := set.NewSet(
 		"name",
@@ -83,6 +93,11 @@ func alertPut(c *gin.Context) {
 		"ignores",
 		"value_int",
 		"value_str",
+		"duration",
+		"clear_int",
+		"aggregate",
+		"window",
+		"quantile",
 	)
 
 	errData, err := alrt.Validate(db)
@@ -138,6 +153,11 @@ func alertPost(c *gin.Context) {
 		Ignores:   data.Ignores,
 		ValueInt:  data.ValueInt,
 		ValueStr:  data.ValueStr,
+		Duration:  data.Duration,
+		ClearInt:  data.ClearInt,
+		Aggregate: data.Aggregate,
+		Window:    data.Window,
+		Quantile:  data.Quantile,
 	}
 
 	errData, err := alrt.Validate(db)
@@ -0,0 +1,310 @@
+package mhandlers
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/alertchannel"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type alertChannelData struct {
+	Id          primitive.ObjectID `json:"id"`
+	Name        string             `json:"name"`
+	Type        string             `json:"type"`
+	Disabled    bool               `json:"disabled"`
+	Roles       []string           `json:"roles"`
+	AlertLevels []int              `json:"alert_levels"`
+	Url         string             `json:"url"`
+	Secret      string             `json:"secret"`
+	Template    string             `json:"template"`
+	Recipients  []string           `json:"recipients"`
+}
+
+type alertChannelsData struct {
+	Channels []*alertchannel.Channel `json:"channels"`
+	Count    int64                   `json:"count"`
+}
+
+func alertChannelPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &alertChannelData{}
+
+	chnlId, ok := utils.ParseObjectId(c.Param("channel_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	chnl, err := alertchannel.Get(db, chnlId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	chnl.Name = data.Name
+	chnl.Type = data.Type
+	chnl.Disabled = data.Disabled
+	chnl.Roles = data.Roles
+	chnl.AlertLevels = data.AlertLevels
+	chnl.Url = data.Url
+	if data.Secret != "" {
+		chnl.Secret = data.Secret
+	}
+	chnl.Template = data.Template
+	chnl.Recipients = data.Recipients
+
+	fields := set.NewSet(
+		"name",
+		"type",
+		"disabled",
+		"roles",
+		"alert_levels",
+		"url",
+		"secret",
+		"template",
+		"recipients",
+	)
+
+	errData, err := chnl.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = chnl.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_channel.change")
+
+	c.JSON(200, chnl)
+}
+
+func alertChannelPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &alertChannelData{
+		Name: "New Channel",
+		Type: alertchannel.Webhook,
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	chnl := &alertchannel.Channel{
+		Name:        data.Name,
+		Type:        data.Type,
+		Disabled:    data.Disabled,
+		Roles:       data.Roles,
+		AlertLevels: data.AlertLevels,
+		Url:         data.Url,
+		Secret:      data.Secret,
+		Template:    data.Template,
+		Recipients:  data.Recipients,
+	}
+
+	errData, err := chnl.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = chnl.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_channel.change")
+
+	c.JSON(200, chnl)
+}
+
+func alertChannelDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	chnlId, ok := utils.ParseObjectId(c.Param("channel_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := alertchannel.Remove(db, chnlId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_channel.change")
+
+	c.JSON(200, nil)
+}
+
+func alertChannelsDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	dta := []primitive.ObjectID{}
+
+	err := c.Bind(&dta)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = alertchannel.RemoveMulti(db, dta)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_channel.change")
+
+	c.JSON(200, nil)
+}
+
+func alertChannelsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	page, _ := strconv.ParseInt(c.Query("page"), 10, 0)
+	pageCount, _ := strconv.ParseInt(c.Query("page_count"), 10, 0)
+
+	if pageCount == 0 {
+		pageCount = 50
+	}
+
+	query := bson.M{}
+
+	name := strings.TrimSpace(c.Query("name"))
+	if name != "" {
+		query["name"] = &bson.M{
+			"$regex":   fmt.Sprintf(".*%s.*", regexp.QuoteMeta(name)),
+			"$options": "i",
+		}
+	}
+
+	typ := strings.TrimSpace(c.Query("type"))
+	if typ != "" {
+		query["type"] = typ
+	}
+
+	role := strings.TrimSpace(c.Query("role"))
+	if role != "" {
+		query["roles"] = role
+	}
+
+	channels, count, err := alertchannel.GetAllPaged(
+		db, &query, page, pageCount)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	dta := &alertChannelsData{
+		Channels: channels,
+		Count:    count,
+	}
+
+	c.JSON(200, dta)
+}
+
+func alertChannelTestPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	chnlId, ok := utils.ParseObjectId(c.Param("channel_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	chnl, err := alertchannel.Get(db, chnlId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = alertchannel.SendTest(db, chnl)
+	if err != nil {
+		errData := &errortypes.ErrorData{
+			Error:   "alert_channel_test_failed",
+			Message: err.Error(),
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	c.JSON(200, nil)
+}
+
+func alertChannelLogsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	chnlId, ok := utils.ParseObjectId(c.Param("channel_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	logs, err := alertchannel.GetLogs(db, chnlId, 100)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, logs)
+}
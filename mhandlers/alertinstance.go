@@ -0,0 +1,595 @@
+package mhandlers
+
+import (
+	"html/template"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/alertinstance"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type alertInstancesData struct {
+	Instances []*alertinstance.Instance `json:"instances"`
+	Count     int64                     `json:"count"`
+}
+
+type alertSilenceData struct {
+	Id        primitive.ObjectID   `json:"id"`
+	Name      string               `json:"name"`
+	Comment   string               `json:"comment"`
+	Endpoints []primitive.ObjectID `json:"endpoints"`
+	Alerts    []primitive.ObjectID `json:"alerts"`
+	Resources []string             `json:"resources"`
+	Start     time.Time            `json:"start"`
+	End       time.Time            `json:"end"`
+}
+
+type alertMaintenanceData struct {
+	Id        primitive.ObjectID   `json:"id"`
+	Name      string               `json:"name"`
+	Comment   string               `json:"comment"`
+	Endpoints []primitive.ObjectID `json:"endpoints"`
+	Roles     []string             `json:"roles"`
+	Start     time.Time            `json:"start"`
+	End       time.Time            `json:"end"`
+}
+
+func alertInstancesGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	page, _ := strconv.ParseInt(c.Query("page"), 10, 0)
+	pageCount, _ := strconv.ParseInt(c.Query("page_count"), 10, 0)
+
+	if pageCount == 0 {
+		pageCount = 50
+	}
+
+	query := bson.M{}
+
+	switch c.Query("active") {
+	case "true":
+		query["active"] = true
+		break
+	case "false":
+		query["active"] = false
+		break
+	}
+
+	state := strings.TrimSpace(c.Query("state"))
+	if state != "" {
+		query["state"] = state
+	}
+
+	sourceId, ok := utils.ParseObjectId(c.Query("source"))
+	if ok {
+		query["source"] = sourceId
+	}
+
+	alertId, ok := utils.ParseObjectId(c.Query("alert"))
+	if ok {
+		query["alert"] = alertId
+	}
+
+	instances, count, err := alertinstance.GetAllPaged(
+		db, &query, page, pageCount)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	dta := &alertInstancesData{
+		Instances: instances,
+		Count:     count,
+	}
+
+	c.JSON(200, dta)
+}
+
+func alertInstanceAckPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	instId, ok := utils.ParseObjectId(c.Param("instance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	inst, err := alertinstance.Get(db, instId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := inst.Acknowledge(db, usr.Id, usr.Username)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_instance.change")
+
+	c.JSON(200, inst)
+}
+
+var alertAckTemplate = template.Must(template.New("alert_ack").Parse(
+	`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="referrer" content="no-referrer">
+<title>Acknowledge Alert</title>
+<style>
+body{font-family:sans-serif;max-width:640px;margin:30px auto;color:#222}
+button{padding:6px 14px}
+</style>
+</head>
+<body>
+<h2>{{.Name}}</h2>
+<p>{{.Resource}}</p>
+<p>{{.Message}}</p>
+{{if .Status}}<p>{{.Status}}</p>{{else}}<form method="post" action="/alert_ack">
+<input type="hidden" name="token" value="{{.Token}}">
+<button type="submit">Acknowledge</button>
+</form>{{end}}
+</body>
+</html>
+`))
+
+type alertAckData struct {
+	Name     string
+	Resource string
+	Message  string
+	Status   string
+	Token    string
+}
+
+func renderAlertAck(c *gin.Context, inst *alertinstance.Instance,
+	token, status string) {
+
+	c.Header("Cache-Control", "no-store")
+	c.Header("Referrer-Policy", "no-referrer")
+	c.Status(200)
+	c.Header("Content-Type", "text/html; charset=utf-8")
+
+	_ = alertAckTemplate.Execute(c.Writer, &alertAckData{
+		Name:     inst.Name,
+		Resource: inst.Resource,
+		Message:  inst.Message,
+		Status:   status,
+		Token:    token,
+	})
+}
+
+func getAlertAck(c *gin.Context, db *database.Database, token string) (
+	inst *alertinstance.Instance) {
+
+	if token == "" {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	inst, err := alertinstance.GetToken(db, token)
+	if err != nil {
+		inst = nil
+		if _, ok := err.(*database.NotFoundError); ok {
+			utils.AbortWithStatus(c, 404)
+			return
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	return
+}
+
+// Render acknowledgement confirmation, the alert is only acknowledged by
+// the form post to prevent link previews from acknowledging alerts
+func alertAckGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	token := c.Param("token")
+
+	inst := getAlertAck(c, db, token)
+	if inst == nil {
+		return
+	}
+
+	status := ""
+	if inst.State != alertinstance.Firing {
+		status = "Alert is no longer firing"
+	}
+
+	renderAlertAck(c, inst, token, status)
+}
+
+func alertAckPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	token := c.PostForm("token")
+
+	inst := getAlertAck(c, db, token)
+	if inst == nil {
+		return
+	}
+
+	errData, err := inst.Acknowledge(db, primitive.NilObjectID, "link")
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		renderAlertAck(c, inst, "", "Alert is no longer firing")
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_instance.change")
+
+	renderAlertAck(c, inst, "", "Alert acknowledged")
+}
+
+func alertSilencePut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &alertSilenceData{}
+
+	slncId, ok := utils.ParseObjectId(c.Param("silence_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	slnc, err := alertinstance.GetSilence(db, slncId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	slnc.Name = data.Name
+	slnc.Comment = data.Comment
+	slnc.Endpoints = data.Endpoints
+	slnc.Alerts = data.Alerts
+	slnc.Resources = data.Resources
+	slnc.Start = data.Start
+	slnc.End = data.End
+
+	fields := set.NewSet(
+		"name",
+		"comment",
+		"endpoints",
+		"alerts",
+		"resources",
+		"start",
+		"end",
+	)
+
+	errData, err := slnc.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = slnc.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_silence.change")
+
+	c.JSON(200, slnc)
+}
+
+func alertSilencePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &alertSilenceData{
+		Name: "New Silence",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	slnc := &alertinstance.Silence{
+		Name:      data.Name,
+		Comment:   data.Comment,
+		Endpoints: data.Endpoints,
+		Alerts:    data.Alerts,
+		Resources: data.Resources,
+		Start:     data.Start,
+		End:       data.End,
+		User:      usr.Id,
+	}
+
+	errData, err := slnc.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = slnc.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_silence.change")
+
+	c.JSON(200, slnc)
+}
+
+func alertSilenceDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	slncId, ok := utils.ParseObjectId(c.Param("silence_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := alertinstance.RemoveSilence(db, slncId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_silence.change")
+
+	c.JSON(200, nil)
+}
+
+func alertSilencesGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	query := bson.M{}
+
+	if c.Query("active") == "true" {
+		now := time.Now()
+		query["start"] = &bson.M{
+			"$lte": now,
+		}
+		query["end"] = &bson.M{
+			"$gt": now,
+		}
+	}
+
+	silences, err := alertinstance.GetSilences(db, &query)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, silences)
+}
+
+func alertMaintenancePut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &alertMaintenanceData{}
+
+	mntcId, ok := utils.ParseObjectId(c.Param("maintenance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	mntc, err := alertinstance.GetMaintenance(db, mntcId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	mntc.Name = data.Name
+	mntc.Comment = data.Comment
+	mntc.Endpoints = data.Endpoints
+	mntc.Roles = data.Roles
+	mntc.Start = data.Start
+	mntc.End = data.End
+
+	fields := set.NewSet(
+		"name",
+		"comment",
+		"endpoints",
+		"roles",
+		"start",
+		"end",
+	)
+
+	errData, err := mntc.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = mntc.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_maintenance.change")
+
+	c.JSON(200, mntc)
+}
+
+func alertMaintenancePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &alertMaintenanceData{
+		Name: "New Maintenance",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	mntc := &alertinstance.Maintenance{
+		Name:      data.Name,
+		Comment:   data.Comment,
+		Endpoints: data.Endpoints,
+		Roles:     data.Roles,
+		Start:     data.Start,
+		End:       data.End,
+	}
+
+	errData, err := mntc.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = mntc.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_maintenance.change")
+
+	c.JSON(200, mntc)
+}
+
+func alertMaintenanceDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	mntcId, ok := utils.ParseObjectId(c.Param("maintenance_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := alertinstance.RemoveMaintenance(db, mntcId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "alert_maintenance.change")
+
+	c.JSON(200, nil)
+}
+
+func alertMaintenancesGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	query := bson.M{}
+
+	if c.Query("active") == "true" {
+		now := time.Now()
+		query["start"] = &bson.M{
+			"$lte": now,
+		}
+		query["end"] = &bson.M{
+			"$gt": now,
+		}
+	}
+
+	maintenances, err := alertinstance.GetMaintenances(db, &query)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, maintenances)
+}
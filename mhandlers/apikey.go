@@ -0,0 +1,267 @@
+package mhandlers
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/apikey"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type apiKeyData struct {
+	User      primitive.ObjectID `json:"user"`
+	Name      string             `json:"name"`
+	Expires   time.Time          `json:"expires"`
+	ReadOnly  bool               `json:"read_only"`
+	Resources []string           `json:"resources"`
+	Service   primitive.ObjectID `json:"service"`
+}
+
+// Keys cannot be created or modified with a key, this prevents a scoped key
+// from creating a key with broader access
+func apiKeyAuthBlocked(c *gin.Context) bool {
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	if !authr.IsApi() {
+		return false
+	}
+
+	c.JSON(403, &errortypes.ErrorData{
+		Error:   "api_key_auth_invalid",
+		Message: "API keys cannot be managed with an API key",
+	})
+	return true
+}
+
+func apiKeyPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	if apiKeyAuthBlocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &apiKeyData{}
+
+	keyId, ok := utils.ParseObjectId(c.Param("apikey_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	key, err := apikey.Get(db, keyId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := user.Get(db, key.User)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	ok, err = canManageUser(c, db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	key.Name = data.Name
+	key.Expires = data.Expires
+	key.ReadOnly = data.ReadOnly
+	key.Resources = data.Resources
+	key.Service = data.Service
+
+	fields := set.NewSet(
+		"name",
+		"expires",
+		"read_only",
+		"resources",
+		"service",
+	)
+
+	errData, err := key.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = key.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "apikey.change")
+
+	c.JSON(200, key)
+}
+
+func apiKeyPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	if apiKeyAuthBlocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &apiKeyData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := user.Get(db, data.User)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	ok, err := canManageUser(c, db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	key := apikey.New(usr.Id)
+
+	key.Name = data.Name
+	key.Expires = data.Expires
+	key.ReadOnly = data.ReadOnly
+	key.Resources = data.Resources
+	key.Service = data.Service
+
+	errData, err := key.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = key.GenerateToken()
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = key.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "apikey.change")
+
+	c.JSON(200, key)
+}
+
+func apiKeyDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	keyId, ok := utils.ParseObjectId(c.Param("apikey_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	key, err := apikey.Get(db, keyId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := user.Get(db, key.User)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	ok, err = canManageUser(c, db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	err = apikey.Remove(db, key.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "apikey.change")
+
+	c.JSON(200, nil)
+}
+
+func apiKeysGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	usrId, ok := utils.ParseObjectId(c.Param("user_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	keys, err := apikey.GetAll(db, usrId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, keys)
+}
@@ -109,6 +109,25 @@ func authSessionPost(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, "local") {
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.AdminLoginFailed, "local")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.AdminLoginFailed, "local") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -167,9 +186,7 @@ func authSessionPost(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.AdminLogin,
-		audit.Fields{
-			"method": "local",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -178,7 +195,7 @@ func authSessionPost(c *gin.Context) {
 
 	cook := cookie.NewAdmin(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -344,7 +361,7 @@ func authSecondaryPost(c *gin.Context) {
 
 	cook := cookie.NewAdmin(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -477,6 +494,25 @@ func authCallbackGet(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, "callback") {
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.AdminLoginFailed, "callback")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.AdminLoginFailed, "callback") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -535,9 +571,7 @@ func authCallbackGet(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.AdminLogin,
-		audit.Fields{
-			"method": "callback",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -546,7 +580,7 @@ func authCallbackGet(c *gin.Context) {
 
 	cook := cookie.NewAdmin(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -771,7 +805,7 @@ func authWanRegisterPost(c *gin.Context) {
 
 	cook := cookie.NewAdmin(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -985,7 +1019,7 @@ func authWanRespondPost(c *gin.Context) {
 
 	cook := cookie.NewAdmin(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
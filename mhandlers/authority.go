@@ -12,6 +12,7 @@ import (
 	"github.com/pritunl/pritunl-zero/demo"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/revision"
 	"github.com/pritunl/pritunl-zero/utils"
 )
 
@@ -68,6 +69,8 @@ func authorityPut(c *gin.Context) {
 		return
 	}
 
+	prev := revisionSnapshot(authr)
+
 	showSecret := false
 	if authr.Type != data.Type {
 		if data.Type == authority.PritunlHsm {
@@ -158,6 +161,9 @@ func authorityPut(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Authority, revision.Update,
+		authr.Id, prev, authr)
+
 	_ = event.PublishDispatch(db, "authority.change")
 	_ = event.PublishDispatch(db, "node.change")
 
@@ -228,6 +234,9 @@ func authorityPost(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Authority, revision.Create,
+		authr.Id, nil, authr)
+
 	_ = event.PublishDispatch(db, "authority.change")
 
 	authr.Json()
@@ -248,6 +257,12 @@ func authorityDelete(c *gin.Context) {
 		return
 	}
 
+	authr, err := authority.Get(db, authrId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
 	errData, err := authority.Remove(db, authrId)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -259,6 +274,9 @@ func authorityDelete(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Authority, revision.Delete,
+		authr.Id, authr, nil)
+
 	_ = event.PublishDispatch(db, "authority.change")
 	_ = event.PublishDispatch(db, "node.change")
 
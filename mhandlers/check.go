@@ -32,6 +32,13 @@ type checkData struct {
 	Method     string             `json:"method"`
 	StatusCode int                `json:"status_code"`
 	Headers    []*check.Header    `json:"headers"`
+	Banner     string             `json:"banner"`
+	RecordType string             `json:"record_type"`
+	Answers    []string           `json:"answers"`
+	Resolver   string             `json:"resolver"`
+	ExpiryDays int                `json:"expiry_days"`
+	Assertions []*check.Assertion `json:"assertions"`
+	Steps      []*check.Step      `json:"steps"`
 }
 
 type checksData struct {
@@ -77,6 +84,13 @@ func checkPut(c *gin.Context) {
 	chck.Method = data.Method
 	chck.StatusCode = data.StatusCode
 	chck.Headers = data.Headers
+	chck.Banner = data.Banner
+	chck.RecordType = data.RecordType
+	chck.Answers = data.Answers
+	chck.Resolver = data.Resolver
+	chck.ExpiryDays = data.ExpiryDays
+	chck.Assertions = data.Assertions
+	chck.Steps = data.Steps
 
 	fields := set.NewSet(
 		"name",
@@ -88,6 +102,13 @@ func checkPut(c *gin.Context) {
 		"method",
 		"status_code",
 		"headers",
+		"banner",
+		"record_type",
+		"answers",
+		"resolver",
+		"expiry_days",
+		"assertions",
+		"steps",
 	)
 
 	errData, err := chck.Validate(db)
@@ -142,6 +163,13 @@ func checkPost(c *gin.Context) {
 		Method:     data.Method,
 		StatusCode: data.StatusCode,
 		Headers:    data.Headers,
+		Banner:     data.Banner,
+		RecordType: data.RecordType,
+		Answers:    data.Answers,
+		Resolver:   data.Resolver,
+		ExpiryDays: data.ExpiryDays,
+		Assertions: data.Assertions,
+		Steps:      data.Steps,
 	}
 
 	errData, err := chck.Validate(db)
@@ -2,6 +2,7 @@ package mhandlers
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/adminrole"
 	"github.com/pritunl/pritunl-zero/authorizer"
 	"github.com/pritunl/pritunl-zero/csrf"
 	"github.com/pritunl/pritunl-zero/database"
@@ -9,13 +10,17 @@ import (
 )
 
 type csrfData struct {
-	Token string `json:"token"`
-	Theme string `json:"theme"`
+	Token            string              `json:"token"`
+	Theme            string              `json:"theme"`
+	Super            bool                `json:"super"`
+	Permissions      []string            `json:"permissions"`
+	PermissionScopes map[string][]string `json:"permission_scopes"`
 }
 
 func csrfGet(c *gin.Context) {
 	db := c.MustGet("db").(*database.Database)
 	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	access := c.MustGet("access").(*adminrole.Access)
 
 	usr, err := authr.GetUser(db)
 	if err != nil {
@@ -30,8 +35,11 @@ func csrfGet(c *gin.Context) {
 	}
 
 	data := &csrfData{
-		Token: token,
-		Theme: usr.Theme,
+		Token:            token,
+		Theme:            usr.Theme,
+		Super:            access.Super,
+		Permissions:      access.Permissions(),
+		PermissionScopes: access.PermissionScopes(),
 	}
 	c.JSON(200, data)
 }
@@ -25,6 +25,7 @@ import (
 
 type endpointData struct {
 	Id             primitive.ObjectID `json:"id"`
+	User           primitive.ObjectID `json:"user"`
 	Name           string             `json:"name"`
 	Roles          []string           `json:"roles"`
 	ResetClientKey bool               `json:"reset_client_key"`
@@ -69,6 +70,7 @@ func endpointPut(c *gin.Context) {
 		return
 	}
 
+	endpt.User = data.User
 	endpt.Name = data.Name
 	endpt.Roles = data.Roles
 
@@ -81,6 +83,7 @@ func endpointPut(c *gin.Context) {
 	}
 
 	fields := set.NewSet(
+		"user",
 		"name",
 		"roles",
 		"client_key",
@@ -131,6 +134,7 @@ func endpointPost(c *gin.Context) {
 	}
 
 	endpt := &endpoint.Endpoint{
+		User:  data.User,
 		Name:  data.Name,
 		Roles: data.Roles,
 	}
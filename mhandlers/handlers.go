@@ -4,6 +4,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/adminrole"
 	"github.com/pritunl/pritunl-zero/config"
 	"github.com/pritunl/pritunl-zero/constants"
 	"github.com/pritunl/pritunl-zero/handlers"
@@ -38,15 +39,85 @@ func Register(engine *gin.Engine) {
 
 	engine.NoRoute(middlewear.NotFound)
 
-	csrfGroup.GET("/audit/:user_id", auditsGet)
-
-	csrfGroup.GET("/alert", alertsGet)
-	csrfGroup.PUT("/alert/:alert_id", alertPut)
-	csrfGroup.POST("/alert", alertPost)
-	csrfGroup.DELETE("/alert", alertsDelete)
-	csrfGroup.DELETE("/alert/:alert_id", alertDelete)
+	csrfGroup.GET("/access_request",
+		middlewear.Permission(adminrole.UsersRead), accessRequestsGet)
+	csrfGroup.PUT("/access_request/:request_id",
+		middlewear.Permission(adminrole.UsersWrite), accessRequestPut)
+	csrfGroup.DELETE("/access_request/:request_id",
+		middlewear.Permission(adminrole.UsersWrite), accessRequestDelete)
+
+	csrfGroup.GET("/admin_role",
+		middlewear.Permission(adminrole.UsersRead), adminRolesGet)
+	csrfGroup.PUT("/admin_role/:admin_role_id",
+		middlewear.AuthSuper, adminRolePut)
+	csrfGroup.POST("/admin_role", middlewear.AuthSuper, adminRolePost)
+	csrfGroup.DELETE("/admin_role/:admin_role_id",
+		middlewear.AuthSuper, adminRoleDelete)
+
+	csrfGroup.GET("/audit/:user_id",
+		middlewear.Permission(adminrole.AuditRead), auditsGet)
+
+	csrfGroup.GET("/alert",
+		middlewear.Permission(adminrole.AlertsRead), alertsGet)
+	csrfGroup.PUT("/alert/:alert_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertPut)
+	csrfGroup.POST("/alert",
+		middlewear.Permission(adminrole.AlertsWrite), alertPost)
+	csrfGroup.DELETE("/alert",
+		middlewear.Permission(adminrole.AlertsWrite), alertsDelete)
+	csrfGroup.DELETE("/alert/:alert_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertDelete)
+
+	csrfGroup.GET("/alert_channel",
+		middlewear.Permission(adminrole.AlertsRead), alertChannelsGet)
+	csrfGroup.PUT("/alert_channel/:channel_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertChannelPut)
+	csrfGroup.POST("/alert_channel",
+		middlewear.Permission(adminrole.AlertsWrite), alertChannelPost)
+	csrfGroup.DELETE("/alert_channel",
+		middlewear.Permission(adminrole.AlertsWrite), alertChannelsDelete)
+	csrfGroup.DELETE("/alert_channel/:channel_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertChannelDelete)
+	csrfGroup.POST("/alert_channel/:channel_id/test",
+		middlewear.Permission(adminrole.AlertsWrite), alertChannelTestPost)
+	csrfGroup.GET("/alert_channel/:channel_id/log",
+		middlewear.Permission(adminrole.AlertsRead), alertChannelLogsGet)
+
+	csrfGroup.GET("/alert_instance",
+		middlewear.Permission(adminrole.AlertsRead), alertInstancesGet)
+	csrfGroup.PUT("/alert_instance/:instance_id/ack",
+		middlewear.Permission(adminrole.AlertsWrite), alertInstanceAckPut)
+
+	csrfGroup.GET("/alert_silence",
+		middlewear.Permission(adminrole.AlertsRead), alertSilencesGet)
+	csrfGroup.PUT("/alert_silence/:silence_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertSilencePut)
+	csrfGroup.POST("/alert_silence",
+		middlewear.Permission(adminrole.AlertsWrite), alertSilencePost)
+	csrfGroup.DELETE("/alert_silence/:silence_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertSilenceDelete)
+
+	csrfGroup.GET("/alert_maintenance",
+		middlewear.Permission(adminrole.AlertsRead), alertMaintenancesGet)
+	csrfGroup.PUT("/alert_maintenance/:maintenance_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertMaintenancePut)
+	csrfGroup.POST("/alert_maintenance",
+		middlewear.Permission(adminrole.AlertsWrite), alertMaintenancePost)
+	csrfGroup.DELETE("/alert_maintenance/:maintenance_id",
+		middlewear.Permission(adminrole.AlertsWrite), alertMaintenanceDelete)
+
+	csrfGroup.GET("/apikey/:user_id",
+		middlewear.Permission(adminrole.UsersRead), apiKeysGet)
+	csrfGroup.PUT("/apikey/:apikey_id",
+		middlewear.Permission(adminrole.UsersWrite), apiKeyPut)
+	csrfGroup.POST("/apikey",
+		middlewear.Permission(adminrole.UsersWrite), apiKeyPost)
+	csrfGroup.DELETE("/apikey/:apikey_id",
+		middlewear.Permission(adminrole.UsersWrite), apiKeyDelete)
 
 	engine.GET("/auth/state", authStateGet)
+	dbGroup.GET("/alert_ack/:token", alertAckGet)
+	dbGroup.POST("/alert_ack", alertAckPost)
 	dbGroup.POST("/auth/session", authSessionPost)
 	dbGroup.POST("/auth/secondary", authSecondaryPost)
 	dbGroup.GET("/auth/request", authRequestGet)
@@ -55,99 +126,235 @@ func Register(engine *gin.Engine) {
 	dbGroup.POST("/auth/webauthn/respond", authWanRespondPost)
 	dbGroup.GET("/auth/webauthn/register", authWanRegisterGet)
 	dbGroup.POST("/auth/webauthn/register", authWanRegisterPost)
+	dbGroup.GET("/auth/passkey/request", authPasskeyRequestGet)
+	dbGroup.POST("/auth/passkey/respond", authPasskeyRespondPost)
 	sessGroup.GET("/logout", logoutGet)
 
-	csrfGroup.GET("/authority", authoritysGet)
-	csrfGroup.GET("/authority/:authr_id", authorityGet)
-	csrfGroup.PUT("/authority/:authr_id", authorityPut)
-	csrfGroup.POST("/authority", authorityPost)
-	csrfGroup.DELETE("/authority/:authr_id", authorityDelete)
-	csrfGroup.POST("/authority/:authr_id/token", authorityTokenPost)
+	csrfGroup.GET("/authority",
+		middlewear.Permission(adminrole.AuthoritiesRead), authoritysGet)
+	csrfGroup.GET("/authority/:authr_id",
+		middlewear.Permission(adminrole.AuthoritiesRead), authorityGet)
+	csrfGroup.PUT("/authority/:authr_id",
+		middlewear.Permission(adminrole.AuthoritiesWrite), authorityPut)
+	csrfGroup.POST("/authority",
+		middlewear.Permission(adminrole.AuthoritiesWrite), authorityPost)
+	csrfGroup.DELETE("/authority/:authr_id",
+		middlewear.Permission(adminrole.AuthoritiesWrite), authorityDelete)
+	csrfGroup.GET("/authority/:authr_id/revision",
+		middlewear.Permission(adminrole.AuthoritiesRead),
+		authorityRevisionsGet)
+	csrfGroup.GET("/authority/:authr_id/revision/:revision_id",
+		middlewear.Permission(adminrole.AuthoritiesRead),
+		authorityRevisionGet)
+	csrfGroup.GET("/authority/:authr_id/revision/:revision_id/compare/:other_id",
+		middlewear.Permission(adminrole.AuthoritiesRead),
+		authorityRevisionCompareGet)
+	csrfGroup.POST("/authority/:authr_id/revision/:revision_id/rollback",
+		middlewear.Permission(adminrole.AuthoritiesWrite),
+		authorityRevisionRollbackPost)
+	csrfGroup.POST("/authority/:authr_id/token",
+		middlewear.Permission(adminrole.AuthoritiesSign), authorityTokenPost)
 	csrfGroup.DELETE("/authority/:authr_id/token/:token",
+		middlewear.Permission(adminrole.AuthoritiesSign),
 		authorityTokenDelete)
 	dbGroup.GET("/ssh_public_key/:authr_ids", authorityPublicKeyGet)
 
-	csrfGroup.GET("/certificate", certificatesGet)
-	csrfGroup.GET("/certificate/:cert_id", certificateGet)
-	csrfGroup.PUT("/certificate/:cert_id", certificatePut)
-	csrfGroup.POST("/certificate", certificatePost)
-	csrfGroup.DELETE("/certificate/:cert_id", certificateDelete)
+	csrfGroup.GET("/certificate",
+		middlewear.Permission(adminrole.CertificatesRead), certificatesGet)
+	csrfGroup.GET("/certificate/:cert_id",
+		middlewear.Permission(adminrole.CertificatesRead), certificateGet)
+	csrfGroup.PUT("/certificate/:cert_id",
+		middlewear.Permission(adminrole.CertificatesWrite), certificatePut)
+	csrfGroup.POST("/certificate",
+		middlewear.Permission(adminrole.CertificatesWrite), certificatePost)
+	csrfGroup.DELETE("/certificate/:cert_id",
+		middlewear.Permission(adminrole.CertificatesWrite), certificateDelete)
 
 	engine.GET("/check", checkGet)
 
-	csrfGroup.GET("/checks", checksGet)
-	csrfGroup.PUT("/checks/:check_id", checkPut)
-	csrfGroup.POST("/checks", checkPost)
-	csrfGroup.DELETE("/checks", checksDelete)
-	csrfGroup.DELETE("/checks/:check_id", checkDelete)
-	csrfGroup.GET("/checks/:check_id/chart", checkChartGet)
-	csrfGroup.GET("/checks/:check_id/log", checkLogGet)
+	csrfGroup.GET("/checks",
+		middlewear.Permission(adminrole.ChecksRead), checksGet)
+	csrfGroup.PUT("/checks/:check_id",
+		middlewear.Permission(adminrole.ChecksWrite), checkPut)
+	csrfGroup.POST("/checks",
+		middlewear.Permission(adminrole.ChecksWrite), checkPost)
+	csrfGroup.DELETE("/checks",
+		middlewear.Permission(adminrole.ChecksWrite), checksDelete)
+	csrfGroup.DELETE("/checks/:check_id",
+		middlewear.Permission(adminrole.ChecksWrite), checkDelete)
+	csrfGroup.GET("/checks/:check_id/chart",
+		middlewear.Permission(adminrole.ChecksRead), checkChartGet)
+	csrfGroup.GET("/checks/:check_id/log",
+		middlewear.Permission(adminrole.ChecksRead), checkLogGet)
+	csrfGroup.GET("/checks/:check_id/report",
+		middlewear.Permission(adminrole.ChecksRead), checkReportGet)
 
 	authGroup.GET("/csrf", csrfGet)
 
-	csrfGroup.GET("/device/:user_id", devicesGet)
-	csrfGroup.PUT("/device/:device_id", devicePut)
-	csrfGroup.POST("/device", devicePost)
-	csrfGroup.DELETE("/device/:device_id", deviceDelete)
-	csrfGroup.POST("/device/:resource_id/:method", deviceMethodPost)
-	csrfGroup.GET("/device/:user_id/webauthn/register", deviceWanRegisterGet)
+	csrfGroup.GET("/device/:user_id",
+		middlewear.Permission(adminrole.DevicesRead), devicesGet)
+	csrfGroup.PUT("/device/:device_id",
+		middlewear.Permission(adminrole.DevicesWrite), devicePut)
+	csrfGroup.POST("/device",
+		middlewear.Permission(adminrole.DevicesWrite), devicePost)
+	csrfGroup.DELETE("/device/:device_id",
+		middlewear.Permission(adminrole.DevicesWrite), deviceDelete)
+	csrfGroup.POST("/device/:resource_id/:method",
+		middlewear.Permission(adminrole.DevicesWrite), deviceMethodPost)
+	csrfGroup.GET("/device/:user_id/webauthn/register",
+		middlewear.Permission(adminrole.DevicesWrite), deviceWanRegisterGet)
 	csrfGroup.POST("/device/:resource_id/webauthn/register",
+		middlewear.Permission(adminrole.DevicesWrite),
 		deviceWanRegisterPost)
 
-	csrfGroup.GET("/endpoint", endpointsGet)
-	csrfGroup.PUT("/endpoint/:endpoint_id", endpointPut)
-	csrfGroup.POST("/endpoint", endpointPost)
-	csrfGroup.DELETE("/endpoint", endpointsDelete)
-	csrfGroup.DELETE("/endpoint/:endpoint_id", endpointDelete)
-	csrfGroup.GET("/endpoint/:endpoint_id/chart", endpointChartGet)
-	csrfGroup.GET("/endpoint/:endpoint_id/log", endpointLogGet)
+	csrfGroup.GET("/endpoint",
+		middlewear.Permission(adminrole.EndpointsRead), endpointsGet)
+	csrfGroup.PUT("/endpoint/:endpoint_id",
+		middlewear.Permission(adminrole.EndpointsWrite), endpointPut)
+	csrfGroup.POST("/endpoint",
+		middlewear.Permission(adminrole.EndpointsWrite), endpointPost)
+	csrfGroup.DELETE("/endpoint",
+		middlewear.Permission(adminrole.EndpointsWrite), endpointsDelete)
+	csrfGroup.DELETE("/endpoint/:endpoint_id",
+		middlewear.Permission(adminrole.EndpointsWrite), endpointDelete)
+	csrfGroup.GET("/endpoint/:endpoint_id/chart",
+		middlewear.Permission(adminrole.EndpointsRead), endpointChartGet)
+	csrfGroup.GET("/endpoint/:endpoint_id/log",
+		middlewear.Permission(adminrole.EndpointsRead), endpointLogGet)
+	csrfGroup.GET("/endpoint/:endpoint_id/report",
+		middlewear.Permission(adminrole.EndpointsRead), endpointReportGet)
 
 	dbGroup.PUT("/endpoint/:endpoint_id/register",
 		handlers.EndpointRegisterPut)
+	dbGroup.PUT("/endpoint/:endpoint_id/challenge",
+		handlers.EndpointChallengePut)
 	dbGroup.GET("/endpoint/:endpoint_id/comm",
 		handlers.EndpointCommGet)
 
-	csrfGroup.GET("/event", eventGet)
-
-	csrfGroup.GET("/log", logsGet)
-	csrfGroup.GET("/log/:log_id", logGet)
-
-	csrfGroup.GET("/node", nodesGet)
-	csrfGroup.GET("/node/:node_id", nodeGet)
-	csrfGroup.PUT("/node/:node_id", nodePut)
-	csrfGroup.DELETE("/node/:node_id", nodeDelete)
-
-	csrfGroup.GET("/policy", policiesGet)
-	csrfGroup.GET("/policy/:policy_id", policyGet)
-	csrfGroup.PUT("/policy/:policy_id", policyPut)
-	csrfGroup.POST("/policy", policyPost)
-	csrfGroup.DELETE("/policy/:policy_id", policyDelete)
-
-	csrfGroup.GET("/service", servicesGet)
-	csrfGroup.PUT("/service/:service_id", servicePut)
-	csrfGroup.POST("/service", servicePost)
-	csrfGroup.DELETE("/service", servicesDelete)
-	csrfGroup.DELETE("/service/:service_id", serviceDelete)
-
-	csrfGroup.GET("/session/:user_id", sessionsGet)
-	csrfGroup.DELETE("/session/:session_id", sessionDelete)
-
-	csrfGroup.GET("/settings", settingsGet)
-	csrfGroup.PUT("/settings", settingsPut)
-
-	csrfGroup.GET("/sshcertificate/:user_id", sshcertsGet)
-
-	csrfGroup.GET("/subscription", subscriptionGet)
-	csrfGroup.GET("/subscription/update", subscriptionUpdateGet)
-	csrfGroup.POST("/subscription", subscriptionPost)
+	csrfGroup.GET("/event", middlewear.PermissionAny, eventGet)
+
+	csrfGroup.GET("/log",
+		middlewear.Permission(adminrole.LogsRead), logsGet)
+	csrfGroup.GET("/log/:log_id",
+		middlewear.Permission(adminrole.LogsRead), logGet)
+
+	csrfGroup.GET("/node",
+		middlewear.Permission(adminrole.NodesRead), nodesGet)
+	csrfGroup.GET("/node/:node_id",
+		middlewear.Permission(adminrole.NodesRead), nodeGet)
+	csrfGroup.PUT("/node/:node_id",
+		middlewear.Permission(adminrole.NodesWrite), nodePut)
+	csrfGroup.DELETE("/node/:node_id",
+		middlewear.Permission(adminrole.NodesWrite), nodeDelete)
+
+	csrfGroup.GET("/oidc_client",
+		middlewear.Permission(adminrole.ServicesRead), oidcClientsGet)
+	csrfGroup.PUT("/oidc_client/:client_id",
+		middlewear.Permission(adminrole.ServicesWrite), oidcClientPut)
+	csrfGroup.POST("/oidc_client",
+		middlewear.Permission(adminrole.ServicesWrite), oidcClientPost)
+	csrfGroup.DELETE("/oidc_client/:client_id",
+		middlewear.Permission(adminrole.ServicesWrite), oidcClientDelete)
+
+	dbGroup.GET("/prometheus/metrics", prometheusMetricsGet)
+
+	csrfGroup.GET("/policy",
+		middlewear.Permission(adminrole.PoliciesRead), policiesGet)
+	csrfGroup.GET("/policy/:policy_id",
+		middlewear.Permission(adminrole.PoliciesRead), policyGet)
+	csrfGroup.PUT("/policy/:policy_id",
+		middlewear.Permission(adminrole.PoliciesWrite), policyPut)
+	csrfGroup.POST("/policy",
+		middlewear.Permission(adminrole.PoliciesWrite), policyPost)
+	csrfGroup.DELETE("/policy/:policy_id",
+		middlewear.Permission(adminrole.PoliciesWrite), policyDelete)
+	csrfGroup.GET("/policy/:policy_id/revision",
+		middlewear.Permission(adminrole.PoliciesRead), policyRevisionsGet)
+	csrfGroup.GET("/policy/:policy_id/revision/:revision_id",
+		middlewear.Permission(adminrole.PoliciesRead), policyRevisionGet)
+	csrfGroup.GET("/policy/:policy_id/revision/:revision_id/compare/:other_id",
+		middlewear.Permission(adminrole.PoliciesRead),
+		policyRevisionCompareGet)
+	csrfGroup.POST("/policy/:policy_id/revision/:revision_id/rollback",
+		middlewear.Permission(adminrole.PoliciesWrite),
+		policyRevisionRollbackPost)
+	csrfGroup.POST("/policy_simulate",
+		middlewear.Permission(adminrole.PoliciesRead), policySimulatePost)
+
+	csrfGroup.GET("/service",
+		middlewear.Permission(adminrole.ServicesRead), servicesGet)
+	csrfGroup.PUT("/service/:service_id",
+		middlewear.Permission(adminrole.ServicesWrite), servicePut)
+	csrfGroup.POST("/service",
+		middlewear.Permission(adminrole.ServicesWrite), servicePost)
+	csrfGroup.DELETE("/service",
+		middlewear.Permission(adminrole.ServicesWrite), servicesDelete)
+	csrfGroup.DELETE("/service/:service_id",
+		middlewear.Permission(adminrole.ServicesWrite), serviceDelete)
+	csrfGroup.GET("/service/:service_id/revision",
+		middlewear.Permission(adminrole.ServicesRead), serviceRevisionsGet)
+	csrfGroup.GET("/service/:service_id/revision/:revision_id",
+		middlewear.Permission(adminrole.ServicesRead), serviceRevisionGet)
+	csrfGroup.GET("/service/:service_id/revision/:revision_id/compare/:other_id",
+		middlewear.Permission(adminrole.ServicesRead),
+		serviceRevisionCompareGet)
+	csrfGroup.POST("/service/:service_id/revision/:revision_id/rollback",
+		middlewear.Permission(adminrole.ServicesWrite),
+		serviceRevisionRollbackPost)
+
+	csrfGroup.GET("/session/:user_id",
+		middlewear.Permission(adminrole.SessionsRead), sessionsGet)
+	csrfGroup.DELETE("/session/:session_id",
+		middlewear.Permission(adminrole.SessionsWrite), sessionDelete)
+
+	csrfGroup.GET("/settings",
+		middlewear.Permission(adminrole.SettingsRead), settingsGet)
+	csrfGroup.PUT("/settings",
+		middlewear.Permission(adminrole.SettingsWrite), settingsPut)
+
+	csrfGroup.GET("/sshcertificate/:user_id",
+		middlewear.Permission(adminrole.UsersRead), sshcertsGet)
+
+	csrfGroup.GET("/status_page",
+		middlewear.Permission(adminrole.ChecksRead), statusPagesGet)
+	csrfGroup.PUT("/status_page/:page_id",
+		middlewear.Permission(adminrole.ChecksWrite), statusPagePut)
+	csrfGroup.POST("/status_page",
+		middlewear.Permission(adminrole.ChecksWrite), statusPagePost)
+	csrfGroup.DELETE("/status_page/:page_id",
+		middlewear.Permission(adminrole.ChecksWrite), statusPageDelete)
+
+	csrfGroup.GET("/status_post",
+		middlewear.Permission(adminrole.ChecksRead), statusPostsGet)
+	csrfGroup.PUT("/status_post/:post_id",
+		middlewear.Permission(adminrole.ChecksWrite), statusPostPut)
+	csrfGroup.POST("/status_post",
+		middlewear.Permission(adminrole.ChecksWrite), statusPostPost)
+	csrfGroup.DELETE("/status_post/:post_id",
+		middlewear.Permission(adminrole.ChecksWrite), statusPostDelete)
+
+	csrfGroup.GET("/subscription",
+		middlewear.Permission(adminrole.SettingsRead), subscriptionGet)
+	csrfGroup.GET("/subscription/update",
+		middlewear.Permission(adminrole.SettingsRead), subscriptionUpdateGet)
+	csrfGroup.POST("/subscription",
+		middlewear.Permission(adminrole.SettingsWrite), subscriptionPost)
 
 	csrfGroup.PUT("/theme", themePut)
 
-	csrfGroup.GET("/user", usersGet)
-	csrfGroup.GET("/user/:user_id", userGet)
-	csrfGroup.PUT("/user/:user_id", userPut)
-	csrfGroup.POST("/user", userPost)
-	csrfGroup.DELETE("/user", usersDelete)
+	csrfGroup.GET("/user",
+		middlewear.Permission(adminrole.UsersRead), usersGet)
+	csrfGroup.GET("/user/:user_id",
+		middlewear.Permission(adminrole.UsersRead), userGet)
+	csrfGroup.GET("/user/:user_id/access",
+		middlewear.Permission(adminrole.PoliciesRead), userAccessGet)
+	csrfGroup.PUT("/user/:user_id",
+		middlewear.Permission(adminrole.UsersWrite, adminrole.UsersDisable),
+		userPut)
+	csrfGroup.POST("/user",
+		middlewear.Permission(adminrole.UsersWrite), userPost)
+	csrfGroup.DELETE("/user",
+		middlewear.Permission(adminrole.UsersWrite), usersDelete)
 
 	engine.GET("/robots.txt", middlewear.RobotsGet)
 
@@ -0,0 +1,244 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/oidc"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+// Scoped administrators can only manage clients limited to their roles
+func oidcClientAllowed(access *adminrole.Access, perm string,
+	matchRoles bool, roles []string, all bool) bool {
+
+	if access.Unscoped(perm) {
+		return true
+	}
+
+	if !matchRoles {
+		return false
+	}
+
+	if all {
+		return access.AllowedRolesAll(perm, roles)
+	}
+	return access.AllowedRoles(perm, roles)
+}
+
+type oidcClientData struct {
+	Id             primitive.ObjectID `json:"id"`
+	Name           string             `json:"name"`
+	RedirectUris   []string           `json:"redirect_uris"`
+	MatchRoles     bool               `json:"match_roles"`
+	Roles          []string           `json:"roles"`
+	GenerateSecret bool               `json:"generate_secret"`
+}
+
+func oidcClientPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &oidcClientData{}
+
+	clientId, ok := utils.ParseObjectId(c.Param("client_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	client, err := oidc.Get(db, clientId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !oidcClientAllowed(access, adminrole.ServicesWrite,
+		client.MatchRoles, client.Roles, false) ||
+		!oidcClientAllowed(access, adminrole.ServicesWrite,
+			data.MatchRoles, data.Roles, true) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	client.Name = data.Name
+	client.RedirectUris = data.RedirectUris
+	client.MatchRoles = data.MatchRoles
+	client.Roles = data.Roles
+
+	fields := set.NewSet(
+		"name",
+		"redirect_uris",
+		"match_roles",
+		"roles",
+	)
+
+	if data.GenerateSecret {
+		err = client.GenerateSecret()
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		fields.Add("secret_hash")
+	}
+
+	errData, err := client.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = client.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "oidc_client.change")
+
+	c.JSON(200, client)
+}
+
+func oidcClientPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &oidcClientData{
+		Name: "New Client",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !oidcClientAllowed(access, adminrole.ServicesWrite,
+		data.MatchRoles, data.Roles, true) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	client := &oidc.Client{
+		Name:         data.Name,
+		RedirectUris: data.RedirectUris,
+		MatchRoles:   data.MatchRoles,
+		Roles:        data.Roles,
+	}
+
+	errData, err := client.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = client.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "oidc_client.change")
+
+	c.JSON(200, client)
+}
+
+func oidcClientDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	clientId, ok := utils.ParseObjectId(c.Param("client_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	client, err := oidc.Get(db, clientId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !oidcClientAllowed(access, adminrole.ServicesWrite,
+		client.MatchRoles, client.Roles, false) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	err = oidc.Remove(db, client.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "oidc_client.change")
+
+	c.JSON(200, nil)
+}
+
+func oidcClientsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	clients, err := oidc.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Unscoped(adminrole.ServicesRead) {
+		allowed := []*oidc.Client{}
+		for _, client := range clients {
+			if oidcClientAllowed(access, adminrole.ServicesRead,
+				client.MatchRoles, client.Roles, false) {
+
+				allowed = append(allowed, client)
+			}
+		}
+		clients = allowed
+	}
+
+	c.JSON(200, clients)
+}
@@ -0,0 +1,239 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/cookie"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/node"
+	"github.com/pritunl/pritunl-zero/passkey"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/secondary"
+	"github.com/pritunl/pritunl-zero/session"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/pritunl/pritunl-zero/validator"
+)
+
+type passkeyRequestData struct {
+	Token   string      `json:"token"`
+	Options interface{} `json:"options"`
+}
+
+type passkeyRespondData struct {
+	Token string `json:"token"`
+}
+
+// Reject password and single sign-on logins when a policy requires passkeys
+func passkeyEnforce(c *gin.Context, db *database.Database,
+	usr *user.User, method string) (blocked bool) {
+
+	required, err := validator.Passkey(db, usr,
+		func(polcy *policy.Policy) bool {
+			return polcy.AdminPasskey
+		})
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	if !required {
+		return
+	}
+
+	errData := &errortypes.ErrorData{
+		Error: "passkey_required",
+		Message: "Passkey login is required, register a passkey " +
+			"from the user portal",
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.AdminLoginFailed,
+		audit.Fields{
+			"method":  method,
+			"error":   errData.Error,
+			"message": errData.Message,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	c.JSON(401, errData)
+	blocked = true
+
+	return
+}
+
+func authPasskeyRequestGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	if node.Self.WebauthnDomain == "" {
+		errData := &errortypes.ErrorData{
+			Error:   "webauthn_domain_unavailable",
+			Message: "WebAuthn domain must be configured",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	token, resp, err := passkey.LoginRequest(
+		db, utils.GetOrigin(c.Request))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, &passkeyRequestData{
+		Token:   token,
+		Options: resp,
+	})
+}
+
+func authPasskeyRespondPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	data := &passkeyRespondData{}
+
+	body, err := utils.CopyBody(c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, devc, errData, err := passkey.LoginResponse(
+		db, utils.GetOrigin(c.Request), data.Token, body)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			errData := &errortypes.ErrorData{
+				Error:   "passkey_expired",
+				Message: "Passkey authentication has expired",
+			}
+			c.JSON(401, errData)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if errData != nil {
+		if usr != nil {
+			err = audit.New(
+				db,
+				c.Request,
+				usr.Id,
+				audit.AdminPasskeyLoginFailed,
+				audit.Fields{
+					"method":  "passkey",
+					"error":   errData.Error,
+					"message": errData.Message,
+				},
+			)
+			if err != nil {
+				utils.AbortWithError(c, 500, err)
+				return
+			}
+		}
+
+		c.JSON(401, errData)
+		return
+	}
+
+	_, secProviderId, errAudit, errData, err := validator.ValidateAdmin(
+		db, usr, false, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		if errAudit == nil {
+			errAudit = audit.Fields{
+				"error":   errData.Error,
+				"message": errData.Message,
+			}
+		}
+		errAudit["method"] = "passkey"
+		errAudit["device_id"] = devc.Id.Hex()
+
+		err = audit.New(
+			db,
+			c.Request,
+			usr.Id,
+			audit.AdminPasskeyLoginFailed,
+			errAudit,
+		)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		c.JSON(401, errData)
+		return
+	}
+
+	// Passkey verification already satisfies device step-up
+	_, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.AdminLoginFailed, "passkey")
+	if blocked {
+		return
+	}
+
+	if !secProviderId.IsZero() {
+		secd, err := secondary.New(db, usr.Id, secondary.Admin, secProviderId)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		data, err := secd.GetData()
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		c.JSON(201, data)
+		return
+	}
+
+	loginAudit["device_id"] = devc.Id.Hex()
+	loginAudit["device_name"] = devc.Name
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.AdminPasskeyLogin,
+		loginAudit,
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	cook := cookie.NewAdmin(c.Writer, c.Request)
+
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Admin, true)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.Status(200)
+}
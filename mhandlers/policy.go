@@ -5,11 +5,14 @@ import (
 	"github.com/dropbox/godropbox/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/demo"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/event"
 	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/revision"
+	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/utils"
 )
 
@@ -30,6 +33,43 @@ type policyData struct {
 	ProxyDeviceSecondary      bool                    `json:"proxy_device_secondary"`
 	AuthorityDeviceSecondary  bool                    `json:"authority_device_secondary"`
 	AuthorityRequireSmartCard bool                    `json:"authority_require_smart_card"`
+	AdminPasskey              bool                    `json:"admin_passkey"`
+	UserPasskey               bool                    `json:"user_passkey"`
+	ProxyPasskey              bool                    `json:"proxy_passkey"`
+	SessionExpire             int                     `json:"session_expire"`
+	SessionMaxDuration        int                     `json:"session_max_duration"`
+	ReauthPrimary             int                     `json:"reauth_primary"`
+	ReauthSecondary           int                     `json:"reauth_secondary"`
+}
+
+// Check if all services are within the policy write scope
+func policyServicesAllowed(db *database.Database, access *adminrole.Access,
+	serviceIds []primitive.ObjectID) (allowed bool, err error) {
+
+	if access.Unscoped(adminrole.PoliciesWrite) {
+		allowed = true
+		return
+	}
+
+	serviceIdsSet := set.NewSet()
+	for _, serviceId := range serviceIds {
+		serviceIdsSet.Add(serviceId)
+	}
+
+	services, err := service.GetMulti(db, serviceIds)
+	if err != nil {
+		return
+	}
+
+	for _, srvc := range services {
+		if !access.AllowedRoles(adminrole.PoliciesWrite, srvc.Roles) {
+			return
+		}
+		serviceIdsSet.Remove(srvc.Id)
+	}
+
+	allowed = serviceIdsSet.Len() == 0
+	return
 }
 
 func policyPut(c *gin.Context) {
@@ -61,6 +101,27 @@ func policyPut(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRoles(adminrole.PoliciesWrite, polcy.Roles) ||
+		!access.AllowedRolesAll(adminrole.PoliciesWrite, data.Roles) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	allowed, err := policyServicesAllowed(db, access, data.Services)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !allowed {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	prev := revisionSnapshot(polcy)
+
 	polcy.Name = data.Name
 	polcy.Disabled = data.Disabled
 	polcy.Services = data.Services
@@ -76,6 +137,13 @@ func policyPut(c *gin.Context) {
 	polcy.ProxyDeviceSecondary = data.ProxyDeviceSecondary
 	polcy.AuthorityDeviceSecondary = data.AuthorityDeviceSecondary
 	polcy.AuthorityRequireSmartCard = data.AuthorityRequireSmartCard
+	polcy.AdminPasskey = data.AdminPasskey
+	polcy.UserPasskey = data.UserPasskey
+	polcy.ProxyPasskey = data.ProxyPasskey
+	polcy.SessionExpire = data.SessionExpire
+	polcy.SessionMaxDuration = data.SessionMaxDuration
+	polcy.ReauthPrimary = data.ReauthPrimary
+	polcy.ReauthSecondary = data.ReauthSecondary
 
 	fields := set.NewSet(
 		"name",
@@ -93,6 +161,13 @@ func policyPut(c *gin.Context) {
 		"proxy_device_secondary",
 		"authority_device_secondary",
 		"authority_require_smart_card",
+		"admin_passkey",
+		"user_passkey",
+		"proxy_passkey",
+		"session_expire",
+		"session_max_duration",
+		"reauth_primary",
+		"reauth_secondary",
 	)
 
 	errData, err := polcy.Validate(db)
@@ -112,6 +187,9 @@ func policyPut(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Policy, revision.Update,
+		polcy.Id, prev, polcy)
+
 	_ = event.PublishDispatch(db, "policy.change")
 
 	c.JSON(200, polcy)
@@ -136,6 +214,23 @@ func policyPost(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRolesAll(adminrole.PoliciesWrite, data.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	allowed, err := policyServicesAllowed(db, access, data.Services)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !allowed {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
 	polcy := &policy.Policy{
 		Name:                     data.Name,
 		Disabled:                 data.Disabled,
@@ -151,6 +246,13 @@ func policyPost(c *gin.Context) {
 		UserDeviceSecondary:      data.UserDeviceSecondary,
 		ProxyDeviceSecondary:     data.ProxyDeviceSecondary,
 		AuthorityDeviceSecondary: data.AuthorityDeviceSecondary,
+		AdminPasskey:             data.AdminPasskey,
+		UserPasskey:              data.UserPasskey,
+		ProxyPasskey:             data.ProxyPasskey,
+		SessionExpire:            data.SessionExpire,
+		SessionMaxDuration:       data.SessionMaxDuration,
+		ReauthPrimary:            data.ReauthPrimary,
+		ReauthSecondary:          data.ReauthSecondary,
 	}
 
 	errData, err := polcy.Validate(db)
@@ -170,6 +272,9 @@ func policyPost(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Policy, revision.Create,
+		polcy.Id, nil, polcy)
+
 	_ = event.PublishDispatch(db, "policy.change")
 
 	c.JSON(200, polcy)
@@ -188,12 +293,27 @@ func policyDelete(c *gin.Context) {
 		return
 	}
 
-	err := policy.Remove(db, polcyId)
+	polcy, err := policy.Get(db, polcyId)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRoles(adminrole.PoliciesWrite, polcy.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	err = policy.Remove(db, polcyId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	revisionRecord(c, db, revision.Policy, revision.Delete,
+		polcy.Id, polcy, nil)
+
 	_ = event.PublishDispatch(db, "policy.change")
 
 	c.JSON(200, nil)
@@ -214,6 +334,12 @@ func policyGet(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRoles(adminrole.PoliciesRead, polcy.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
 	c.JSON(200, polcy)
 }
 
@@ -226,5 +352,18 @@ func policiesGet(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Unscoped(adminrole.PoliciesRead) {
+		scopedPolicies := []*policy.Policy{}
+
+		for _, polcy := range policies {
+			if access.AllowedRoles(adminrole.PoliciesRead, polcy.Roles) {
+				scopedPolicies = append(scopedPolicies, polcy)
+			}
+		}
+
+		policies = scopedPolicies
+	}
+
 	c.JSON(200, policies)
 }
@@ -0,0 +1,66 @@
+package mhandlers
+
+import (
+	"crypto/subtle"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/check"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/prometheus"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+func prometheusMetricsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	token := settings.Prometheus.Token
+	if token == "" {
+		utils.AbortWithStatus(c, 404)
+		return
+	}
+
+	if subtle.ConstantTimeCompare(
+		[]byte(c.GetHeader("Authorization")),
+		[]byte("Bearer "+token),
+	) != 1 {
+		utils.AbortWithStatus(c, 401)
+		return
+	}
+
+	endpts, err := endpoint.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	targets := []*prometheus.Target{}
+	for _, endpt := range endpts {
+		targets = append(targets, &prometheus.Target{
+			Id:    endpt.Id,
+			Name:  endpt.Name,
+			Roles: endpt.Roles,
+		})
+	}
+
+	checks, err := check.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	checkNames := map[primitive.ObjectID]string{}
+	for _, chck := range checks {
+		checkNames[chck.Id] = chck.Name
+	}
+
+	data, err := prometheus.Export(db, targets, checkNames)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.Data(200, "text/plain; version=0.0.4; charset=utf-8", data)
+}
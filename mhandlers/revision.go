@@ -0,0 +1,293 @@
+package mhandlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/revision"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/sirupsen/logrus"
+)
+
+const revisionsLimit = 200
+
+type revisionCompareData struct {
+	From    *revision.Revision `json:"from"`
+	To      *revision.Revision `json:"to"`
+	Changes []*revision.Change `json:"changes"`
+}
+
+// Record revision after a change has been committed, failures are logged
+// without failing the request
+func revisionRecord(c *gin.Context, db *database.Database,
+	resource, action string, resourceId primitive.ObjectID,
+	prev, cur interface{}) {
+
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err == nil {
+		_, err = revision.Record(db, usr, resource, action,
+			resourceId, prev, cur)
+	}
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"resource":    resource,
+			"resource_id": resourceId.Hex(),
+			"action":      action,
+			"error":       err,
+		}).Error("mhandlers: Failed to record revision")
+	}
+}
+
+func revisionSnapshot(obj interface{}) (prev interface{}) {
+	prev, err := revision.Snapshot(obj)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"error": err,
+		}).Error("mhandlers: Failed to snapshot revision")
+		prev = nil
+	}
+	return
+}
+
+func revisionAllowed(c *gin.Context, resource, perm string,
+	roles []string) bool {
+
+	if resource == revision.Authority {
+		return true
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	return access.AllowedRoles(perm, roles)
+}
+
+func revisionsGet(c *gin.Context, resource, param, perm string) {
+	db := c.MustGet("db").(*database.Database)
+
+	resourceId, ok := utils.ParseObjectId(c.Param(param))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	revs, err := revision.GetAll(db, resource, resourceId, revisionsLimit)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	allowedRevs := []*revision.Revision{}
+	for _, rev := range revs {
+		if revisionAllowed(c, resource, perm, rev.Roles) {
+			rev.Json(false)
+			allowedRevs = append(allowedRevs, rev)
+		}
+	}
+
+	c.JSON(200, allowedRevs)
+}
+
+func revisionGet(c *gin.Context, resource, param, perm string) {
+	db := c.MustGet("db").(*database.Database)
+
+	resourceId, ok := utils.ParseObjectId(c.Param(param))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	revId, ok := utils.ParseObjectId(c.Param("revision_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	rev, err := revision.GetResource(db, resource, resourceId, revId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !revisionAllowed(c, resource, perm, rev.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	rev.Json(true)
+
+	c.JSON(200, rev)
+}
+
+func revisionCompareGet(c *gin.Context, resource, param, perm string) {
+	db := c.MustGet("db").(*database.Database)
+
+	resourceId, ok := utils.ParseObjectId(c.Param(param))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	revId, ok := utils.ParseObjectId(c.Param("revision_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	otherId, ok := utils.ParseObjectId(c.Param("other_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	from, err := revision.GetResource(db, resource, resourceId, revId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	to, err := revision.GetResource(db, resource, resourceId, otherId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !revisionAllowed(c, resource, perm, from.Roles) ||
+		!revisionAllowed(c, resource, perm, to.Roles) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	data := &revisionCompareData{
+		From:    from,
+		To:      to,
+		Changes: revision.Diff(from.Data, to.Data),
+	}
+
+	from.Json(false)
+	to.Json(false)
+	revision.Redact(data.Changes)
+
+	c.JSON(200, data)
+}
+
+func revisionRollbackPost(c *gin.Context, resource, param, perm string) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	resourceId, ok := utils.ParseObjectId(c.Param(param))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	revId, ok := utils.ParseObjectId(c.Param("revision_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	rev, err := revision.GetResource(db, resource, resourceId, revId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	last, err := revision.Latest(db, resource, resourceId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !revisionAllowed(c, resource, perm, rev.Roles) ||
+		(last != nil && !revisionAllowed(c, resource, perm, last.Roles)) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	newRev, errData, err := rev.Rollback(db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	newRev.Json(false)
+
+	c.JSON(200, newRev)
+}
+
+func policyRevisionsGet(c *gin.Context) {
+	revisionsGet(c, revision.Policy, "policy_id", adminrole.PoliciesRead)
+}
+
+func policyRevisionGet(c *gin.Context) {
+	revisionGet(c, revision.Policy, "policy_id", adminrole.PoliciesRead)
+}
+
+func policyRevisionCompareGet(c *gin.Context) {
+	revisionCompareGet(c, revision.Policy, "policy_id",
+		adminrole.PoliciesRead)
+}
+
+func policyRevisionRollbackPost(c *gin.Context) {
+	revisionRollbackPost(c, revision.Policy, "policy_id",
+		adminrole.PoliciesWrite)
+}
+
+func serviceRevisionsGet(c *gin.Context) {
+	revisionsGet(c, revision.Service, "service_id", adminrole.ServicesRead)
+}
+
+func serviceRevisionGet(c *gin.Context) {
+	revisionGet(c, revision.Service, "service_id", adminrole.ServicesRead)
+}
+
+func serviceRevisionCompareGet(c *gin.Context) {
+	revisionCompareGet(c, revision.Service, "service_id",
+		adminrole.ServicesRead)
+}
+
+func serviceRevisionRollbackPost(c *gin.Context) {
+	revisionRollbackPost(c, revision.Service, "service_id",
+		adminrole.ServicesWrite)
+}
+
+func authorityRevisionsGet(c *gin.Context) {
+	revisionsGet(c, revision.Authority, "authr_id",
+		adminrole.AuthoritiesRead)
+}
+
+func authorityRevisionGet(c *gin.Context) {
+	revisionGet(c, revision.Authority, "authr_id",
+		adminrole.AuthoritiesRead)
+}
+
+func authorityRevisionCompareGet(c *gin.Context) {
+	revisionCompareGet(c, revision.Authority, "authr_id",
+		adminrole.AuthoritiesRead)
+}
+
+func authorityRevisionRollbackPost(c *gin.Context) {
+	revisionRollbackPost(c, revision.Authority, "authr_id",
+		adminrole.AuthoritiesWrite)
+}
@@ -11,10 +11,12 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/bson"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/demo"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/revision"
 	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/utils"
 )
@@ -33,6 +35,7 @@ type serviceData struct {
 	Servers           []*service.Server        `json:"servers"`
 	WhitelistNetworks []string                 `json:"whitelist_networks"`
 	WhitelistPaths    []*service.WhitelistPath `json:"whitelist_paths"`
+	Sensitivity       string                   `json:"sensitivity"`
 }
 
 type servicesData struct {
@@ -69,6 +72,16 @@ func servicePut(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRoles(adminrole.ServicesWrite, srvce.Roles) ||
+		!access.AllowedRolesAll(adminrole.ServicesWrite, data.Roles) {
+
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	prev := revisionSnapshot(srvce)
+
 	srvce.Name = data.Name
 	srvce.Type = data.Type
 	srvce.ShareSession = data.ShareSession
@@ -81,6 +94,7 @@ func servicePut(c *gin.Context) {
 	srvce.Servers = data.Servers
 	srvce.WhitelistNetworks = data.WhitelistNetworks
 	srvce.WhitelistPaths = data.WhitelistPaths
+	srvce.Sensitivity = data.Sensitivity
 
 	fields := set.NewSet(
 		"name",
@@ -95,6 +109,7 @@ func servicePut(c *gin.Context) {
 		"servers",
 		"whitelist_networks",
 		"whitelist_paths",
+		"sensitivity",
 	)
 
 	errData, err := srvce.Validate(db)
@@ -114,6 +129,9 @@ func servicePut(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Service, revision.Update,
+		srvce.Id, prev, srvce)
+
 	_ = event.PublishDispatch(db, "service.change")
 
 	c.JSON(200, srvce)
@@ -139,6 +157,12 @@ func servicePost(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRolesAll(adminrole.ServicesWrite, data.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
 	srvce := &service.Service{
 		Name:              data.Name,
 		Type:              data.Type,
@@ -152,6 +176,7 @@ func servicePost(c *gin.Context) {
 		Servers:           data.Servers,
 		WhitelistNetworks: data.WhitelistNetworks,
 		WhitelistPaths:    data.WhitelistPaths,
+		Sensitivity:       data.Sensitivity,
 	}
 
 	errData, err := srvce.Validate(db)
@@ -171,6 +196,9 @@ func servicePost(c *gin.Context) {
 		return
 	}
 
+	revisionRecord(c, db, revision.Service, revision.Create,
+		srvce.Id, nil, srvce)
+
 	_ = event.PublishDispatch(db, "service.change")
 
 	c.JSON(200, srvce)
@@ -189,12 +217,27 @@ func serviceDelete(c *gin.Context) {
 		return
 	}
 
-	err := service.Remove(db, serviceId)
+	srvce, err := service.Get(db, serviceId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.AllowedRoles(adminrole.ServicesWrite, srvce.Roles) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	err = service.Remove(db, serviceId)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
 	}
 
+	revisionRecord(c, db, revision.Service, revision.Delete,
+		srvce.Id, srvce, nil)
+
 	_ = event.PublishDispatch(db, "service.change")
 
 	c.JSON(200, nil)
@@ -214,12 +257,33 @@ func servicesDelete(c *gin.Context) {
 		return
 	}
 
+	services, err := service.GetMulti(db, dta)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Unscoped(adminrole.ServicesWrite) {
+		for _, srvce := range services {
+			if !access.AllowedRoles(adminrole.ServicesWrite, srvce.Roles) {
+				utils.AbortWithStatus(c, 403)
+				return
+			}
+		}
+	}
+
 	err = service.RemoveMulti(db, dta)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
 	}
 
+	for _, srvce := range services {
+		revisionRecord(c, db, revision.Service, revision.Delete,
+			srvce.Id, srvce, nil)
+	}
+
 	_ = event.PublishDispatch(db, "service.change")
 
 	c.JSON(200, nil)
@@ -228,9 +292,20 @@ func servicesDelete(c *gin.Context) {
 func servicesGet(c *gin.Context) {
 	db := c.MustGet("db").(*database.Database)
 
+	access := c.MustGet("access").(*adminrole.Access)
+	scopes := access.Scopes(adminrole.ServicesRead)
+
 	serviceNames := c.Query("service_names")
 	if serviceNames == "true" {
-		insts, err := service.GetAllName(db)
+		query := bson.M{}
+
+		if scopes != nil {
+			query["roles"] = &bson.M{
+				"$in": scopes,
+			}
+		}
+
+		insts, err := service.GetAllName(db, &query)
 		if err != nil {
 			utils.AbortWithError(c, 500, err)
 			return
@@ -271,6 +346,12 @@ func servicesGet(c *gin.Context) {
 			query["organization"] = organization
 		}
 
+		if scopes != nil {
+			query["roles"] = &bson.M{
+				"$in": scopes,
+			}
+		}
+
 		services, count, err := service.GetAllPaged(
 			db, &query, page, pageCount)
 		if err != nil {
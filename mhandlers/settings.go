@@ -1,16 +1,20 @@
 package mhandlers
 
 import (
+	"strings"
+
 	"github.com/dropbox/godropbox/container/set"
 	"github.com/dropbox/godropbox/errors"
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/endpoints"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/event"
 	"github.com/pritunl/pritunl-zero/secondary"
 	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/sla"
 	"github.com/pritunl/pritunl-zero/utils"
 )
 
@@ -23,6 +27,25 @@ type settingsData struct {
 	AuthProxyMaxDuration   int                           `json:"auth_proxy_max_duration"`
 	AuthUserExpire         int                           `json:"auth_user_expire"`
 	AuthUserMaxDuration    int                           `json:"auth_user_max_duration"`
+	AccessRequestRoles     []string                      `json:"access_request_roles"`
+	AccessApproverRoles    []string                      `json:"access_approver_roles"`
+	AccessMaxDuration      int                           `json:"access_max_duration"`
+	NetlistTorSource       string                        `json:"netlist_tor_source"`
+	NetlistVpnSource       string                        `json:"netlist_vpn_source"`
+	NetlistHostingSource   string                        `json:"netlist_hosting_source"`
+	SmtpHost               string                        `json:"smtp_host"`
+	SmtpPort               int                           `json:"smtp_port"`
+	SmtpUsername           string                        `json:"smtp_username"`
+	SmtpPassword           string                        `json:"smtp_password"`
+	SmtpFrom               string                        `json:"smtp_from"`
+	SlaSchedule            string                        `json:"sla_schedule"`
+	SlaRecipients          []string                      `json:"sla_recipients"`
+	EndpointRawRetention   int                           `json:"endpoint_raw_retention"`
+	EndpointTiers          []*settings.RetentionTier     `json:"endpoint_tiers"`
+	PrometheusToken        string                        `json:"prometheus_token"`
+	PrometheusRemoteWrite  string                        `json:"prometheus_remote_write"`
+	PrometheusRemoteUser   string                        `json:"prometheus_remote_username"`
+	PrometheusRemotePass   string                        `json:"prometheus_remote_password"`
 	ElasticAddress         string                        `json:"elastic_address"`
 	ElasticUsername        string                        `json:"elastic_username"`
 	ElasticPassword        string                        `json:"elastic_password"`
@@ -39,6 +62,25 @@ func getSettingsData() *settingsData {
 		AuthProxyMaxDuration:   settings.Auth.ProxyMaxDuration,
 		AuthUserExpire:         settings.Auth.UserExpire,
 		AuthUserMaxDuration:    settings.Auth.UserMaxDuration,
+		AccessRequestRoles:     settings.Access.RequestRoles,
+		AccessApproverRoles:    settings.Access.ApproverRoles,
+		AccessMaxDuration:      settings.Access.MaxDuration,
+		NetlistTorSource:       settings.Netlist.TorSource,
+		NetlistVpnSource:       settings.Netlist.VpnSource,
+		NetlistHostingSource:   settings.Netlist.HostingSource,
+		SmtpHost:               settings.Smtp.Host,
+		SmtpPort:               settings.Smtp.Port,
+		SmtpUsername:           settings.Smtp.Username,
+		SmtpPassword:           settings.Smtp.Password,
+		SmtpFrom:               settings.Smtp.From,
+		SlaSchedule:            settings.Sla.Schedule,
+		SlaRecipients:          settings.Sla.Recipients,
+		EndpointRawRetention:   settings.Endpoint.RawRetention,
+		EndpointTiers:          settings.Endpoint.Tiers,
+		PrometheusToken:        settings.Prometheus.Token,
+		PrometheusRemoteWrite:  settings.Prometheus.RemoteWriteUrl,
+		PrometheusRemoteUser:   settings.Prometheus.RemoteWriteUsername,
+		PrometheusRemotePass:   settings.Prometheus.RemoteWritePassword,
 		ElasticUsername:        settings.Elastic.Username,
 		ElasticPassword:        settings.Elastic.Password,
 		ElasticProxyRequests:   settings.Elastic.ProxyRequests,
@@ -114,6 +156,121 @@ func settingsPut(c *gin.Context) {
 		}
 	}
 
+	if data.AccessRequestRoles == nil {
+		data.AccessRequestRoles = []string{}
+	}
+	if data.AccessApproverRoles == nil {
+		data.AccessApproverRoles = []string{}
+	}
+	if data.AccessMaxDuration < 1 {
+		data.AccessMaxDuration = 1
+	}
+
+	settings.Access.RequestRoles = data.AccessRequestRoles
+	settings.Access.ApproverRoles = data.AccessApproverRoles
+	settings.Access.MaxDuration = data.AccessMaxDuration
+
+	err = settings.Commit(db, settings.Access, set.NewSet(
+		"request_roles",
+		"approver_roles",
+		"max_duration",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	settings.Netlist.TorSource = strings.TrimSpace(data.NetlistTorSource)
+	settings.Netlist.VpnSource = strings.TrimSpace(data.NetlistVpnSource)
+	settings.Netlist.HostingSource = strings.TrimSpace(
+		data.NetlistHostingSource)
+
+	err = settings.Commit(db, settings.Netlist, set.NewSet(
+		"tor_source",
+		"vpn_source",
+		"hosting_source",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	settings.Smtp.Host = strings.TrimSpace(data.SmtpHost)
+	settings.Smtp.Port = data.SmtpPort
+	settings.Smtp.Username = data.SmtpUsername
+	settings.Smtp.Password = data.SmtpPassword
+	settings.Smtp.From = strings.TrimSpace(data.SmtpFrom)
+
+	if settings.Smtp.Port == 0 {
+		settings.Smtp.Port = 587
+	}
+
+	err = settings.Commit(db, settings.Smtp, set.NewSet(
+		"host",
+		"port",
+		"username",
+		"password",
+		"from",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	switch data.SlaSchedule {
+	case sla.Weekly, sla.Monthly:
+		settings.Sla.Schedule = data.SlaSchedule
+		break
+	default:
+		settings.Sla.Schedule = ""
+	}
+	settings.Sla.Recipients = data.SlaRecipients
+
+	if settings.Sla.Recipients == nil {
+		settings.Sla.Recipients = []string{}
+	}
+
+	err = settings.Commit(db, settings.Sla, set.NewSet(
+		"schedule",
+		"recipients",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	settings.Endpoint.RawRetention = data.EndpointRawRetention
+	if settings.Endpoint.RawRetention < 0 {
+		settings.Endpoint.RawRetention = 0
+	}
+	settings.Endpoint.Tiers = endpoints.FilterTiers(data.EndpointTiers)
+
+	err = settings.Commit(db, settings.Endpoint, set.NewSet(
+		"raw_retention",
+		"tiers",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	settings.Prometheus.Token = strings.TrimSpace(data.PrometheusToken)
+	settings.Prometheus.RemoteWriteUrl = strings.TrimSpace(
+		data.PrometheusRemoteWrite)
+	settings.Prometheus.RemoteWriteUsername = data.PrometheusRemoteUser
+	settings.Prometheus.RemoteWritePassword = data.PrometheusRemotePass
+
+	err = settings.Commit(db, settings.Prometheus, set.NewSet(
+		"token",
+		"remote_write_url",
+		"remote_write_username",
+		"remote_write_password",
+	))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
 	fields = set.NewSet(
 		"providers",
 		"secondary_providers",
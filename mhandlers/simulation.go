@@ -0,0 +1,165 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/authority"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/simulation"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type simulationData struct {
+	User      primitive.ObjectID  `json:"user"`
+	Roles     []string            `json:"roles"`
+	Service   primitive.ObjectID  `json:"service"`
+	Authority primitive.ObjectID  `json:"authority"`
+	Context   *simulation.Context `json:"context"`
+}
+
+func simulationUser(c *gin.Context, db *database.Database,
+	userId primitive.ObjectID, roles []string) (usr *user.User,
+	ok bool, err error) {
+
+	access := c.MustGet("access").(*adminrole.Access)
+
+	if userId.IsZero() {
+		if roles == nil {
+			roles = []string{}
+		}
+
+		usr = &user.User{
+			Username: "simulation",
+			Roles:    roles,
+		}
+
+		ok = access.AllowedRolesAll(adminrole.PoliciesRead, usr.Roles)
+		return
+	}
+
+	usr, err = user.Get(db, userId)
+	if err != nil {
+		return
+	}
+
+	ok = access.AllowedRoles(adminrole.UsersRead, usr.Roles)
+
+	return
+}
+
+func policySimulatePost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	data := &simulationData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if data.Context == nil {
+		data.Context = &simulation.Context{}
+	}
+
+	usr, ok, err := simulationUser(c, db, data.User, data.Roles)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	var result *simulation.Result
+	if !data.Service.IsZero() {
+		srvc, e := service.Get(db, data.Service)
+		if e != nil {
+			utils.AbortWithError(c, 500, e)
+			return
+		}
+
+		result, err = simulation.EvaluateService(db, usr, srvc, data.Context)
+	} else if !data.Authority.IsZero() {
+		authr, e := authority.Get(db, data.Authority)
+		if e != nil {
+			utils.AbortWithError(c, 500, e)
+			return
+		}
+
+		result, err = simulation.EvaluateAuthority(
+			db, usr, authr, data.Context)
+	} else {
+		errData := &errortypes.ErrorData{
+			Error:   "simulation_target_missing",
+			Message: "Service or authority is required",
+		}
+		c.JSON(400, errData)
+		return
+	}
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, result)
+}
+
+func userAccessGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	userId, ok := utils.ParseObjectId(c.Param("user_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	usr, ok, err := simulationUser(c, db, userId, nil)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !ok {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	ctx := &simulation.Context{
+		Ip:                     c.Query("ip"),
+		OperatingSystem:        c.Query("operating_system"),
+		Browser:                c.Query("browser"),
+		Platform:               c.Query("platform"),
+		OperatingSystemVersion: c.Query("operating_system_version"),
+		BrowserVersion:         c.Query("browser_version"),
+		CountryCode:            c.Query("country_code"),
+		RegionCode:             c.Query("region_code"),
+	}
+
+	results, err := simulation.Reachable(db, usr, ctx)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if c.Query("allowed") == "true" {
+		allowed := []*simulation.Result{}
+		for _, result := range results {
+			if result.Decision == simulation.Allow {
+				allowed = append(allowed, result)
+			}
+		}
+		results = allowed
+	}
+
+	c.JSON(200, results)
+}
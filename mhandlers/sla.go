@@ -0,0 +1,81 @@
+package mhandlers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/sla"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+func slaReportRespond(c *gin.Context, report *sla.Report) {
+	if c.Query("format") != "csv" {
+		c.JSON(200, report)
+		return
+	}
+
+	data, err := report.Csv()
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(
+		"attachment; filename=\"sla-%s-%s.csv\"",
+		report.Resource,
+		report.Start.Format("2006-01-02"),
+	))
+	c.Data(200, "text/csv; charset=utf-8", data)
+}
+
+func checkReportGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	checkId, ok := utils.ParseObjectId(c.Param("check_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	start, end, errData := sla.ParseRange(c.Query("range"),
+		c.Query("start"), c.Query("end"), time.Now().UTC())
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	report, err := sla.GetCheck(db, checkId, start, end)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	slaReportRespond(c, report)
+}
+
+func endpointReportGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	endpointId, ok := utils.ParseObjectId(c.Param("endpoint_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	start, end, errData := sla.ParseRange(c.Query("range"),
+		c.Query("start"), c.Query("end"), time.Now().UTC())
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	report, err := sla.GetEndpoint(db, endpointId, start, end)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	slaReportRespond(c, report)
+}
@@ -0,0 +1,338 @@
+package mhandlers
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/statuspage"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type statusPageData struct {
+	Id         primitive.ObjectID      `json:"id"`
+	Name       string                  `json:"name"`
+	Title      string                  `json:"title"`
+	Domain     string                  `json:"domain"`
+	Service    primitive.ObjectID      `json:"service"`
+	Components []*statuspage.Component `json:"components"`
+}
+
+type statusPostData struct {
+	Id    primitive.ObjectID `json:"id"`
+	Page  primitive.ObjectID `json:"page"`
+	Title string             `json:"title"`
+	Body  string             `json:"body"`
+	State string             `json:"state"`
+}
+
+func statusPagePut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &statusPageData{}
+
+	pageId, ok := utils.ParseObjectId(c.Param("page_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	page, err := statuspage.Get(db, pageId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	page.Name = data.Name
+	page.Title = data.Title
+	page.Domain = data.Domain
+	page.Service = data.Service
+	page.Components = data.Components
+
+	fields := set.NewSet(
+		"name",
+		"title",
+		"domain",
+		"service",
+		"components",
+	)
+
+	errData, err := page.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = page.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_page.change")
+
+	c.JSON(200, page)
+}
+
+func statusPagePost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &statusPageData{
+		Name: "New Status Page",
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	page := &statuspage.StatusPage{
+		Name:       data.Name,
+		Title:      data.Title,
+		Domain:     data.Domain,
+		Service:    data.Service,
+		Components: data.Components,
+	}
+
+	errData, err := page.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = page.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_page.change")
+
+	c.JSON(200, page)
+}
+
+func statusPageDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	pageId, ok := utils.ParseObjectId(c.Param("page_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := statuspage.Remove(db, pageId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_page.change")
+	_ = event.PublishDispatch(db, "status_post.change")
+
+	c.JSON(200, nil)
+}
+
+func statusPagesGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	pages, err := statuspage.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, pages)
+}
+
+func statusPostPut(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &statusPostData{}
+
+	postId, ok := utils.ParseObjectId(c.Param("post_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	post, err := statuspage.GetPost(db, postId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	post.Title = data.Title
+	post.Body = data.Body
+	post.State = data.State
+
+	fields := set.NewSet(
+		"title",
+		"body",
+		"state",
+		"updated",
+	)
+
+	errData, err := post.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = post.CommitFields(db, fields)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_post.change")
+
+	c.JSON(200, post)
+}
+
+func statusPostPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	data := &statusPostData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_, err = statuspage.Get(db, data.Page)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	post := &statuspage.Post{
+		Page:  data.Page,
+		Title: data.Title,
+		Body:  data.Body,
+		State: data.State,
+	}
+
+	errData, err := post.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = post.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_post.change")
+
+	c.JSON(200, post)
+}
+
+func statusPostDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+
+	postId, ok := utils.ParseObjectId(c.Param("post_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := statuspage.RemovePost(db, postId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "status_post.change")
+
+	c.JSON(200, nil)
+}
+
+func statusPostsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	pageId, ok := utils.ParseObjectId(c.Query("page"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	posts, err := statuspage.GetPosts(db, pageId, 100)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, posts)
+}
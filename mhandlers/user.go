@@ -12,6 +12,8 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/bson"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/authorizer"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/demo"
 	"github.com/pritunl/pritunl-zero/errortypes"
@@ -21,16 +23,17 @@ import (
 )
 
 type userData struct {
-	Id             primitive.ObjectID `json:"id"`
-	Type           string             `json:"type"`
-	Username       string             `json:"username"`
-	Password       string             `json:"password"`
-	Roles          []string           `json:"roles"`
-	Administrator  string             `json:"administrator"`
-	Permissions    []string           `json:"permissions"`
-	GenerateSecret bool               `json:"generate_secret"`
-	Disabled       bool               `json:"disabled"`
-	ActiveUntil    time.Time          `json:"active_until"`
+	Id             primitive.ObjectID   `json:"id"`
+	Type           string               `json:"type"`
+	Username       string               `json:"username"`
+	Password       string               `json:"password"`
+	Roles          []string             `json:"roles"`
+	Administrator  string               `json:"administrator"`
+	AdminRoles     []primitive.ObjectID `json:"admin_roles"`
+	Permissions    []string             `json:"permissions"`
+	GenerateSecret bool                 `json:"generate_secret"`
+	Disabled       bool                 `json:"disabled"`
+	ActiveUntil    time.Time            `json:"active_until"`
 }
 
 type usersData struct {
@@ -38,6 +41,31 @@ type usersData struct {
 	Count int64        `json:"count"`
 }
 
+// Only super administrators can manage other administrators
+func canManageUser(c *gin.Context, db *database.Database,
+	usr *user.User) (ok bool, err error) {
+
+	access := c.MustGet("access").(*adminrole.Access)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	if access.Super || !adminrole.IsAdmin(usr) {
+		ok = true
+		return
+	}
+
+	authrUsr, err := authr.GetUser(db)
+	if err != nil {
+		return
+	}
+
+	if authrUsr != nil && authrUsr.Id == usr.Id {
+		ok = true
+		return
+	}
+
+	return
+}
+
 func userGet(c *gin.Context) {
 	db := c.MustGet("db").(*database.Database)
 
@@ -95,6 +123,43 @@ func userPut(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Super && adminrole.IsAdmin(usr) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	if !access.Allowed(adminrole.UsersWrite) {
+		usr.Disabled = data.Disabled
+		usr.ActiveUntil = data.ActiveUntil
+
+		if usr.Disabled {
+			usr.ActiveUntil = time.Time{}
+		}
+
+		err = usr.CommitFields(db, set.NewSet(
+			"disabled",
+			"active_until",
+		))
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		_ = event.PublishDispatch(db, "user.change")
+
+		usr.Secret = ""
+
+		c.JSON(200, usr)
+		return
+	}
+
+	if !access.Super {
+		data.Administrator = usr.Administrator
+		data.AdminRoles = usr.AdminRoles
+		data.Permissions = usr.Permissions
+	}
+
 	showSecret := false
 	if usr.Type != data.Type {
 		if data.Type == user.Api {
@@ -115,6 +180,7 @@ func userPut(c *gin.Context) {
 	usr.Username = data.Username
 	usr.Roles = data.Roles
 	usr.Administrator = data.Administrator
+	usr.AdminRoles = data.AdminRoles
 	usr.Permissions = data.Permissions
 	usr.Disabled = data.Disabled
 	usr.ActiveUntil = data.ActiveUntil
@@ -140,6 +206,7 @@ func userPut(c *gin.Context) {
 		"username",
 		"roles",
 		"administrator",
+		"admin_roles",
 		"permissions",
 		"disabled",
 		"active_until",
@@ -212,11 +279,19 @@ func userPost(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Super {
+		data.Administrator = ""
+		data.AdminRoles = nil
+		data.Permissions = nil
+	}
+
 	usr := &user.User{
 		Type:          data.Type,
 		Username:      data.Username,
 		Roles:         data.Roles,
 		Administrator: data.Administrator,
+		AdminRoles:    data.AdminRoles,
 		Permissions:   data.Permissions,
 		Disabled:      data.Disabled,
 		ActiveUntil:   data.ActiveUntil,
@@ -355,6 +430,26 @@ func usersDelete(c *gin.Context) {
 		return
 	}
 
+	access := c.MustGet("access").(*adminrole.Access)
+	if !access.Super {
+		users, _, err := user.GetAll(db, &bson.M{
+			"_id": &bson.M{
+				"$in": data,
+			},
+		}, 0, 0)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		for _, usr := range users {
+			if adminrole.IsAdmin(usr) {
+				utils.AbortWithStatus(c, 403)
+				return
+			}
+		}
+	}
+
 	errData, err := user.Remove(db, data)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
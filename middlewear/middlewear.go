@@ -8,6 +8,7 @@ import (
 
 	"github.com/dropbox/godropbox/errors"
 	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/adminrole"
 	"github.com/pritunl/pritunl-zero/audit"
 	"github.com/pritunl/pritunl-zero/auth"
 	"github.com/pritunl/pritunl-zero/authority"
@@ -251,6 +252,46 @@ func AuthAdmin(c *gin.Context) {
 		utils.AbortWithStatus(c, 401)
 		return
 	}
+
+	access, err := adminrole.GetAccess(db, usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.Set("access", access)
+}
+
+func AuthSuper(c *gin.Context) {
+	access := c.MustGet("access").(*adminrole.Access)
+
+	if !access.Super {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+}
+
+func Permission(perms ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		access := c.MustGet("access").(*adminrole.Access)
+
+		for _, perm := range perms {
+			if access.Allowed(perm) {
+				return
+			}
+		}
+
+		utils.AbortWithStatus(c, 403)
+	}
+}
+
+func PermissionAny(c *gin.Context) {
+	access := c.MustGet("access").(*adminrole.Access)
+
+	if !access.Any() {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
 }
 
 func AuthUser(c *gin.Context) {
@@ -0,0 +1,21 @@
+package netlist
+
+import (
+	"time"
+)
+
+const (
+	Tor     = "tor"
+	Vpn     = "vpn"
+	Hosting = "hosting"
+
+	cacheTtl    = 5 * time.Minute
+	maxDownload = 64 * 1024 * 1024
+	chunkSize   = 10000
+)
+
+var Classifications = []string{
+	Tor,
+	Vpn,
+	Hosting,
+}
@@ -0,0 +1,164 @@
+package netlist
+
+import (
+	"bytes"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/database"
+)
+
+var (
+	cache     = map[string]*matcher{}
+	cacheTime time.Time
+	cacheLock = sync.RWMutex{}
+)
+
+// Address range with addresses in 16 byte form, IPv4 addresses are IPv4
+// mapped IPv6 addresses
+type ipRange struct {
+	start [16]byte
+	end   [16]byte
+}
+
+// Sorted table of non-overlapping address ranges
+type matcher struct {
+	ranges []ipRange
+}
+
+func (m *matcher) contains(ip net.IP) bool {
+	ip = ip.To16()
+	if ip == nil {
+		return false
+	}
+
+	var addr [16]byte
+	copy(addr[:], ip)
+
+	i := sort.Search(len(m.ranges), func(i int) bool {
+		return bytes.Compare(m.ranges[i].start[:], addr[:]) > 0
+	})
+	if i == 0 {
+		return false
+	}
+
+	return bytes.Compare(addr[:], m.ranges[i-1].end[:]) <= 0
+}
+
+func newMatcher(networks []string) (m *matcher) {
+	ranges := make([]ipRange, 0, len(networks))
+
+	for _, value := range networks {
+		_, network, err := net.ParseCIDR(value)
+		if err != nil {
+			continue
+		}
+
+		ones, bits := network.Mask.Size()
+		if bits == 32 {
+			ones += 96
+		}
+		mask := net.CIDRMask(ones, 128)
+		ip := network.IP.To16()
+
+		rng := ipRange{}
+		for i := 0; i < 16; i++ {
+			rng.start[i] = ip[i] & mask[i]
+			rng.end[i] = ip[i] | ^mask[i]
+		}
+
+		ranges = append(ranges, rng)
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return bytes.Compare(ranges[i].start[:], ranges[j].start[:]) < 0
+	})
+
+	m = &matcher{
+		ranges: make([]ipRange, 0, len(ranges)),
+	}
+
+	for _, rng := range ranges {
+		n := len(m.ranges)
+		if n > 0 && bytes.Compare(
+			rng.start[:], m.ranges[n-1].end[:]) <= 0 {
+
+			if bytes.Compare(rng.end[:], m.ranges[n-1].end[:]) > 0 {
+				m.ranges[n-1].end = rng.end
+			}
+			continue
+		}
+
+		m.ranges = append(m.ranges, rng)
+	}
+
+	return
+}
+
+func loadCache(db *database.Database) (err error) {
+	cacheLock.RLock()
+	expired := time.Since(cacheTime) > cacheTtl
+	cacheLock.RUnlock()
+
+	if !expired {
+		return
+	}
+
+	lists, err := GetAll(db)
+	if err != nil {
+		return
+	}
+
+	newCache := map[string]*matcher{}
+	for _, list := range lists {
+		networks, e := GetNetworks(db, list)
+		if e != nil {
+			err = e
+			return
+		}
+
+		newCache[list.Id] = newMatcher(networks)
+	}
+
+	cacheLock.Lock()
+	cache = newCache
+	cacheTime = time.Now()
+	cacheLock.Unlock()
+
+	return
+}
+
+// Get classification of address from loaded lists, all classifications are
+// checked when none are given. Returns empty string without a match.
+func Match(db *database.Database, addr string, classes []string) (
+	class string, err error) {
+
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return
+	}
+
+	err = loadCache(db)
+	if err != nil {
+		return
+	}
+
+	if len(classes) == 0 {
+		classes = Classifications
+	}
+
+	cacheLock.RLock()
+	defer cacheLock.RUnlock()
+
+	for _, cls := range classes {
+		m := cache[cls]
+		if m != nil && m.contains(ip) {
+			class = cls
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,75 @@
+package netlist
+
+import (
+	"net"
+	"testing"
+)
+
+func TestMatcher(t *testing.T) {
+	m := newMatcher([]string{
+		"10.0.0.0/8",
+		"10.1.0.0/16",
+		"192.168.0.0/23",
+		"192.168.1.0/24",
+		"172.16.0.0/24",
+		"172.16.1.0/24",
+		"1.2.3.4/32",
+		"2001:db8::/32",
+		"invalid",
+	})
+
+	if len(m.ranges) != 6 {
+		t.Errorf("ranges: got %d want 6", len(m.ranges))
+	}
+
+	tests := []struct {
+		addr   string
+		result bool
+	}{
+		// Overlapping networks are merged
+		{"10.0.0.0", true},
+		{"10.1.2.3", true},
+		{"10.255.255.255", true},
+		{"9.255.255.255", false},
+		{"11.0.0.0", false},
+		{"192.168.0.0", true},
+		{"192.168.1.255", true},
+		{"192.168.2.0", false},
+
+		// Adjacent networks are kept as separate ranges
+		{"172.16.0.255", true},
+		{"172.16.1.0", true},
+		{"172.16.2.0", false},
+
+		// Hosts
+		{"1.2.3.4", true},
+		{"1.2.3.3", false},
+		{"1.2.3.5", false},
+
+		// IPv6
+		{"2001:db8::1", true},
+		{"2001:db8:ffff:ffff:ffff:ffff:ffff:ffff", true},
+		{"2001:db9::", false},
+		{"::1", false},
+
+		// Bounds
+		{"0.0.0.0", false},
+		{"255.255.255.255", false},
+	}
+
+	for _, test := range tests {
+		result := m.contains(net.ParseIP(test.addr))
+		if result != test.result {
+			t.Errorf("contains %s: got %t want %t",
+				test.addr, result, test.result)
+		}
+	}
+}
+
+func TestMatcherEmpty(t *testing.T) {
+	m := newMatcher([]string{})
+
+	if m.contains(net.ParseIP("10.0.0.1")) {
+		t.Errorf("empty matcher contains address")
+	}
+}
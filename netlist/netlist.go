@@ -0,0 +1,79 @@
+package netlist
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+)
+
+type List struct {
+	Id        string             `bson:"_id" json:"id"`
+	Source    string             `bson:"source" json:"source"`
+	Version   primitive.ObjectID `bson:"version" json:"-"`
+	Networks  []string           `bson:"-" json:"-"`
+	Count     int                `bson:"count" json:"count"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+}
+
+// Networks are stored in chunks to stay under the document size limit, all
+// chunks of a list share the version of the list
+type chunk struct {
+	Id       primitive.ObjectID `bson:"_id"`
+	List     string             `bson:"list"`
+	Version  primitive.ObjectID `bson:"version"`
+	Networks []string           `bson:"networks"`
+}
+
+// Store networks as a new version, the list is switched to the new version
+// after all chunks are inserted and the previous chunks are then removed
+func (l *List) Upsert(db *database.Database) (err error) {
+	coll := db.Netlists()
+	chunksColl := db.NetlistChunks()
+
+	l.Version = primitive.NewObjectID()
+	l.Count = len(l.Networks)
+
+	for i := 0; i < len(l.Networks); i += chunkSize {
+		end := i + chunkSize
+		if end > len(l.Networks) {
+			end = len(l.Networks)
+		}
+
+		_, err = chunksColl.InsertOne(db, &chunk{
+			Id:       primitive.NewObjectID(),
+			List:     l.Id,
+			Version:  l.Version,
+			Networks: l.Networks[i:end],
+		})
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+	}
+
+	_, err = coll.UpdateOne(db, &bson.M{
+		"_id": l.Id,
+	}, &bson.M{
+		"$set": l,
+	}, options.Update().SetUpsert(true))
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	_, err = chunksColl.DeleteMany(db, &bson.M{
+		"list": l.Id,
+		"version": &bson.M{
+			"$ne": l.Version,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,269 @@
+package netlist
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	client = &http.Client{
+		Timeout: 60 * time.Second,
+	}
+)
+
+func getSource(class string) string {
+	switch class {
+	case Tor:
+		return settings.Netlist.TorSource
+	case Vpn:
+		return settings.Netlist.VpnSource
+	case Hosting:
+		return settings.Netlist.HostingSource
+	}
+	return ""
+}
+
+func open(source string) (reader io.ReadCloser, err error) {
+	if strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://") {
+
+		resp, e := client.Get(source)
+		if e != nil {
+			err = &errortypes.RequestError{
+				errors.Wrap(e, "netlist: Failed to request list"),
+			}
+			return
+		}
+
+		if resp.StatusCode != 200 {
+			_ = resp.Body.Close()
+			err = &errortypes.RequestError{
+				errors.Newf("netlist: Bad status %d from list source",
+					resp.StatusCode),
+			}
+			return
+		}
+
+		reader = resp.Body
+		return
+	}
+
+	file, e := os.Open(source)
+	if e != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(e, "netlist: Failed to open list file"),
+		}
+		return
+	}
+
+	reader = file
+	return
+}
+
+// Parse list with one address or network per line, text after # is ignored
+func parse(reader io.Reader) (networks []string, err error) {
+	networks = []string{}
+
+	scanner := bufio.NewScanner(io.LimitReader(reader, maxDownload))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "#"); i != -1 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.Contains(line, "/") {
+			_, network, e := net.ParseCIDR(line)
+			if e != nil {
+				continue
+			}
+			networks = append(networks, network.String())
+		} else {
+			ip := net.ParseIP(line)
+			if ip == nil {
+				continue
+			}
+
+			if ip.To4() != nil {
+				networks = append(networks, ip.String()+"/32")
+			} else {
+				networks = append(networks, ip.String()+"/128")
+			}
+		}
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "netlist: Failed to read list"),
+		}
+		return
+	}
+
+	return
+}
+
+func Load(source string) (networks []string, err error) {
+	reader, err := open(source)
+	if err != nil {
+		return
+	}
+	defer reader.Close()
+
+	networks, err = parse(reader)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database) (lists []*List, err error) {
+	coll := db.Netlists()
+	lists = []*List{}
+
+	cursor, err := coll.Find(db, &bson.M{})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		list := &List{}
+		err = cursor.Decode(list)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		lists = append(lists, list)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Get networks of list from the chunks of the current version
+func GetNetworks(db *database.Database, list *List) (
+	networks []string, err error) {
+
+	coll := db.NetlistChunks()
+	networks = []string{}
+
+	cursor, err := coll.Find(db, &bson.M{
+		"list":    list.Id,
+		"version": list.Version,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		chnk := &chunk{}
+		err = cursor.Decode(chnk)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		networks = append(networks, chnk.Networks...)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, class string) (err error) {
+	coll := db.Netlists()
+	chunksColl := db.NetlistChunks()
+
+	_, err = chunksColl.DeleteMany(db, &bson.M{
+		"list": class,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": class,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	return
+}
+
+// Reload lists from configured sources, lists without a source are removed
+// and lists that fail to load keep the previous networks
+func Refresh(db *database.Database) (err error) {
+	for _, class := range Classifications {
+		source := getSource(class)
+		if source == "" {
+			err = Remove(db, class)
+			if err != nil {
+				return
+			}
+			continue
+		}
+
+		networks, e := Load(source)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"classification": class,
+				"source":         source,
+				"error":          e,
+			}).Error("netlist: Failed to load network list")
+			continue
+		}
+
+		list := &List{
+			Id:        class,
+			Source:    source,
+			Networks:  networks,
+			Timestamp: time.Now(),
+		}
+
+		err = list.Upsert(db)
+		if err != nil {
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,204 @@
+package oidc
+
+import (
+	"crypto/subtle"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type Client struct {
+	Id           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name         string             `bson:"name" json:"name"`
+	ClientId     string             `bson:"client_id" json:"client_id"`
+	SecretHash   string             `bson:"secret_hash" json:"-"`
+	Secret       string             `bson:"-" json:"secret,omitempty"`
+	RedirectUris []string           `bson:"redirect_uris" json:"redirect_uris"`
+	MatchRoles   bool               `bson:"match_roles" json:"match_roles"`
+	Roles        []string           `bson:"roles" json:"roles"`
+}
+
+func (c *Client) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	c.Name = utils.FilterStr(c.Name, 64)
+
+	if c.Name == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "oidc_client_name_missing",
+			Message: "OpenID Connect client name is required",
+		}
+		return
+	}
+
+	if c.ClientId == "" {
+		c.ClientId, err = utils.RandStr(32)
+		if err != nil {
+			return
+		}
+	}
+
+	if c.SecretHash == "" {
+		err = c.GenerateSecret()
+		if err != nil {
+			return
+		}
+	}
+
+	if c.RedirectUris == nil {
+		c.RedirectUris = []string{}
+	}
+
+	if c.Roles == nil {
+		c.Roles = []string{}
+	}
+
+	uris := []string{}
+	urisSet := set.NewSet()
+	for _, uri := range c.RedirectUris {
+		uri = strings.TrimSpace(uri)
+		if uri == "" || urisSet.Contains(uri) {
+			continue
+		}
+
+		if !validRedirectUri(uri) {
+			errData = &errortypes.ErrorData{
+				Error: "oidc_client_redirect_uri_invalid",
+				Message: "OpenID Connect redirect URI must be an " +
+					"absolute HTTPS URL without a fragment",
+			}
+			return
+		}
+
+		urisSet.Add(uri)
+		uris = append(uris, uri)
+	}
+	c.RedirectUris = uris
+
+	if len(c.RedirectUris) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "oidc_client_redirect_uri_missing",
+			Message: "OpenID Connect client redirect URI is required",
+		}
+		return
+	}
+
+	rolesSet := set.NewSet()
+	for _, role := range c.Roles {
+		rolesSet.Add(role)
+	}
+
+	roles := []string{}
+	for role := range rolesSet.Iter() {
+		roles = append(roles, role.(string))
+	}
+	sort.Strings(roles)
+	c.Roles = roles
+
+	return
+}
+
+func (c *Client) GenerateSecret() (err error) {
+	c.Secret, err = utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	c.SecretHash = hashToken(c.Secret)
+
+	return
+}
+
+func (c *Client) CheckSecret(secret string) bool {
+	if c.SecretHash == "" || secret == "" {
+		return false
+	}
+
+	return subtle.ConstantTimeCompare(
+		[]byte(c.SecretHash), []byte(hashToken(secret))) == 1
+}
+
+func (c *Client) HasRedirectUri(uri string) bool {
+	for _, redirectUri := range c.RedirectUris {
+		if redirectUri == uri {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Client) UserHasAccess(usr *user.User) bool {
+	if !c.MatchRoles {
+		return true
+	}
+	return usr.RolesMatch(c.Roles)
+}
+
+func (c *Client) Commit(db *database.Database) (err error) {
+	coll := db.OidcClients()
+
+	err = coll.Commit(c.Id, c)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *Client) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.OidcClients()
+
+	err = coll.CommitFields(c.Id, c, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (c *Client) Insert(db *database.Database) (err error) {
+	coll := db.OidcClients()
+
+	if !c.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("oidc: Client already exists"),
+		}
+		return
+	}
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func validRedirectUri(uri string) bool {
+	u, err := url.Parse(uri)
+	if err != nil || u.Host == "" || u.Fragment != "" {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return true
+	case "http":
+		hostname := u.Hostname()
+		return hostname == "localhost" || hostname == "127.0.0.1" ||
+			hostname == "::1"
+	}
+
+	return false
+}
@@ -0,0 +1,114 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type Code struct {
+	Id                  string             `bson:"_id"`
+	Client              primitive.ObjectID `bson:"client"`
+	User                primitive.ObjectID `bson:"user"`
+	RedirectUri         string             `bson:"redirect_uri"`
+	Scope               []string           `bson:"scope"`
+	Nonce               string             `bson:"nonce"`
+	CodeChallenge       string             `bson:"code_challenge"`
+	CodeChallengeMethod string             `bson:"code_challenge_method"`
+	AuthTime            time.Time          `bson:"auth_time"`
+	Timestamp           time.Time          `bson:"timestamp"`
+}
+
+func (c *Code) CheckVerifier(verifier string) bool {
+	if c.CodeChallenge == "" {
+		return true
+	}
+
+	if verifier == "" {
+		return false
+	}
+
+	challenge := verifier
+	if c.CodeChallengeMethod == ChallengeS256 {
+		hash := sha256.Sum256([]byte(verifier))
+		challenge = base64.RawURLEncoding.EncodeToString(hash[:])
+	}
+
+	return subtle.ConstantTimeCompare(
+		[]byte(c.CodeChallenge), []byte(challenge)) == 1
+}
+
+func (c *Code) Insert(db *database.Database) (err error) {
+	coll := db.OidcCodes()
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Create authorization code and return the code sent to the client
+func NewCode(db *database.Database, client *Client, userId primitive.ObjectID,
+	redirectUri string, scope []string, nonce, challenge,
+	challengeMethod string, authTime time.Time) (code string, err error) {
+
+	code, err = utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	cd := &Code{
+		Id:                  hashToken(code),
+		Client:              client.Id,
+		User:                userId,
+		RedirectUri:         redirectUri,
+		Scope:               scope,
+		Nonce:               nonce,
+		CodeChallenge:       challenge,
+		CodeChallengeMethod: challengeMethod,
+		AuthTime:            authTime,
+		Timestamp:           time.Now(),
+	}
+
+	err = cd.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Remove and return authorization code, codes can only be redeemed once
+func RedeemCode(db *database.Database, code string) (cd *Code, err error) {
+	coll := db.OidcCodes()
+	cd = &Code{}
+
+	err = coll.FindOneAndDelete(db, &bson.M{
+		"_id": hashToken(code),
+	}).Decode(cd)
+	if err != nil {
+		err = database.ParseError(err)
+		cd = nil
+		return
+	}
+
+	if time.Since(cd.Timestamp) > CodeExpire {
+		err = &database.NotFoundError{
+			errors.New("oidc: Authorization code expired"),
+		}
+		cd = nil
+		return
+	}
+
+	return
+}
@@ -0,0 +1,48 @@
+package oidc
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+)
+
+const (
+	CodeExpire  = 3 * time.Minute
+	TokenExpire = 1 * time.Hour
+
+	ScopeOpenId  = "openid"
+	ScopeProfile = "profile"
+	ScopeEmail   = "email"
+	ScopeGroups  = "groups"
+
+	ChallengePlain = "plain"
+	ChallengeS256  = "S256"
+)
+
+var (
+	scopes = set.NewSet(
+		ScopeOpenId,
+		ScopeProfile,
+		ScopeEmail,
+		ScopeGroups,
+	)
+	Scopes = []string{
+		ScopeOpenId,
+		ScopeProfile,
+		ScopeEmail,
+		ScopeGroups,
+	}
+	Claims = []string{
+		"sub",
+		"iss",
+		"aud",
+		"exp",
+		"iat",
+		"auth_time",
+		"nonce",
+		"name",
+		"preferred_username",
+		"email",
+		"groups",
+	}
+)
@@ -0,0 +1,166 @@
+package oidc
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"sync"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/settings"
+)
+
+var (
+	keyCache     *rsa.PrivateKey
+	keyCacheKid  string
+	keyCachePem  string
+	keyCacheLock sync.Mutex
+)
+
+type Jwk struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+}
+
+type Jwks struct {
+	Keys []*Jwk `json:"keys"`
+}
+
+func generateKey() (encodedPriv string, err error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "oidc: Failed to generate rsa key"),
+		}
+		return
+	}
+
+	block := &pem.Block{
+		Type:  "RSA PRIVATE KEY",
+		Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+	}
+
+	encodedPriv = string(pem.EncodeToMemory(block))
+
+	return
+}
+
+// Get parsed signing key, the key is cached until the settings key changes
+func loadKey() (privateKey *rsa.PrivateKey, kid string, err error) {
+	encodedKey := settings.System.OidcPrivateKey
+
+	keyCacheLock.Lock()
+	defer keyCacheLock.Unlock()
+
+	if keyCache != nil && keyCachePem == encodedKey {
+		privateKey = keyCache
+		kid = keyCacheKid
+		return
+	}
+
+	block, _ := pem.Decode([]byte(encodedKey))
+	if block == nil {
+		err = &errortypes.ParseError{
+			errors.New("oidc: Failed to decode signing key"),
+		}
+		return
+	}
+
+	privateKey, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "oidc: Failed to parse signing key"),
+		}
+		return
+	}
+
+	hash := sha256.Sum256(privateKey.PublicKey.N.Bytes())
+	kid = hex.EncodeToString(hash[:8])
+
+	keyCache = privateKey
+	keyCacheKid = kid
+	keyCachePem = encodedKey
+
+	return
+}
+
+func GetJwks() (jwks *Jwks, err error) {
+	privateKey, kid, err := loadKey()
+	if err != nil {
+		return
+	}
+
+	jwks = &Jwks{
+		Keys: []*Jwk{
+			{
+				Kty: "RSA",
+				Use: "sig",
+				Alg: "RS256",
+				Kid: kid,
+				N: base64.RawURLEncoding.EncodeToString(
+					privateKey.PublicKey.N.Bytes()),
+				E: base64.RawURLEncoding.EncodeToString(
+					big.NewInt(int64(privateKey.PublicKey.E)).Bytes()),
+			},
+		},
+	}
+
+	return
+}
+
+// Sign claims as a RS256 JSON Web Token
+func signJwt(claims map[string]interface{}) (token string, err error) {
+	privateKey, kid, err := loadKey()
+	if err != nil {
+		return
+	}
+
+	header, err := json.Marshal(map[string]string{
+		"alg": "RS256",
+		"typ": "JWT",
+		"kid": kid,
+	})
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "oidc: Failed to marshal token header"),
+		}
+		return
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "oidc: Failed to marshal token claims"),
+		}
+		return
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(header) + "." +
+		base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := sha256.Sum256([]byte(token))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, privateKey,
+		crypto.SHA256, hash[:])
+	if err != nil {
+		err = &errortypes.ReadError{
+			errors.Wrap(err, "oidc: Failed to sign token"),
+		}
+		return
+	}
+
+	token += "." + base64.RawURLEncoding.EncodeToString(sig)
+
+	return
+}
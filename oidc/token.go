@@ -0,0 +1,83 @@
+package oidc
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type Token struct {
+	Id        string             `bson:"_id"`
+	Client    primitive.ObjectID `bson:"client"`
+	User      primitive.ObjectID `bson:"user"`
+	Scope     []string           `bson:"scope"`
+	Timestamp time.Time          `bson:"timestamp"`
+}
+
+func (t *Token) HasScope(scope string) bool {
+	for _, s := range t.Scope {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *Token) Insert(db *database.Database) (err error) {
+	coll := db.OidcTokens()
+
+	_, err = coll.InsertOne(db, t)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+// Create access token and return the token sent to the client
+func NewToken(db *database.Database, cd *Code) (token string, err error) {
+	token, err = utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	tkn := &Token{
+		Id:        hashToken(token),
+		Client:    cd.Client,
+		User:      cd.User,
+		Scope:     cd.Scope,
+		Timestamp: time.Now(),
+	}
+
+	err = tkn.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetToken(db *database.Database, token string) (tkn *Token, err error) {
+	coll := db.OidcTokens()
+	tkn = &Token{}
+
+	err = coll.FindOneId(hashToken(token), tkn)
+	if err != nil {
+		tkn = nil
+		return
+	}
+
+	if time.Since(tkn.Timestamp) > TokenExpire {
+		err = &database.NotFoundError{
+			errors.New("oidc: Access token expired"),
+		}
+		tkn = nil
+		return
+	}
+
+	return
+}
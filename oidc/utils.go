@@ -0,0 +1,224 @@
+package oidc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/node"
+	"github.com/pritunl/pritunl-zero/requires"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func hashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func Issuer() string {
+	return "https://" + node.Self.UserDomain
+}
+
+// Parse space delimited scope, unknown scopes are ignored
+func ParseScope(scope string) (scps []string, valid bool) {
+	scps = []string{}
+	scpsSet := set.NewSet()
+
+	for _, scp := range strings.Fields(scope) {
+		if !scopes.Contains(scp) || scpsSet.Contains(scp) {
+			continue
+		}
+		scpsSet.Add(scp)
+		scps = append(scps, scp)
+	}
+
+	valid = scpsSet.Contains(ScopeOpenId)
+	return
+}
+
+// Get user claims, roles are always included as groups
+func GetClaims(usr *user.User, scope []string) (
+	claims map[string]interface{}) {
+
+	claims = map[string]interface{}{
+		"sub": usr.Id.Hex(),
+	}
+
+	roles := usr.Roles
+	if roles == nil {
+		roles = []string{}
+	}
+	claims["groups"] = roles
+
+	for _, scp := range scope {
+		switch scp {
+		case ScopeProfile:
+			claims["name"] = usr.Username
+			claims["preferred_username"] = usr.Username
+			break
+		case ScopeEmail:
+			if strings.Contains(usr.Username, "@") {
+				claims["email"] = usr.Username
+				claims["email_verified"] = false
+			}
+			break
+		}
+	}
+
+	return
+}
+
+func NewIdToken(client *Client, usr *user.User, cd *Code) (
+	token string, err error) {
+
+	now := time.Now()
+
+	claims := GetClaims(usr, cd.Scope)
+	claims["iss"] = Issuer()
+	claims["aud"] = client.ClientId
+	claims["iat"] = now.Unix()
+	claims["exp"] = now.Add(TokenExpire).Unix()
+	claims["auth_time"] = cd.AuthTime.Unix()
+	if cd.Nonce != "" {
+		claims["nonce"] = cd.Nonce
+	}
+
+	token, err = signJwt(claims)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func Get(db *database.Database, clientId primitive.ObjectID) (
+	client *Client, err error) {
+
+	coll := db.OidcClients()
+	client = &Client{}
+
+	err = coll.FindOneId(clientId, client)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetClientId(db *database.Database, clientId string) (
+	client *Client, err error) {
+
+	coll := db.OidcClients()
+	client = &Client{}
+
+	err = coll.FindOne(db, &bson.M{
+		"client_id": clientId,
+	}).Decode(client)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database) (clients []*Client, err error) {
+	coll := db.OidcClients()
+	clients = []*Client{}
+
+	cursor, err := coll.Find(db, &bson.M{}, &options.FindOptions{
+		Sort: &bson.D{
+			{"name", 1},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		client := &Client{}
+		err = cursor.Decode(client)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		clients = append(clients, client)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, clientId primitive.ObjectID) (err error) {
+	coll := db.OidcClients()
+
+	_, err = coll.DeleteOne(db, &bson.M{
+		"_id": clientId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+
+		switch err.(type) {
+		case *database.NotFoundError:
+			err = nil
+		default:
+			return
+		}
+	}
+
+	coll = db.OidcTokens()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"client": clientId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func init() {
+	module := requires.New("oidc")
+	module.After("settings")
+
+	module.Handler = func() (err error) {
+		db := database.GetDatabase()
+		defer db.Close()
+
+		if settings.System.OidcPrivateKey == "" {
+			privateKey, e := generateKey()
+			if e != nil {
+				err = e
+				return
+			}
+
+			settings.System.OidcPrivateKey = privateKey
+
+			err = settings.Commit(db, settings.System, set.NewSet(
+				"oidc_private_key",
+			))
+			if err != nil {
+				return
+			}
+		}
+
+		return
+	}
+}
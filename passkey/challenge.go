@@ -0,0 +1,29 @@
+package passkey
+
+import (
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/webauthn/webauthn"
+)
+
+type Challenge struct {
+	Id         string                `bson:"_id"`
+	Type       string                `bson:"type"`
+	User       primitive.ObjectID    `bson:"user,omitempty"`
+	WanSession *webauthn.SessionData `bson:"wan_session"`
+	Timestamp  time.Time             `bson:"timestamp"`
+}
+
+func (c *Challenge) Insert(db *database.Database) (err error) {
+	coll := db.PasskeyChallenges()
+
+	_, err = coll.InsertOne(db, c)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
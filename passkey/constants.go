@@ -0,0 +1,6 @@
+package passkey
+
+const (
+	Register = "register"
+	Login    = "login"
+)
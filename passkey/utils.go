@@ -0,0 +1,341 @@
+package passkey
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/node"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/pritunl/webauthn/protocol"
+	"github.com/pritunl/webauthn/webauthn"
+	"github.com/sirupsen/logrus"
+)
+
+func newChallenge(db *database.Database, typ string, userId primitive.ObjectID,
+	sessionData *webauthn.SessionData) (chal *Challenge, err error) {
+
+	token, err := utils.RandStr(48)
+	if err != nil {
+		return
+	}
+
+	chal = &Challenge{
+		Id:         token,
+		Type:       typ,
+		User:       userId,
+		WanSession: sessionData,
+		Timestamp:  time.Now(),
+	}
+
+	err = chal.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func redeemChallenge(db *database.Database, token, typ string) (
+	chal *Challenge, err error) {
+
+	coll := db.PasskeyChallenges()
+	chal = &Challenge{}
+
+	err = coll.FindOneAndDelete(db, &bson.M{
+		"_id":  token,
+		"type": typ,
+	}).Decode(chal)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if time.Since(chal.Timestamp) > 3*time.Minute ||
+		chal.WanSession == nil {
+
+		err = &database.NotFoundError{
+			errors.New("passkey: Challenge expired"),
+		}
+		return
+	}
+
+	return
+}
+
+func loadCredentials(db *database.Database, usr *user.User) (
+	devices []*device.Device, err error) {
+
+	devices, err = device.GetAllMode(db, usr.Id, device.Passkey)
+	if err != nil {
+		return
+	}
+
+	wanCredentials := []webauthn.Credential{}
+	for _, devc := range devices {
+		wanCred, e := devc.UnmarshalWebauthn()
+		if e != nil {
+			err = e
+			return
+		}
+
+		wanCredentials = append(wanCredentials, wanCred)
+	}
+
+	usr.WanCredentials = wanCredentials
+
+	return
+}
+
+func RegisterRequest(db *database.Database, origin string,
+	usr *user.User) (token string, jsonResp interface{}, err error) {
+
+	web, err := node.Self.GetWebauthn(origin, true)
+	if err != nil {
+		return
+	}
+
+	devices, err := loadCredentials(db, usr)
+	if err != nil {
+		return
+	}
+
+	exclusions := []protocol.CredentialDescriptor{}
+	for _, devc := range devices {
+		exclusions = append(exclusions, protocol.CredentialDescriptor{
+			Type:         protocol.PublicKeyCredentialType,
+			CredentialID: devc.WanId,
+		})
+	}
+
+	options, sessionData, err := web.BeginRegistration(
+		usr,
+		webauthn.WithAuthenticatorSelection(
+			protocol.AuthenticatorSelection{
+				RequireResidentKey: protocol.ResidentKeyRequired(),
+				UserVerification:   protocol.VerificationRequired,
+			},
+		),
+		webauthn.WithExclusions(exclusions),
+	)
+	if err != nil {
+		err = utils.ParseWebauthnError(err)
+		return
+	}
+
+	chal, err := newChallenge(db, Register, usr.Id, sessionData)
+	if err != nil {
+		return
+	}
+
+	token = chal.Id
+	jsonResp = options
+
+	return
+}
+
+func RegisterResponse(db *database.Database, origin string,
+	usr *user.User, token string, body io.Reader, name string) (
+	devc *device.Device, errData *errortypes.ErrorData, err error) {
+
+	chal, err := redeemChallenge(db, token, Register)
+	if err != nil {
+		return
+	}
+
+	if chal.User != usr.Id {
+		err = &errortypes.AuthenticationError{
+			errors.New("passkey: Registration user mismatch"),
+		}
+		return
+	}
+
+	data, err := protocol.ParseCredentialCreationResponseBody(body)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "passkey: Webauthn parse error"),
+		}
+		return
+	}
+
+	web, err := node.Self.GetWebauthn(origin, true)
+	if err != nil {
+		return
+	}
+
+	credential, err := web.CreateCredential(usr, *chal.WanSession, data)
+	if err != nil {
+		err = utils.ParseWebauthnError(err)
+		return
+	}
+
+	devc = device.New(usr.Id, device.WebAuthn, device.Passkey)
+	devc.Name = name
+	devc.WanRpId = web.Config.RPID
+
+	devc.MarshalWebauthn(credential)
+
+	errData, err = devc.Validate(db)
+	if err != nil || errData != nil {
+		return
+	}
+
+	err = devc.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Create a discoverable login challenge without a user or credential list
+func LoginRequest(db *database.Database, origin string) (
+	token string, jsonResp interface{}, err error) {
+
+	web, err := node.Self.GetWebauthn(origin, true)
+	if err != nil {
+		return
+	}
+
+	challenge, err := protocol.CreateChallenge()
+	if err != nil {
+		err = &errortypes.UnknownError{
+			errors.Wrap(err, "passkey: Failed to create challenge"),
+		}
+		return
+	}
+
+	options := protocol.PublicKeyCredentialRequestOptions{
+		Challenge:        challenge,
+		Timeout:          web.Config.Timeout,
+		RelyingPartyID:   web.Config.RPID,
+		UserVerification: protocol.VerificationRequired,
+	}
+
+	sessionData := &webauthn.SessionData{
+		Challenge:        base64.RawURLEncoding.EncodeToString(challenge),
+		UserVerification: protocol.VerificationRequired,
+	}
+
+	chal, err := newChallenge(db, Login, primitive.NilObjectID, sessionData)
+	if err != nil {
+		return
+	}
+
+	token = chal.Id
+	jsonResp = &protocol.CredentialAssertion{
+		Response: options,
+	}
+
+	return
+}
+
+// Verify a discoverable login and return the user owning the credential
+func LoginResponse(db *database.Database, origin string, token string,
+	body io.Reader) (usr *user.User, devc *device.Device,
+	errData *errortypes.ErrorData, err error) {
+
+	chal, err := redeemChallenge(db, token, Login)
+	if err != nil {
+		return
+	}
+
+	data, err := protocol.ParseCredentialRequestResponseBody(body)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "passkey: Webauthn parse error"),
+		}
+		return
+	}
+
+	userHandle := data.Response.UserHandle
+	if len(userHandle) != 12 {
+		errData = &errortypes.ErrorData{
+			Error:   "passkey_invalid",
+			Message: "Passkey is not registered",
+		}
+		return
+	}
+
+	userId := primitive.ObjectID{}
+	copy(userId[:], userHandle)
+
+	usr, err = user.Get(db, userId)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			usr = nil
+			errData = &errortypes.ErrorData{
+				Error:   "passkey_invalid",
+				Message: "Passkey is not registered",
+			}
+		}
+		return
+	}
+
+	web, err := node.Self.GetWebauthn(origin, true)
+	if err != nil {
+		return
+	}
+
+	devices, err := loadCredentials(db, usr)
+	if err != nil {
+		return
+	}
+
+	sessionData := *chal.WanSession
+	sessionData.UserID = usr.WebAuthnID()
+
+	credential, err := web.ValidateLogin(usr, sessionData, data)
+	if err != nil {
+		err = utils.ParseWebauthnError(err)
+		logrus.WithFields(logrus.Fields{
+			"user_id": usr.Id.Hex(),
+			"error":   err,
+		}).Error("passkey: Passkey authentication was denied")
+
+		err = nil
+		errData = &errortypes.ErrorData{
+			Error:   "passkey_denied",
+			Message: "Passkey authentication was denied",
+		}
+		return
+	}
+
+	for _, dvc := range devices {
+		if !bytes.Equal(dvc.WanId, credential.ID) ||
+			!bytes.Equal(dvc.WanPublicKey, credential.PublicKey) {
+
+			continue
+		}
+
+		dvc.LastActive = time.Now()
+		dvc.MarshalWebauthn(credential)
+
+		err = dvc.CommitFields(db, set.NewSet(
+			"last_active", "wan_authenticator"))
+		if err != nil {
+			return
+		}
+
+		devc = dvc
+
+		return
+	}
+
+	errData = &errortypes.ErrorData{
+		Error:   "passkey_denied",
+		Message: "Passkey authentication was denied",
+	}
+
+	return
+}
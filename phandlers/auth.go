@@ -116,6 +116,25 @@ func authSessionPost(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, srvc, "local") {
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, srvc,
+		audit.ProxyLoginFailed, "local")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.ProxyLoginFailed, "local") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -174,9 +193,7 @@ func authSessionPost(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.ProxyLogin,
-		audit.Fields{
-			"method": "local",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -185,7 +202,7 @@ func authSessionPost(c *gin.Context) {
 
 	cook := cookie.NewProxy(srvc, c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -353,7 +370,7 @@ func authSecondaryPost(c *gin.Context) {
 
 	cook := cookie.NewProxy(srvc, c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -492,6 +509,25 @@ func authCallbackGet(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, srvc, "callback") {
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, srvc,
+		audit.ProxyLoginFailed, "callback")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.ProxyLoginFailed, "callback") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -558,9 +594,7 @@ func authCallbackGet(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.ProxyLogin,
-		audit.Fields{
-			"method": "callback",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -569,7 +603,7 @@ func authCallbackGet(c *gin.Context) {
 
 	cook := cookie.NewProxy(srvc, c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -795,7 +829,7 @@ func authWanRegisterPost(c *gin.Context) {
 
 	cook := cookie.NewProxy(srvc, c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -1009,7 +1043,7 @@ func authWanRespondPost(c *gin.Context) {
 
 	cook := cookie.NewProxy(srvc, c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.Proxy, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -0,0 +1,65 @@
+package phandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type endpointRedeemData struct {
+	Secret string `json:"secret"`
+}
+
+// Create challenge for the endpoint agent to answer, the login page passes
+// the challenge id to the agent and polls for the answer
+func authEndpointChallengePost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	chal, err := endpoint.NewChallenge(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, chal)
+}
+
+// Redeem answered challenge and set the endpoint token cookie, returns
+// 202 while the challenge has not been answered
+func authEndpointRedeemPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	data := &endpointRedeemData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	bind, errData, err := endpoint.RedeemChallenge(
+		db, c.Param("challenge_id"), data.Secret)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	if bind == nil {
+		c.Status(202)
+		return
+	}
+
+	endpoint.SetToken(c.Writer, bind)
+
+	c.Status(200)
+}
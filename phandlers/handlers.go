@@ -51,6 +51,11 @@ func Register(prxy *proxy.Proxy, engine *gin.Engine) {
 	dbGroup.POST("/auth/webauthn/respond", authWanRespondPost)
 	dbGroup.GET("/auth/webauthn/register", authWanRegisterGet)
 	dbGroup.POST("/auth/webauthn/register", authWanRegisterPost)
+	dbGroup.GET("/auth/passkey/request", authPasskeyRequestGet)
+	dbGroup.POST("/auth/passkey/respond", authPasskeyRespondPost)
+	dbGroup.POST("/auth/endpoint/challenge", authEndpointChallengePost)
+	dbGroup.POST("/auth/endpoint/challenge/:challenge_id",
+		authEndpointRedeemPost)
 	sessGroup.GET("/logout", logoutGet)
 
 	engine.GET("/check", checkGet)
@@ -0,0 +1,58 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+func validateAnomaly(rule *Rule) (errData *errortypes.ErrorData) {
+	switch rule.Action {
+	case StepUp, Deny, Alert:
+		break
+	case "":
+		rule.Action = Alert
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "anomaly_action_invalid",
+			Message: fmt.Sprintf("Anomaly action '%s' is invalid", rule.Action),
+		}
+		return
+	}
+
+	if rule.Values == nil {
+		rule.Values = []string{}
+	}
+
+	for _, value := range rule.Values {
+		switch value {
+		case ImpossibleTravel, NewCountry, NewIsp, NewOperatingSystem,
+			NewBrowser:
+
+			break
+		default:
+			errData = &errortypes.ErrorData{
+				Error:   "anomaly_check_invalid",
+				Message: fmt.Sprintf("Anomaly check '%s' is invalid", value),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+// Get enabled anomaly checks, all checks are enabled when none are listed
+func (r *Rule) AnomalyChecks() []string {
+	if len(r.Values) == 0 {
+		return []string{
+			ImpossibleTravel,
+			NewCountry,
+			NewIsp,
+			NewOperatingSystem,
+			NewBrowser,
+		}
+	}
+	return r.Values
+}
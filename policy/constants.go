@@ -1,12 +1,39 @@
 package policy
 
 const (
-	Optional          = "optional"
-	Required          = "required"
-	Disabled          = "disabled"
-	OperatingSystem   = "operating_system"
-	Browser           = "browser"
-	Location          = "location"
-	WhitelistNetworks = "whitelist_networks"
-	BlacklistNetworks = "blacklist_networks"
+	Optional           = "optional"
+	Required           = "required"
+	Disabled           = "disabled"
+	OperatingSystem    = "operating_system"
+	Browser            = "browser"
+	Location           = "location"
+	WhitelistNetworks  = "whitelist_networks"
+	BlacklistNetworks  = "blacklist_networks"
+	Schedule           = "schedule"
+	Blackout           = "blackout"
+	Anomaly            = "anomaly"
+	Expression         = "expression"
+	DevicePosture      = "device_posture"
+	WhitelistIsps      = "whitelist_isps"
+	BlacklistIsps      = "blacklist_isps"
+	Anonymizer         = "anonymizer"
+	RiskScore          = "risk_score"
+	MinOperatingSystem = "min_operating_system"
+	MinBrowser         = "min_browser"
+
+	ImpossibleTravel   = "impossible_travel"
+	NewCountry         = "new_country"
+	NewIsp             = "new_isp"
+	NewOperatingSystem = "new_operating_system"
+	NewBrowser         = "new_browser"
+
+	PosturePlatform          = "platform"
+	PostureMaxPackageUpdates = "max_package_updates"
+	PostureSeenWithin        = "seen_within"
+
+	StepUp   = "step_up"
+	Deny     = "deny"
+	Alert    = "alert"
+	Allow    = "allow"
+	Approval = "approval"
 )
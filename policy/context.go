@@ -0,0 +1,133 @@
+package policy
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/expression"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+// Variables available to expression rules
+var ExpressionSchema = map[string]string{
+	"user.id":                        expression.String,
+	"user.username":                  expression.String,
+	"user.type":                      expression.String,
+	"user.roles":                     expression.List,
+	"user.administrator":             expression.Bool,
+	"user.last_active":               expression.Number,
+	"agent.ip":                       expression.String,
+	"agent.isp":                      expression.String,
+	"agent.operating_system":         expression.String,
+	"agent.browser":                  expression.String,
+	"agent.platform":                 expression.String,
+	"agent.operating_system_version": expression.String,
+	"agent.browser_version":          expression.String,
+	"agent.continent_code":           expression.String,
+	"agent.country":                  expression.String,
+	"agent.country_code":             expression.String,
+	"agent.region_code":              expression.String,
+	"agent.city":                     expression.String,
+	"agent.latitude":                 expression.Number,
+	"agent.longitude":                expression.Number,
+	"request.path":                   expression.String,
+	"request.method":                 expression.String,
+	"request.host":                   expression.String,
+	"request.service":                expression.String,
+	"time.hour":                      expression.Number,
+	"time.minute":                    expression.Number,
+	"time.weekday":                   expression.String,
+	"time.unix":                      expression.Number,
+	"devices":                        expression.List,
+}
+
+type Context struct {
+	User           *user.User
+	Agent          *agent.Agent
+	Request        *http.Request
+	Service        string
+	Devices        []string
+	Endpoint       *endpoint.Endpoint
+	EndpointError  *errortypes.ErrorData
+	Classification string
+	Time           time.Time
+}
+
+func (c *Context) agent() *agent.Agent {
+	if c.Agent == nil {
+		return &agent.Agent{}
+	}
+	return c.Agent
+}
+
+// Build expression variables from context, time is in UTC
+func (c *Context) Vars() (vars map[string]interface{}) {
+	agnt := c.agent()
+	now := c.Time.UTC()
+
+	vars = map[string]interface{}{
+		"user.id":                        "",
+		"user.username":                  "",
+		"user.type":                      "",
+		"user.roles":                     []interface{}{},
+		"user.administrator":             false,
+		"user.last_active":               float64(0),
+		"agent.ip":                       agnt.Ip,
+		"agent.isp":                      agnt.Isp,
+		"agent.operating_system":         agnt.OperatingSystem,
+		"agent.browser":                  agnt.Browser,
+		"agent.platform":                 agnt.Platform,
+		"agent.operating_system_version": agnt.OperatingSystemVersion,
+		"agent.browser_version":          agnt.BrowserVersion,
+		"agent.continent_code":           agnt.ContinentCode,
+		"agent.country":                  agnt.Country,
+		"agent.country_code":             agnt.CountryCode,
+		"agent.region_code":              agnt.RegionCode,
+		"agent.city":                     agnt.City,
+		"agent.latitude":                 agnt.Latitude,
+		"agent.longitude":                agnt.Longitude,
+		"request.path":                   "",
+		"request.method":                 "",
+		"request.host":                   "",
+		"request.service":                c.Service,
+		"time.hour":                      float64(now.Hour()),
+		"time.minute":                    float64(now.Minute()),
+		"time.weekday":                   strings.ToLower(now.Weekday().String()[:3]),
+		"time.unix":                      float64(now.Unix()),
+		"devices":                        toList(c.Devices),
+	}
+
+	if c.User != nil {
+		vars["user.id"] = c.User.Id.Hex()
+		vars["user.username"] = c.User.Username
+		vars["user.type"] = c.User.Type
+		vars["user.roles"] = toList(c.User.Roles)
+		vars["user.administrator"] = adminrole.IsAdmin(c.User)
+		if !c.User.LastActive.IsZero() {
+			vars["user.last_active"] = float64(c.User.LastActive.Unix())
+		}
+	}
+
+	if c.Request != nil {
+		vars["request.method"] = c.Request.Method
+		vars["request.host"] = c.Request.Host
+		if c.Request.URL != nil {
+			vars["request.path"] = c.Request.URL.Path
+		}
+	}
+
+	return
+}
+
+func toList(values []string) (items []interface{}) {
+	items = []interface{}{}
+	for _, val := range values {
+		items = append(items, val)
+	}
+	return
+}
@@ -0,0 +1,143 @@
+package policy
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/expression"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func checkVarType(typ string, val interface{}) bool {
+	switch typ {
+	case expression.String:
+		_, ok := val.(string)
+		return ok
+	case expression.Number:
+		_, ok := val.(float64)
+		return ok
+	case expression.Bool:
+		_, ok := val.(bool)
+		return ok
+	case expression.List:
+		_, ok := val.([]interface{})
+		return ok
+	default:
+		return false
+	}
+}
+
+func TestExpressionSchema(t *testing.T) {
+	req, _ := http.NewRequest("GET", "https://app.example.com/admin", nil)
+
+	contexts := []*Context{
+		&Context{},
+		&Context{
+			User: &user.User{
+				Id:         primitive.NewObjectID(),
+				Username:   "alice",
+				Type:       user.Local,
+				Roles:      []string{"admin", "ops"},
+				LastActive: time.Now(),
+			},
+			Agent: &agent.Agent{
+				Ip:          "10.0.1.5",
+				CountryCode: "US",
+				Latitude:    37.7,
+			},
+			Request: req,
+			Service: "app",
+			Devices: []string{"laptop"},
+			Time:    time.Date(2024, 1, 1, 14, 30, 0, 0, time.UTC),
+		},
+	}
+
+	for _, ctx := range contexts {
+		vars := ctx.Vars()
+
+		for name, typ := range ExpressionSchema {
+			val, ok := vars[name]
+			if !ok {
+				t.Errorf("schema variable %q missing from vars", name)
+				continue
+			}
+
+			if !checkVarType(typ, val) {
+				t.Errorf("schema variable %q is %T not %s", name, val, typ)
+			}
+		}
+
+		for name := range vars {
+			if _, ok := ExpressionSchema[name]; !ok {
+				t.Errorf("vars variable %q missing from schema", name)
+			}
+		}
+
+		for name := range ExpressionSchema {
+			typ := ExpressionSchema[name]
+			src := ""
+			switch typ {
+			case expression.String:
+				src = name + " == ''"
+			case expression.Number:
+				src = name + " >= 0 || " + name + " < 0"
+			case expression.Bool:
+				src = name + " || !" + name
+			case expression.List:
+				src = "size(" + name + ") >= 0"
+			}
+
+			expr, err := expression.Compile(src, ExpressionSchema)
+			if err != nil {
+				t.Errorf("compile %q: %s", src, err)
+				continue
+			}
+
+			_, err = expr.Eval(vars)
+			if err != nil {
+				t.Errorf("eval %q: %s", src, err)
+			}
+		}
+	}
+
+	ctx := contexts[1]
+	vars := ctx.Vars()
+
+	tests := []struct {
+		src    string
+		result bool
+	}{
+		{"user.username == 'alice'", true},
+		{"'ops' in user.roles", true},
+		{"agent.country_code == 'US'", true},
+		{"cidr(agent.ip, '10.0.0.0/8')", true},
+		{"request.host == 'app.example.com'", true},
+		{"request.path.startsWith('/admin')", true},
+		{"request.service == 'app'", true},
+		{"time.hour == 14 && time.weekday == 'mon'", true},
+		{"'laptop' in devices", true},
+		{"user.administrator", false},
+	}
+
+	for _, test := range tests {
+		expr, err := expression.Compile(test.src, ExpressionSchema)
+		if err != nil {
+			t.Errorf("compile %q: %s", test.src, err)
+			continue
+		}
+
+		result, err := expr.Eval(vars)
+		if err != nil {
+			t.Errorf("eval %q: %s", test.src, err)
+			continue
+		}
+
+		if result != test.result {
+			t.Errorf("eval %q: got %t want %t",
+				test.src, result, test.result)
+		}
+	}
+}
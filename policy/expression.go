@@ -0,0 +1,100 @@
+package policy
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/expression"
+)
+
+var (
+	expressionCache     = map[string]*expression.Expression{}
+	expressionCacheLock = sync.Mutex{}
+)
+
+func compileExpression(src string) (
+	expr *expression.Expression, err error) {
+
+	expressionCacheLock.Lock()
+	expr = expressionCache[src]
+	expressionCacheLock.Unlock()
+	if expr != nil {
+		return
+	}
+
+	expr, err = expression.Compile(src, ExpressionSchema)
+	if err != nil {
+		return
+	}
+
+	expressionCacheLock.Lock()
+	if len(expressionCache) > 1000 {
+		expressionCache = map[string]*expression.Expression{}
+	}
+	expressionCache[src] = expr
+	expressionCacheLock.Unlock()
+
+	return
+}
+
+func validateExpression(rule *Rule) (errData *errortypes.ErrorData) {
+	if len(rule.Values) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "expression_invalid",
+			Message: "Expression policy requires an expression",
+		}
+		return
+	}
+
+	for _, value := range rule.Values {
+		_, err := expression.Compile(value, ExpressionSchema)
+		if err != nil {
+			errData = &errortypes.ErrorData{
+				Error: "expression_invalid",
+				Message: fmt.Sprintf("Expression '%s' is invalid: %s",
+					value, err.Error()),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+// Check that all expressions evaluate true, returns the first failure
+func matchExpression(rule *Rule, ctx *Context) (
+	failed string, err error) {
+
+	vars := ctx.Vars()
+
+	for _, value := range rule.Values {
+		expr, e := compileExpression(value)
+		if e != nil {
+			err = e
+			return
+		}
+
+		result, e := expr.Eval(vars)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if !result {
+			failed = value
+			return
+		}
+	}
+
+	return
+}
+
+func (p *Policy) hasRule(typ string) bool {
+	for _, rule := range p.Rules {
+		if rule.Type == typ {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,151 @@
+package policy
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/netlist"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+var asnReg = regexp.MustCompile(`^(?i)AS[0-9]+$`)
+
+func validateIsps(rule *Rule) (errData *errortypes.ErrorData) {
+	values := []string{}
+
+	for _, value := range rule.Values {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		// Geo data does not include the ASN
+		if asnReg.MatchString(value) {
+			errData = &errortypes.ErrorData{
+				Error: "isp_asn_unsupported",
+				Message: fmt.Sprintf(
+					"ISP rule cannot match ASN '%s', use the ISP name",
+					value),
+			}
+			return
+		}
+
+		values = append(values, value)
+	}
+
+	if len(values) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "isp_invalid",
+			Message: "ISP rule requires at least one ISP",
+		}
+		return
+	}
+
+	rule.Values = values
+
+	return
+}
+
+func validateAnonymizer(rule *Rule) (errData *errortypes.ErrorData) {
+	if rule.Values == nil {
+		rule.Values = []string{}
+	}
+
+	for _, value := range rule.Values {
+		switch value {
+		case netlist.Tor, netlist.Vpn, netlist.Hosting:
+			break
+		default:
+			errData = &errortypes.ErrorData{
+				Error: "anonymizer_invalid",
+				Message: fmt.Sprintf(
+					"Anonymizer classification '%s' is invalid", value),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+// Get rule value matching ISP with a case insensitive substring
+func matchIsp(rule *Rule, isp string) string {
+	if isp == "" {
+		return ""
+	}
+
+	ispLower := strings.ToLower(isp)
+
+	for _, value := range rule.Values {
+		if strings.Contains(ispLower, strings.ToLower(value)) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// Get anonymizer classifications checked by rule, all classifications are
+// checked when none are listed
+func (r *Rule) AnonymizerClasses() []string {
+	if len(r.Values) == 0 {
+		return netlist.Classifications
+	}
+	return r.Values
+}
+
+func matchAnonymizer(rule *Rule, class string) bool {
+	if class == "" {
+		return false
+	}
+
+	for _, value := range rule.AnonymizerClasses() {
+		if value == class {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Get classification that caused a network rule to deny access
+func networkClassification(rule *Rule, ctx *Context) string {
+	switch rule.Type {
+	case WhitelistIsps:
+		return "isp_unlisted"
+	case BlacklistIsps:
+		return "isp:" + matchIsp(rule, ctx.agent().Isp)
+	case Anonymizer:
+		return ctx.Classification
+	}
+	return ""
+}
+
+func auditNetwork(db *database.Database, usr *user.User, r *http.Request,
+	rule *Rule, ctx *Context) (err error) {
+
+	agnt := ctx.agent()
+
+	err = audit.New(
+		db,
+		r,
+		usr.Id,
+		audit.PolicyNetworkBlocked,
+		audit.Fields{
+			"rule":           rule.Type,
+			"classification": networkClassification(rule, ctx),
+			"ip":             agnt.Ip,
+			"isp":            agnt.Isp,
+		},
+	)
+	if err != nil {
+		return
+	}
+
+	return
+}
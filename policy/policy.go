@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net"
 	"net/http"
+	"time"
 
 	"github.com/sirupsen/logrus"
 	"github.com/dropbox/godropbox/container/set"
@@ -12,7 +13,10 @@ import (
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
 	"github.com/pritunl/pritunl-zero/agent"
 	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/endpoint"
 	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/netlist"
 	"github.com/pritunl/pritunl-zero/node"
 	"github.com/pritunl/pritunl-zero/settings"
 	"github.com/pritunl/pritunl-zero/subscription"
@@ -20,9 +24,11 @@ import (
 )
 
 type Rule struct {
-	Type    string   `bson:"type" json:"type"`
-	Disable bool     `bson:"disable" json:"disable"`
-	Values  []string `bson:"values" json:"values"`
+	Type     string   `bson:"type" json:"type"`
+	Disable  bool     `bson:"disable" json:"disable"`
+	Values   []string `bson:"values" json:"values"`
+	Timezone string   `bson:"timezone,omitempty" json:"timezone"`
+	Action   string   `bson:"action,omitempty" json:"action"`
 }
 
 type Policy struct {
@@ -42,6 +48,13 @@ type Policy struct {
 	ProxyDeviceSecondary      bool                 `bson:"proxy_device_secondary" json:"proxy_device_secondary"`
 	AuthorityDeviceSecondary  bool                 `bson:"authority_device_secondary" json:"authority_device_secondary"`
 	AuthorityRequireSmartCard bool                 `bson:"authority_require_smart_card" json:"authority_require_smart_card"`
+	AdminPasskey              bool                 `bson:"admin_passkey" json:"admin_passkey"`
+	UserPasskey               bool                 `bson:"user_passkey" json:"user_passkey"`
+	ProxyPasskey              bool                 `bson:"proxy_passkey" json:"proxy_passkey"`
+	SessionExpire             int                  `bson:"session_expire" json:"session_expire"`
+	SessionMaxDuration        int                  `bson:"session_max_duration" json:"session_max_duration"`
+	ReauthPrimary             int                  `bson:"reauth_primary" json:"reauth_primary"`
+	ReauthSecondary           int                  `bson:"reauth_secondary" json:"reauth_secondary"`
 }
 
 func (p *Policy) Validate(db *database.Database) (
@@ -74,6 +87,54 @@ func (p *Policy) Validate(db *database.Database) (
 			break
 		case BlacklistNetworks:
 			break
+		case Schedule, Blackout:
+			errData = validateSchedule(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case Anomaly:
+			errData = validateAnomaly(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case Expression:
+			errData = validateExpression(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case DevicePosture:
+			errData = validatePosture(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case WhitelistIsps, BlacklistIsps:
+			errData = validateIsps(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case Anonymizer:
+			errData = validateAnonymizer(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case RiskScore:
+			errData = validateRisk(rule)
+			if errData != nil {
+				return
+			}
+			break
+		case MinOperatingSystem, MinBrowser:
+			errData = validateVersion(rule)
+			if errData != nil {
+				return
+			}
+			break
 		default:
 			errData = &errortypes.ErrorData{
 				Error:   "invalid_rule_type",
@@ -185,12 +246,39 @@ func (p *Policy) Validate(db *database.Database) (
 		return
 	}
 
+	errData = p.validateSession()
+	if errData != nil {
+		return
+	}
+
+	if (p.AdminPasskey || p.UserPasskey || p.ProxyPasskey) && !hasUserNode {
+		errData = &errortypes.ErrorData{
+			Error: "user_node_unavailable",
+			Message: "At least one node must have a user domain configured " +
+				"to use passkey authentication",
+		}
+		return
+	}
+
 	return
 }
 
 func (p *Policy) ValidateUser(db *database.Database, usr *user.User,
 	r *http.Request) (errData *errortypes.ErrorData, err error) {
 
+	errData, err = p.ValidateService(db, usr, r, "")
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Validate user with service name available to expression rules
+func (p *Policy) ValidateService(db *database.Database, usr *user.User,
+	r *http.Request, service string) (
+	errData *errortypes.ErrorData, err error) {
+
 	if p.Disabled {
 		return
 	}
@@ -200,194 +288,321 @@ func (p *Policy) ValidateUser(db *database.Database, usr *user.User,
 		return
 	}
 
+	ctx := &Context{
+		User:    usr,
+		Agent:   agnt,
+		Request: r,
+		Service: service,
+		Time:    time.Now(),
+	}
+
+	if p.hasRule(Expression) {
+		ctx.Devices, err = device.GetTypes(db, usr.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	if p.hasRule(DevicePosture) {
+		ctx.Endpoint, ctx.EndpointError, err = endpoint.ParseToken(
+			db, endpoint.GetToken(r), usr.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	if p.hasRule(Anonymizer) {
+		ctx.Classification, err = netlist.Match(
+			db, ctx.agent().Ip, nil)
+		if err != nil {
+			return
+		}
+	}
+
 	for _, rule := range p.Rules {
-		switch rule.Type {
-		case OperatingSystem:
-			match := false
-			for _, value := range rule.Values {
-				if value == agnt.OperatingSystem {
-					match = true
-					break
+		errData, err = p.CheckRule(rule, ctx)
+		if err != nil {
+			return
+		}
+
+		if errData != nil {
+			switch rule.Type {
+			case WhitelistIsps, BlacklistIsps, Anonymizer:
+				err = auditNetwork(db, usr, r, rule, ctx)
+				if err != nil {
+					return
 				}
 			}
 
-			if !match {
-				if rule.Disable {
-					errData = &errortypes.ErrorData{
-						Error:   "unauthorized",
-						Message: "Not authorized",
-					}
-
-					usr.Disabled = true
-					err = usr.CommitFields(db, set.NewSet("disabled"))
-					if err != nil {
-						return
-					}
-				} else {
-					errData = &errortypes.ErrorData{
-						Error:   "operating_system_policy",
-						Message: "Operating system not permitted",
-					}
+			if rule.Disable {
+				errData = &errortypes.ErrorData{
+					Error:   "unauthorized",
+					Message: "Not authorized",
 				}
-				return
-			}
-			break
-		case Browser:
-			match := false
-			for _, value := range rule.Values {
-				if value == agnt.Browser {
-					match = true
-					break
+
+				usr.Disabled = true
+				err = usr.CommitFields(db, set.NewSet("disabled"))
+				if err != nil {
+					return
 				}
 			}
+			return
+		}
+	}
 
-			if !match {
-				if rule.Disable {
-					errData = &errortypes.ErrorData{
-						Error:   "unauthorized",
-						Message: "Not authorized",
-					}
-
-					usr.Disabled = true
-					err = usr.CommitFields(db, set.NewSet("disabled"))
-					if err != nil {
-						return
-					}
-				} else {
-					errData = &errortypes.ErrorData{
-						Error:   "browser_policy",
-						Message: "Browser not permitted",
-					}
-				}
-				return
+	return
+}
+
+// Check rule against context without side effects, returns the denial
+// for a failed rule
+func (p *Policy) CheckRule(rule *Rule, ctx *Context) (
+	errData *errortypes.ErrorData, err error) {
+
+	agnt := ctx.agent()
+	now := ctx.Time
+
+	switch rule.Type {
+	case OperatingSystem:
+		match := false
+		for _, value := range rule.Values {
+			if value == agnt.OperatingSystem {
+				match = true
+				break
 			}
-			break
-		case Location:
-			match := false
-			regionKey := fmt.Sprintf("%s_%s",
-				agnt.CountryCode, agnt.RegionCode)
-
-			for _, value := range rule.Values {
-				if value == agnt.CountryCode || value == regionKey {
-					match = true
-					break
-				}
+		}
+
+		if !match {
+			errData = &errortypes.ErrorData{
+				Error:   "operating_system_policy",
+				Message: "Operating system not permitted",
 			}
+			return
+		}
+		break
+	case Browser:
+		match := false
+		for _, value := range rule.Values {
+			if value == agnt.Browser {
+				match = true
+				break
+			}
+		}
 
-			if !match {
-				if rule.Disable {
-					errData = &errortypes.ErrorData{
-						Error:   "unauthorized",
-						Message: "Not authorized",
-					}
-
-					usr.Disabled = true
-					err = usr.CommitFields(db, set.NewSet("disabled"))
-					if err != nil {
-						return
-					}
-				} else {
-					errData = &errortypes.ErrorData{
-						Error:   "location_policy",
-						Message: "Location not permitted",
-					}
-				}
-				return
+		if !match {
+			errData = &errortypes.ErrorData{
+				Error:   "browser_policy",
+				Message: "Browser not permitted",
 			}
-			break
-		case WhitelistNetworks:
-			match := false
-			clientIp := net.ParseIP(agnt.Ip)
-
-			for _, value := range rule.Values {
-				_, network, e := net.ParseCIDR(value)
-				if e != nil {
-					err = &errortypes.ParseError{
-						errors.Wrap(e, "policy: Failed to parse network"),
-					}
-
-					logrus.WithFields(logrus.Fields{
-						"network": value,
-						"error":   err,
-					}).Error("policy: Invalid whitelist network")
-					err = nil
-					continue
-				}
+			return
+		}
+		break
+	case Location:
+		match := false
+		regionKey := fmt.Sprintf("%s_%s",
+			agnt.CountryCode, agnt.RegionCode)
+
+		for _, value := range rule.Values {
+			if value == agnt.CountryCode || value == regionKey {
+				match = true
+				break
+			}
+		}
 
-				if network.Contains(clientIp) {
-					match = true
-					break
+		if !match {
+			errData = &errortypes.ErrorData{
+				Error:   "location_policy",
+				Message: "Location not permitted",
+			}
+			return
+		}
+		break
+	case WhitelistNetworks:
+		match := false
+		clientIp := net.ParseIP(agnt.Ip)
+
+		for _, value := range rule.Values {
+			_, network, e := net.ParseCIDR(value)
+			if e != nil {
+				err = &errortypes.ParseError{
+					errors.Wrap(e, "policy: Failed to parse network"),
 				}
+
+				logrus.WithFields(logrus.Fields{
+					"network": value,
+					"error":   err,
+				}).Error("policy: Invalid whitelist network")
+				err = nil
+				continue
 			}
 
-			if !match {
-				if rule.Disable {
-					errData = &errortypes.ErrorData{
-						Error:   "unauthorized",
-						Message: "Not authorized",
-					}
-
-					usr.Disabled = true
-					err = usr.CommitFields(db, set.NewSet("disabled"))
-					if err != nil {
-						return
-					}
-				} else {
-					errData = &errortypes.ErrorData{
-						Error:   "whitelist_networks_policy",
-						Message: "Network not permitted",
-					}
-				}
-				return
+			if network.Contains(clientIp) {
+				match = true
+				break
 			}
-			break
-		case BlacklistNetworks:
-			match := false
-			clientIp := net.ParseIP(agnt.Ip)
-
-			for _, value := range rule.Values {
-				_, network, e := net.ParseCIDR(value)
-				if e != nil {
-					err = &errortypes.ParseError{
-						errors.Wrap(e, "policy: Failed to parse network"),
-					}
-
-					logrus.WithFields(logrus.Fields{
-						"network": value,
-						"error":   err,
-					}).Error("policy: Invalid blacklist network")
-					err = nil
-					continue
-				}
+		}
 
-				if network.Contains(clientIp) {
-					match = true
-					break
+		if !match {
+			errData = &errortypes.ErrorData{
+				Error:   "whitelist_networks_policy",
+				Message: "Network not permitted",
+			}
+			return
+		}
+		break
+	case BlacklistNetworks:
+		match := false
+		clientIp := net.ParseIP(agnt.Ip)
+
+		for _, value := range rule.Values {
+			_, network, e := net.ParseCIDR(value)
+			if e != nil {
+				err = &errortypes.ParseError{
+					errors.Wrap(e, "policy: Failed to parse network"),
 				}
+
+				logrus.WithFields(logrus.Fields{
+					"network": value,
+					"error":   err,
+				}).Error("policy: Invalid blacklist network")
+				err = nil
+				continue
 			}
 
-			if match {
-				if rule.Disable {
-					errData = &errortypes.ErrorData{
-						Error:   "unauthorized",
-						Message: "Not authorized",
-					}
-
-					usr.Disabled = true
-					err = usr.CommitFields(db, set.NewSet("disabled"))
-					if err != nil {
-						return
-					}
-				} else {
-					errData = &errortypes.ErrorData{
-						Error:   "blacklist_networks_policy",
-						Message: "Network not permitted",
-					}
-				}
-				return
+			if network.Contains(clientIp) {
+				match = true
+				break
 			}
-			break
 		}
+
+		if match {
+			errData = &errortypes.ErrorData{
+				Error:   "blacklist_networks_policy",
+				Message: "Network not permitted",
+			}
+			return
+		}
+		break
+	case Schedule:
+		match, e := matchSchedule(rule, now)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if !match {
+			errData = &errortypes.ErrorData{
+				Error: "schedule_policy",
+				Message: fmt.Sprintf(
+					"Access only permitted during %s",
+					scheduleLabel(rule)),
+			}
+			return
+		}
+		break
+	case Blackout:
+		blk, e := matchBlackout(rule, now)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if blk != nil {
+			errData = &errortypes.ErrorData{
+				Error: "blackout_policy",
+				Message: fmt.Sprintf(
+					"Access blocked by blackout until %s",
+					blk.end.Format("2006-01-02 15:04 MST")),
+			}
+			return
+		}
+		break
+	case Expression:
+		failed, e := matchExpression(rule, ctx)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if failed != "" {
+			errData = &errortypes.ErrorData{
+				Error:   "expression_policy",
+				Message: "Access not permitted by policy expression",
+			}
+			return
+		}
+		break
+	case DevicePosture:
+		if ctx.Endpoint == nil {
+			message := "Request not from a registered endpoint"
+			if ctx.EndpointError != nil {
+				message = ctx.EndpointError.Message
+			}
+
+			errData = &errortypes.ErrorData{
+				Error:   "device_posture_policy",
+				Message: message,
+			}
+			return
+		}
+
+		reason := matchPosture(rule, ctx.Endpoint, now)
+		if reason != "" {
+			errData = &errortypes.ErrorData{
+				Error:   "device_posture_policy",
+				Message: reason,
+			}
+			return
+		}
+		break
+	case WhitelistIsps:
+		if matchIsp(rule, agnt.Isp) == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "isp_policy",
+				Message: "ISP not permitted",
+			}
+			return
+		}
+		break
+	case BlacklistIsps:
+		if matchIsp(rule, agnt.Isp) != "" {
+			errData = &errortypes.ErrorData{
+				Error:   "isp_policy",
+				Message: "ISP not permitted",
+			}
+			return
+		}
+		break
+	case MinOperatingSystem:
+		if !matchVersion(rule, agnt.Platform,
+			agnt.OperatingSystemVersion) {
+
+			errData = &errortypes.ErrorData{
+				Error:   "operating_system_version_policy",
+				Message: "Operating system version not permitted",
+			}
+			return
+		}
+		break
+	case MinBrowser:
+		if !matchVersion(rule, agnt.Browser, agnt.BrowserVersion) {
+			errData = &errortypes.ErrorData{
+				Error:   "browser_version_policy",
+				Message: "Browser version not permitted",
+			}
+			return
+		}
+		break
+	case Anonymizer:
+		if matchAnonymizer(rule, ctx.Classification) {
+			errData = &errortypes.ErrorData{
+				Error: "anonymizer_policy",
+				Message: fmt.Sprintf(
+					"Access from %s network not permitted",
+					ctx.Classification),
+			}
+			return
+		}
+		break
 	}
 
 	return
@@ -427,6 +642,8 @@ func (p *Policy) Insert(db *database.Database) (err error) {
 		return
 	}
 
+	p.Id = primitive.NewObjectID()
+
 	_, err = coll.InsertOne(db, p)
 	if err != nil {
 		err = database.ParseError(err)
@@ -0,0 +1,139 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+const (
+	postureDefaultSeen = 15 * time.Minute
+)
+
+type posture struct {
+	platforms      []string
+	maxUpdates     int
+	seenWithin     time.Duration
+	requireUpdates bool
+}
+
+func invalidPosture(value string) *errortypes.ErrorData {
+	return &errortypes.ErrorData{
+		Error:   "device_posture_invalid",
+		Message: fmt.Sprintf("Device posture value '%s' is invalid", value),
+	}
+}
+
+// Parse posture values formatted as "platform:ubuntu",
+// "max_package_updates:10" and "seen_within:15" in minutes
+func parsePosture(rule *Rule) (pstr *posture, errData *errortypes.ErrorData) {
+	pstr = &posture{
+		platforms:  []string{},
+		seenWithin: postureDefaultSeen,
+	}
+
+	for _, value := range rule.Values {
+		valueSpl := strings.SplitN(value, ":", 2)
+		if len(valueSpl) != 2 {
+			errData = invalidPosture(value)
+			return
+		}
+
+		key := strings.TrimSpace(valueSpl[0])
+		val := strings.TrimSpace(valueSpl[1])
+
+		switch key {
+		case PosturePlatform:
+			if val == "" {
+				errData = invalidPosture(value)
+				return
+			}
+			pstr.platforms = append(pstr.platforms, strings.ToLower(val))
+			break
+		case PostureMaxPackageUpdates:
+			n, e := strconv.Atoi(val)
+			if e != nil || n < 0 {
+				errData = invalidPosture(value)
+				return
+			}
+			pstr.maxUpdates = n
+			pstr.requireUpdates = true
+			break
+		case PostureSeenWithin:
+			n, e := strconv.Atoi(val)
+			if e != nil || n < 1 {
+				errData = invalidPosture(value)
+				return
+			}
+			pstr.seenWithin = time.Duration(n) * time.Minute
+			break
+		default:
+			errData = invalidPosture(value)
+			return
+		}
+	}
+
+	return
+}
+
+func validatePosture(rule *Rule) (errData *errortypes.ErrorData) {
+	if rule.Values == nil {
+		rule.Values = []string{}
+	}
+
+	_, errData = parsePosture(rule)
+	if errData != nil {
+		return
+	}
+
+	return
+}
+
+// Check endpoint meets posture, returns the reason for a failed check
+func matchPosture(rule *Rule, endpt *endpoint.Endpoint,
+	now time.Time) (reason string) {
+
+	pstr, errData := parsePosture(rule)
+	if errData != nil {
+		reason = errData.Message
+		return
+	}
+
+	data := endpt.Data
+	if data == nil {
+		data = &endpoint.Data{}
+	}
+
+	if data.Timestamp.IsZero() || now.Sub(data.Timestamp) > pstr.seenWithin {
+		reason = "Endpoint has not reported recently"
+		return
+	}
+
+	if len(pstr.platforms) > 0 {
+		platform := strings.ToLower(data.Platform)
+		match := false
+		for _, value := range pstr.platforms {
+			if strings.HasPrefix(platform, value) {
+				match = true
+				break
+			}
+		}
+
+		if !match {
+			reason = "Endpoint platform not permitted"
+			return
+		}
+	}
+
+	if pstr.requireUpdates && data.PackageUpdates > pstr.maxUpdates {
+		reason = fmt.Sprintf("Endpoint has %d pending package updates",
+			data.PackageUpdates)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,112 @@
+package policy
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+var riskActions = map[string]int{
+	Allow:    0,
+	StepUp:   1,
+	Approval: 2,
+	Deny:     3,
+}
+
+type RiskBand struct {
+	Min    int
+	Action string
+}
+
+func invalidRisk(value string) *errortypes.ErrorData {
+	return &errortypes.ErrorData{
+		Error: "risk_score_invalid",
+		Message: fmt.Sprintf(
+			"Risk score band '%s' is invalid, must be score:action", value),
+	}
+}
+
+// Parse risk bands from values formatted as min_score:action sorted by
+// minimum score
+func parseRisk(rule *Rule) (bands []*RiskBand,
+	errData *errortypes.ErrorData) {
+
+	bands = []*RiskBand{}
+
+	for _, value := range rule.Values {
+		parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+		if len(parts) != 2 {
+			errData = invalidRisk(value)
+			return
+		}
+
+		min, e := strconv.Atoi(strings.TrimSpace(parts[0]))
+		if e != nil || min < 0 || min > 100 {
+			errData = invalidRisk(value)
+			return
+		}
+
+		action := strings.TrimSpace(parts[1])
+		if _, ok := riskActions[action]; !ok {
+			errData = invalidRisk(value)
+			return
+		}
+
+		bands = append(bands, &RiskBand{
+			Min:    min,
+			Action: action,
+		})
+	}
+
+	sort.SliceStable(bands, func(i, j int) bool {
+		return bands[i].Min < bands[j].Min
+	})
+
+	return
+}
+
+func validateRisk(rule *Rule) (errData *errortypes.ErrorData) {
+	bands, errData := parseRisk(rule)
+	if errData != nil {
+		return
+	}
+
+	if len(bands) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "risk_score_invalid",
+			Message: "Risk score rule requires at least one band",
+		}
+		return
+	}
+
+	return
+}
+
+// Get action of highest band at or below score, allow when no band matches
+func (r *Rule) RiskAction(score int) (action string) {
+	action = Allow
+
+	bands, errData := parseRisk(r)
+	if errData != nil {
+		return
+	}
+
+	for _, band := range bands {
+		if score >= band.Min {
+			action = band.Action
+		}
+	}
+
+	return
+}
+
+// Get stricter of two risk actions
+func StricterRisk(x, y string) string {
+	if riskActions[y] > riskActions[x] {
+		return y
+	}
+	return x
+}
@@ -0,0 +1,340 @@
+package policy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+const (
+	dateLayout     = "2006-01-02"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+type window struct {
+	days  [7]bool
+	start int
+	end   int
+}
+
+// Check if time is within window, windows ending before they start wrap
+// past midnight and belong to the day they start on
+func (w *window) contains(t time.Time) bool {
+	day := int(t.Weekday())
+	minute := t.Hour()*60 + t.Minute()
+
+	if w.start < w.end {
+		return w.days[day] && minute >= w.start && minute < w.end
+	}
+
+	if w.days[day] && minute >= w.start {
+		return true
+	}
+	return w.days[(day+6)%7] && minute < w.end
+}
+
+type blackout struct {
+	start time.Time
+	end   time.Time
+}
+
+func parseLocation(name string) (loc *time.Location, err error) {
+	if name == "" {
+		loc = time.UTC
+		return
+	}
+
+	loc, err = time.LoadLocation(name)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "policy: Failed to load time zone"),
+		}
+		return
+	}
+
+	return
+}
+
+func parseDays(value string) (days [7]bool, err error) {
+	if value == "*" || value == "all" {
+		for i := range days {
+			days[i] = true
+		}
+		return
+	}
+
+	for _, item := range strings.Split(value, ",") {
+		bounds := strings.SplitN(item, "-", 2)
+
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			err = &errortypes.ParseError{
+				errors.Newf("policy: Invalid weekday '%s'", bounds[0]),
+			}
+			return
+		}
+
+		last := first
+		if len(bounds) == 2 {
+			last, ok = weekdays[bounds[1]]
+			if !ok {
+				err = &errortypes.ParseError{
+					errors.Newf("policy: Invalid weekday '%s'", bounds[1]),
+				}
+				return
+			}
+		}
+
+		for day := first; ; day = (day + 1) % 7 {
+			days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+
+	return
+}
+
+func parseClock(value string) (minute int, err error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 2 {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid time '%s'", value),
+		}
+		return
+	}
+
+	hour, e1 := strconv.Atoi(parts[0])
+	min, e2 := strconv.Atoi(parts[1])
+	if e1 != nil || e2 != nil || hour < 0 || hour > 24 ||
+		min < 0 || min > 59 || (hour == 24 && min != 0) {
+
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid time '%s'", value),
+		}
+		return
+	}
+
+	minute = hour*60 + min
+
+	return
+}
+
+// Parse window such as "mon-fri 09:00-17:00" or "sat,sun 22:00-02:00"
+func parseWindow(value string) (win *window, err error) {
+	fields := strings.Fields(strings.ToLower(value))
+	if len(fields) != 2 {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid schedule '%s'", value),
+		}
+		return
+	}
+
+	days, err := parseDays(fields[0])
+	if err != nil {
+		return
+	}
+
+	hours := strings.SplitN(fields[1], "-", 2)
+	if len(hours) != 2 {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid schedule hours '%s'", fields[1]),
+		}
+		return
+	}
+
+	start, err := parseClock(hours[0])
+	if err != nil {
+		return
+	}
+
+	end, err := parseClock(hours[1])
+	if err != nil {
+		return
+	}
+
+	if start == end {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Empty schedule hours '%s'", fields[1]),
+		}
+		return
+	}
+
+	win = &window{
+		days:  days,
+		start: start,
+		end:   end,
+	}
+
+	return
+}
+
+func parseBlackoutTime(value string, loc *time.Location, end bool) (
+	t time.Time, err error) {
+
+	value = strings.TrimSpace(value)
+
+	t, err = time.ParseInLocation(dateTimeLayout, value, loc)
+	if err == nil {
+		return
+	}
+
+	t, err = time.ParseInLocation(dateLayout, value, loc)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid blackout date '%s'", value),
+		}
+		return
+	}
+
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+
+	return
+}
+
+// Parse blackout such as "2024-12-20/2025-01-02" with inclusive end dates
+// or "2024-12-20 18:00/2025-01-02 08:00"
+func parseBlackout(value string, loc *time.Location) (
+	blk *blackout, err error) {
+
+	bounds := strings.SplitN(value, "/", 2)
+	if len(bounds) != 2 {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Invalid blackout '%s'", value),
+		}
+		return
+	}
+
+	start, err := parseBlackoutTime(bounds[0], loc, false)
+	if err != nil {
+		return
+	}
+
+	end, err := parseBlackoutTime(bounds[1], loc, true)
+	if err != nil {
+		return
+	}
+
+	if !end.After(start) {
+		err = &errortypes.ParseError{
+			errors.Newf("policy: Blackout '%s' ends before start", value),
+		}
+		return
+	}
+
+	blk = &blackout{
+		start: start,
+		end:   end,
+	}
+
+	return
+}
+
+func validateSchedule(rule *Rule) (errData *errortypes.ErrorData) {
+	loc, err := parseLocation(rule.Timezone)
+	if err != nil {
+		errData = &errortypes.ErrorData{
+			Error:   "schedule_timezone_invalid",
+			Message: fmt.Sprintf("Time zone '%s' is invalid", rule.Timezone),
+		}
+		return
+	}
+
+	if len(rule.Values) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "schedule_missing",
+			Message: "Schedule rule requires at least one entry",
+		}
+		return
+	}
+
+	for _, value := range rule.Values {
+		if rule.Type == Schedule {
+			_, err = parseWindow(value)
+		} else {
+			_, err = parseBlackout(value, loc)
+		}
+		if err != nil {
+			errData = &errortypes.ErrorData{
+				Error:   "schedule_invalid",
+				Message: fmt.Sprintf("Schedule entry '%s' is invalid", value),
+			}
+			return
+		}
+	}
+
+	return
+}
+
+func matchSchedule(rule *Rule, now time.Time) (match bool, err error) {
+	loc, err := parseLocation(rule.Timezone)
+	if err != nil {
+		return
+	}
+	now = now.In(loc)
+
+	for _, value := range rule.Values {
+		win, e := parseWindow(value)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if win.contains(now) {
+			match = true
+			return
+		}
+	}
+
+	return
+}
+
+func matchBlackout(rule *Rule, now time.Time) (
+	blk *blackout, err error) {
+
+	loc, err := parseLocation(rule.Timezone)
+	if err != nil {
+		return
+	}
+
+	for _, value := range rule.Values {
+		b, e := parseBlackout(value, loc)
+		if e != nil {
+			err = e
+			return
+		}
+
+		if !now.Before(b.start) && now.Before(b.end) {
+			blk = b
+			return
+		}
+	}
+
+	return
+}
+
+func scheduleLabel(rule *Rule) string {
+	tz := rule.Timezone
+	if tz == "" {
+		tz = "UTC"
+	}
+
+	return fmt.Sprintf("%s (%s)", strings.Join(rule.Values, ", "), tz)
+}
@@ -0,0 +1,88 @@
+package policy
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/session"
+)
+
+// Proxy login performs a secondary only with a service secondary, the
+// authority secondary is performed with each challenge
+func (p *Policy) proxySecondary() bool {
+	return !p.ProxySecondary.IsZero() || p.ProxyDeviceSecondary
+}
+
+func (p *Policy) validateSession() (errData *errortypes.ErrorData) {
+	if p.SessionExpire < 0 || p.SessionMaxDuration < 0 ||
+		p.ReauthPrimary < 0 || p.ReauthSecondary < 0 {
+
+		errData = &errortypes.ErrorData{
+			Error:   "session_limit_invalid",
+			Message: "Session limits cannot be negative",
+		}
+		return
+	}
+
+	if p.ReauthSecondary != 0 && !p.proxySecondary() {
+		errData = &errortypes.ErrorData{
+			Error: "reauth_secondary_unavailable",
+			Message: "Secondary reauthentication requires a service " +
+				"secondary authentication provider or device",
+		}
+		return
+	}
+
+	return
+}
+
+func minLimit(cur time.Duration, minutes int) time.Duration {
+	if minutes == 0 {
+		return cur
+	}
+
+	limit := time.Duration(minutes) * time.Minute
+	if cur == 0 || limit < cur {
+		return limit
+	}
+	return cur
+}
+
+// Get strictest session limits from policies, nil when no policy sets
+// limits. Secondary reauthentication only applies from policies with a
+// service secondary.
+func SessionLimits(policies []*Policy) (limits *session.Limits) {
+	lmts := &session.Limits{}
+	set := false
+
+	for _, polcy := range policies {
+		if polcy.Disabled {
+			continue
+		}
+
+		reauthSecondary := polcy.ReauthSecondary
+		if !polcy.proxySecondary() {
+			reauthSecondary = 0
+		}
+
+		if polcy.SessionExpire != 0 || polcy.SessionMaxDuration != 0 ||
+			polcy.ReauthPrimary != 0 || reauthSecondary != 0 {
+
+			set = true
+		}
+
+		lmts.Expire = minLimit(lmts.Expire, polcy.SessionExpire)
+		lmts.MaxDuration = minLimit(lmts.MaxDuration,
+			polcy.SessionMaxDuration)
+		lmts.ReauthPrimary = minLimit(lmts.ReauthPrimary,
+			polcy.ReauthPrimary)
+		lmts.ReauthSecondary = minLimit(lmts.ReauthSecondary,
+			reauthSecondary)
+	}
+
+	if set {
+		limits = lmts
+	}
+
+	return
+}
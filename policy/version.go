@@ -0,0 +1,94 @@
+package policy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type minVersion struct {
+	name    string
+	version []int
+}
+
+// Parse minimum versions from values formatted as name:version such as
+// chrome:120 or ios:17.2
+func parseVersions(rule *Rule) (mins []*minVersion,
+	errData *errortypes.ErrorData) {
+
+	mins = []*minVersion{}
+
+	for _, value := range rule.Values {
+		parts := strings.SplitN(strings.TrimSpace(value), ":", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			errData = &errortypes.ErrorData{
+				Error: "min_version_invalid",
+				Message: fmt.Sprintf(
+					"Minimum version '%s' is invalid, must be name:version",
+					value),
+			}
+			return
+		}
+
+		ver, ok := agent.ParseVersion(parts[1])
+		if !ok {
+			errData = &errortypes.ErrorData{
+				Error: "min_version_invalid",
+				Message: fmt.Sprintf(
+					"Minimum version '%s' is invalid, must be name:version",
+					value),
+			}
+			return
+		}
+
+		mins = append(mins, &minVersion{
+			name:    strings.ToLower(strings.TrimSpace(parts[0])),
+			version: ver,
+		})
+	}
+
+	return
+}
+
+func validateVersion(rule *Rule) (errData *errortypes.ErrorData) {
+	mins, errData := parseVersions(rule)
+	if errData != nil {
+		return
+	}
+
+	if len(mins) == 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "min_version_invalid",
+			Message: "Minimum version rule requires at least one version",
+		}
+		return
+	}
+
+	return
+}
+
+// Check version against minimum for name, names without a minimum in the
+// rule are permitted and unknown versions of listed names are not
+func matchVersion(rule *Rule, name, version string) bool {
+	mins, errData := parseVersions(rule)
+	if errData != nil {
+		return false
+	}
+
+	for _, min := range mins {
+		if min.name != name {
+			continue
+		}
+
+		ver, ok := agent.ParseVersion(version)
+		if !ok {
+			return false
+		}
+
+		return agent.CompareVersion(ver, min.version) >= 0
+	}
+
+	return true
+}
@@ -0,0 +1,79 @@
+package prometheus
+
+import (
+	"time"
+)
+
+const (
+	BufferSize   = 4096
+	BatchSize    = 500
+	FlushRate    = 5 * time.Second
+	SendTimeout  = 15 * time.Second
+	exportStale  = 5 * time.Minute
+	metricPrefix = "pritunl_zero_"
+)
+
+const (
+	SystemProcesses  = metricPrefix + "system_processes"
+	SystemCpuUsage   = metricPrefix + "system_cpu_usage_percent"
+	SystemMemUsage   = metricPrefix + "system_memory_usage_percent"
+	SystemHugeUsage  = metricPrefix + "system_hugepages_usage_percent"
+	SystemSwapUsage  = metricPrefix + "system_swap_usage_percent"
+	Load1            = metricPrefix + "load1"
+	Load5            = metricPrefix + "load5"
+	Load15           = metricPrefix + "load15"
+	DiskUsed         = metricPrefix + "disk_used_percent"
+	DiskIoReadBytes  = metricPrefix + "diskio_read_bytes"
+	DiskIoWriteBytes = metricPrefix + "diskio_write_bytes"
+	DiskIoReadCount  = metricPrefix + "diskio_read_count"
+	DiskIoWriteCount = metricPrefix + "diskio_write_count"
+	DiskIoReadTime   = metricPrefix + "diskio_read_time_ms"
+	DiskIoWriteTime  = metricPrefix + "diskio_write_time_ms"
+	DiskIoTime       = metricPrefix + "diskio_io_time_ms"
+	NetBytesSent     = metricPrefix + "network_sent_bytes"
+	NetBytesRecv     = metricPrefix + "network_received_bytes"
+	NetPacketsSent   = metricPrefix + "network_sent_packets"
+	NetPacketsRecv   = metricPrefix + "network_received_packets"
+	NetErrorsSent    = metricPrefix + "network_sent_errors"
+	NetErrorsRecv    = metricPrefix + "network_received_errors"
+	NetDropsSent     = metricPrefix + "network_sent_drops"
+	NetDropsRecv     = metricPrefix + "network_received_drops"
+	CheckTargetsUp   = metricPrefix + "check_targets_up"
+	CheckTargetsDown = metricPrefix + "check_targets_down"
+	CheckLatency     = metricPrefix + "check_latency_ms"
+)
+
+// Metric help text in exposition order, counter values are reported as the
+// change during the last sample interval
+var metrics = []struct {
+	Name string
+	Help string
+}{
+	{SystemProcesses, "Number of processes"},
+	{SystemCpuUsage, "CPU usage percent"},
+	{SystemMemUsage, "Memory usage percent"},
+	{SystemHugeUsage, "Huge pages usage percent"},
+	{SystemSwapUsage, "Swap usage percent"},
+	{Load1, "Load average over 1 minute"},
+	{Load5, "Load average over 5 minutes"},
+	{Load15, "Load average over 15 minutes"},
+	{DiskUsed, "Disk mount used percent"},
+	{DiskIoReadBytes, "Bytes read during the last sample interval"},
+	{DiskIoWriteBytes, "Bytes written during the last sample interval"},
+	{DiskIoReadCount, "Reads completed during the last sample interval"},
+	{DiskIoWriteCount, "Writes completed during the last sample interval"},
+	{DiskIoReadTime, "Time spent reading during the last sample interval"},
+	{DiskIoWriteTime, "Time spent writing during the last sample interval"},
+	{DiskIoTime, "Time spent doing io during the last sample interval"},
+	{NetBytesSent, "Bytes sent during the last sample interval"},
+	{NetBytesRecv, "Bytes received during the last sample interval"},
+	{NetPacketsSent, "Packets sent during the last sample interval"},
+	{NetPacketsRecv, "Packets received during the last sample interval"},
+	{NetErrorsSent, "Send errors during the last sample interval"},
+	{NetErrorsRecv, "Receive errors during the last sample interval"},
+	{NetDropsSent, "Send drops during the last sample interval"},
+	{NetDropsRecv, "Receive drops during the last sample interval"},
+	{CheckTargetsUp, "Check targets passing"},
+	{CheckTargetsDown, "Check targets failing"},
+	{CheckLatency, "Check average target latency"},
+}
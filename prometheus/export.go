@@ -0,0 +1,211 @@
+package prometheus
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoints"
+)
+
+var (
+	exportTypes = []string{
+		"system",
+		"load",
+		"disk",
+		"diskio",
+		"network",
+	}
+	labelReplacer = strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+	)
+)
+
+type latestDoc struct {
+	Doc bson.Raw `bson:"doc"`
+}
+
+func getLatest(db *database.Database, typ string, group interface{},
+	since time.Time) (docs []endpoints.Doc, err error) {
+
+	coll := endpoints.GetObj(typ).GetCollection(db)
+	docs = []endpoints.Doc{}
+
+	cursor, err := coll.Aggregate(db, []*bson.M{
+		&bson.M{
+			"$match": &bson.M{
+				"t": &bson.M{
+					"$gte": since,
+				},
+			},
+		},
+		&bson.M{
+			"$sort": &bson.M{
+				"t": -1,
+			},
+		},
+		&bson.M{
+			"$group": &bson.M{
+				"_id": group,
+				"doc": &bson.M{
+					"$first": "$$ROOT",
+				},
+			},
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		latest := &latestDoc{}
+		err = cursor.Decode(latest)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		doc := endpoints.GetObj(typ)
+		err = bson.Unmarshal(latest.Doc, doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		docs = append(docs, doc)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func formatSample(buf *bytes.Buffer, smpl *Sample) {
+	buf.WriteString(smpl.Name)
+	buf.WriteString("{")
+	for i, lbl := range smpl.Labels {
+		if i != 0 {
+			buf.WriteString(",")
+		}
+		buf.WriteString(lbl.Name)
+		buf.WriteString(`="`)
+		buf.WriteString(labelReplacer.Replace(lbl.Value))
+		buf.WriteString(`"`)
+	}
+	buf.WriteString("} ")
+	buf.WriteString(strconv.FormatFloat(smpl.Value, 'g', -1, 64))
+	buf.WriteString("\n")
+}
+
+// Get latest values for targets in prometheus text exposition format,
+// checks contains check names used for check labels
+func Export(db *database.Database, targets []*Target,
+	checks map[primitive.ObjectID]string) (data []byte, err error) {
+
+	since := time.Now().Add(-exportStale)
+
+	targetsMap := map[primitive.ObjectID]*Target{}
+	for _, target := range targets {
+		targetsMap[target.Id] = target
+	}
+
+	metricSamples := map[string][]*Sample{}
+	addSamples := func(smpls []*Sample) {
+		for _, smpl := range smpls {
+			metricSamples[smpl.Name] = append(
+				metricSamples[smpl.Name], smpl)
+		}
+	}
+
+	for _, typ := range exportTypes {
+		docs, e := getLatest(db, typ, "$e", since)
+		if e != nil {
+			err = e
+			return
+		}
+
+		latest := map[primitive.ObjectID]endpoints.Doc{}
+		for _, doc := range docs {
+			latest[docEndpoint(doc)] = doc
+		}
+
+		for _, target := range targets {
+			doc := latest[target.Id]
+			if doc != nil {
+				addSamples(samples(target, doc, ""))
+			}
+		}
+	}
+
+	docs, err := getLatest(db, "check", &bson.D{
+		{"c", "$c"},
+		{"e", "$e"},
+	}, since)
+	if err != nil {
+		return
+	}
+
+	for _, doc := range docs {
+		chck := doc.(*endpoints.Check)
+
+		target := targetsMap[chck.Endpoint]
+		if target == nil {
+			continue
+		}
+
+		name, ok := checks[chck.Check]
+		if !ok {
+			continue
+		}
+
+		addSamples(samples(target, chck, name))
+	}
+
+	buf := &bytes.Buffer{}
+	for _, metric := range metrics {
+		smpls := metricSamples[metric.Name]
+		if len(smpls) == 0 {
+			continue
+		}
+
+		buf.WriteString("# HELP " + metric.Name + " " + metric.Help + "\n")
+		buf.WriteString("# TYPE " + metric.Name + " gauge\n")
+		for _, smpl := range smpls {
+			formatSample(buf, smpl)
+		}
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+func docEndpoint(doc endpoints.Doc) primitive.ObjectID {
+	switch d := doc.(type) {
+	case *endpoints.System:
+		return d.Endpoint
+	case *endpoints.Load:
+		return d.Endpoint
+	case *endpoints.Disk:
+		return d.Endpoint
+	case *endpoints.DiskIo:
+		return d.Endpoint
+	case *endpoints.Network:
+		return d.Endpoint
+	case *endpoints.Check:
+		return d.Endpoint
+	default:
+		return primitive.NilObjectID
+	}
+}
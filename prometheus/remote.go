@@ -0,0 +1,178 @@
+package prometheus
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/golang/snappy"
+	"github.com/pritunl/pritunl-zero/endpoints"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/requires"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+var (
+	buffer chan *Sample
+	client = &http.Client{
+		Timeout: SendTimeout,
+	}
+)
+
+// Queue doc samples for remote write, samples are dropped when no receiver
+// is configured or the buffer is full
+func Write(target *Target, doc endpoints.Doc) {
+	if settings.Prometheus.RemoteWriteUrl == "" {
+		return
+	}
+
+	for _, smpl := range Samples(target, doc) {
+		select {
+		case buffer <- smpl:
+		default:
+		}
+	}
+}
+
+// Encode samples as remote write protobuf WriteRequest with one time
+// series for each sample
+func encode(smpls []*Sample) []byte {
+	var data []byte
+
+	for _, smpl := range smpls {
+		labels := make([]*Label, 0, len(smpl.Labels)+1)
+		labels = append(labels, &Label{"__name__", smpl.Name})
+		labels = append(labels, smpl.Labels...)
+		sort.SliceStable(labels, func(i, j int) bool {
+			return labels[i].Name < labels[j].Name
+		})
+
+		var series []byte
+		for _, lbl := range labels {
+			var label []byte
+			label = protowire.AppendTag(label, 1, protowire.BytesType)
+			label = protowire.AppendString(label, lbl.Name)
+			label = protowire.AppendTag(label, 2, protowire.BytesType)
+			label = protowire.AppendString(label, lbl.Value)
+
+			series = protowire.AppendTag(series, 1, protowire.BytesType)
+			series = protowire.AppendBytes(series, label)
+		}
+
+		var sample []byte
+		sample = protowire.AppendTag(sample, 1, protowire.Fixed64Type)
+		sample = protowire.AppendFixed64(sample, math.Float64bits(smpl.Value))
+		sample = protowire.AppendTag(sample, 2, protowire.VarintType)
+		sample = protowire.AppendVarint(sample,
+			uint64(smpl.Timestamp.UnixMilli()))
+
+		series = protowire.AppendTag(series, 2, protowire.BytesType)
+		series = protowire.AppendBytes(series, sample)
+
+		data = protowire.AppendTag(data, 1, protowire.BytesType)
+		data = protowire.AppendBytes(data, series)
+	}
+
+	return data
+}
+
+func send(smpls []*Sample) (err error) {
+	url := settings.Prometheus.RemoteWriteUrl
+	if url == "" {
+		return
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		url,
+		bytes.NewReader(snappy.Encode(nil, encode(smpls))),
+	)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "prometheus: Failed to create request"),
+		}
+		return
+	}
+
+	req.Header.Set("Content-Encoding", "snappy")
+	req.Header.Set("Content-Type", "application/x-protobuf")
+	req.Header.Set("User-Agent", "pritunl-zero")
+	req.Header.Set("X-Prometheus-Remote-Write-Version", "0.1.0")
+
+	if settings.Prometheus.RemoteWriteUsername != "" {
+		req.SetBasicAuth(settings.Prometheus.RemoteWriteUsername,
+			settings.Prometheus.RemoteWritePassword)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		err = &errortypes.RequestError{
+			errors.Wrap(err, "prometheus: Remote write request failed"),
+		}
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		err = &errortypes.RequestError{
+			errors.Newf(
+				"prometheus: Remote write bad status %d '%s'",
+				resp.StatusCode, string(body),
+			),
+		}
+		return
+	}
+
+	return
+}
+
+func worker() {
+	batch := []*Sample{}
+	ticker := time.NewTicker(FlushRate)
+
+	for {
+		select {
+		case smpl := <-buffer:
+			batch = append(batch, smpl)
+			if len(batch) < BatchSize {
+				continue
+			}
+			break
+		case <-ticker.C:
+			if len(batch) == 0 {
+				continue
+			}
+			break
+		}
+
+		err := send(batch)
+		if err != nil {
+			logrus.WithFields(logrus.Fields{
+				"samples": len(batch),
+				"error":   err,
+			}).Error("prometheus: Failed to send samples")
+		}
+
+		batch = []*Sample{}
+	}
+}
+
+func init() {
+	buffer = make(chan *Sample, BufferSize)
+
+	module := requires.New("prometheus")
+	module.After("settings")
+
+	module.Handler = func() (err error) {
+		go worker()
+		return
+	}
+}
@@ -0,0 +1,143 @@
+package prometheus
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/endpoints"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+type Sample struct {
+	Name      string
+	Labels    []*Label
+	Value     float64
+	Timestamp time.Time
+}
+
+type Target struct {
+	Id    primitive.ObjectID
+	Name  string
+	Roles []string
+}
+
+func (t *Target) labels() []*Label {
+	roles := make([]string, len(t.Roles))
+	copy(roles, t.Roles)
+	sort.Strings(roles)
+
+	return []*Label{
+		{"endpoint", t.Name},
+		{"endpoint_id", t.Id.Hex()},
+		{"roles", strings.Join(roles, ",")},
+	}
+}
+
+type sampler struct {
+	labels    []*Label
+	timestamp time.Time
+	samples   []*Sample
+}
+
+func (s *sampler) add(name string, value float64, labels ...*Label) {
+	lbls := make([]*Label, 0, len(s.labels)+len(labels))
+	lbls = append(lbls, labels...)
+	lbls = append(lbls, s.labels...)
+
+	s.samples = append(s.samples, &Sample{
+		Name:      name,
+		Labels:    lbls,
+		Value:     value,
+		Timestamp: s.timestamp,
+	})
+}
+
+// Get samples for endpoint doc, docs without metrics return no samples
+func Samples(target *Target, doc endpoints.Doc) []*Sample {
+	return samples(target, doc, "")
+}
+
+func samples(target *Target, doc endpoints.Doc,
+	checkName string) []*Sample {
+
+	smplr := &sampler{
+		labels:  target.labels(),
+		samples: []*Sample{},
+	}
+
+	switch d := doc.(type) {
+	case *endpoints.System:
+		smplr.timestamp = d.Timestamp
+		smplr.add(SystemProcesses, float64(d.Processes))
+		smplr.add(SystemCpuUsage, d.CpuUsage)
+		smplr.add(SystemMemUsage, d.MemUsage)
+		smplr.add(SystemHugeUsage, d.HugeUsage)
+		smplr.add(SystemSwapUsage, d.SwapUsage)
+		break
+	case *endpoints.Load:
+		smplr.timestamp = d.Timestamp
+		smplr.add(Load1, d.Load1)
+		smplr.add(Load5, d.Load5)
+		smplr.add(Load15, d.Load15)
+		break
+	case *endpoints.Disk:
+		smplr.timestamp = d.Timestamp
+		for _, mount := range d.Mounts {
+			smplr.add(DiskUsed, mount.Used, &Label{"mount", mount.Path})
+		}
+		break
+	case *endpoints.DiskIo:
+		smplr.timestamp = d.Timestamp
+		for _, disk := range d.Disks {
+			lbl := &Label{"disk", disk.Name}
+			smplr.add(DiskIoReadBytes, float64(disk.BytesRead), lbl)
+			smplr.add(DiskIoWriteBytes, float64(disk.BytesWrite), lbl)
+			smplr.add(DiskIoReadCount, float64(disk.CountRead), lbl)
+			smplr.add(DiskIoWriteCount, float64(disk.CountWrite), lbl)
+			smplr.add(DiskIoReadTime, float64(disk.TimeRead), lbl)
+			smplr.add(DiskIoWriteTime, float64(disk.TimeWrite), lbl)
+			smplr.add(DiskIoTime, float64(disk.TimeIo), lbl)
+		}
+		break
+	case *endpoints.Network:
+		smplr.timestamp = d.Timestamp
+		for _, iface := range d.Interfaces {
+			lbl := &Label{"interface", iface.Name}
+			smplr.add(NetBytesSent, float64(iface.BytesSent), lbl)
+			smplr.add(NetBytesRecv, float64(iface.BytesRecv), lbl)
+			smplr.add(NetPacketsSent, float64(iface.PacketsSent), lbl)
+			smplr.add(NetPacketsRecv, float64(iface.PacketsRecv), lbl)
+			smplr.add(NetErrorsSent, float64(iface.ErrorsSent), lbl)
+			smplr.add(NetErrorsRecv, float64(iface.ErrorsRecv), lbl)
+			smplr.add(NetDropsSent, float64(iface.DropsSent), lbl)
+			smplr.add(NetDropsRecv, float64(iface.DropsRecv), lbl)
+		}
+		break
+	case *endpoints.Check:
+		smplr.timestamp = d.Timestamp
+		name := checkName
+		if name == "" {
+			name = d.CheckName()
+		}
+		if name == "" {
+			name = d.Check.Hex()
+		}
+		checkLbl := &Label{"check", name}
+		checkIdLbl := &Label{"check_id", d.Check.Hex()}
+		smplr.add(CheckTargetsUp, float64(d.TargetsUp),
+			checkLbl, checkIdLbl)
+		smplr.add(CheckTargetsDown, float64(d.TargetsDown),
+			checkLbl, checkIdLbl)
+		smplr.add(CheckLatency, float64(d.LatencyAvg),
+			checkLbl, checkIdLbl)
+		break
+	}
+
+	return smplr.samples
+}
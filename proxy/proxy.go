@@ -22,6 +22,7 @@ import (
 	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/session"
 	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/statuspage"
 	"github.com/pritunl/pritunl-zero/utils"
 	"github.com/pritunl/pritunl-zero/validator"
 	"github.com/sirupsen/logrus"
@@ -34,6 +35,7 @@ type Host struct {
 	WhitelistNetworks []*net.IPNet
 	ClientAuthority   *authority.Authority
 	ClientCertificate *tls.Certificate
+	StatusPage        *statuspage.StatusPage
 }
 
 type Proxy struct {
@@ -62,6 +64,10 @@ func (p *Proxy) MatchHost(domain string) (hst *Host, wildcard bool) {
 func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) bool {
 	host, wildcard := p.MatchHost(utils.StripPort(r.Host))
 
+	if host != nil && host.StatusPage != nil {
+		return p.serveStatusPage(w, r, host)
+	}
+
 	var hostId primitive.ObjectID
 	if host == nil {
 		hostId = primitive.NilObjectID
@@ -163,72 +169,112 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) bool {
 		return true
 	}
 
-	authr, err := authorizer.AuthorizeProxy(db, host.Service, w, r)
-	if err != nil {
-		WriteError(w, r, 500, err)
+	authr, handled := p.authorize(db, host, w, r)
+	if authr == nil {
+		return handled
+	}
+
+	if wsLen != 0 && strings.ToLower(r.Header.Get("Upgrade")) == "websocket" {
+		wsProxies[rand.Intn(wsLen)].ServeHTTP(w, r, db, authr)
 		return true
 	}
 
-	if !authr.IsValid() {
-		err = authr.Clear(db, w, r)
+	if host.Service.MatchLogoutPath(r.URL.Path) {
+		err := authr.Clear(db, w, r)
 		if err != nil {
 			WriteError(w, r, 500, err)
 			return true
 		}
 
-		return false
+		http.Redirect(w, r, "/", 302)
+		return true
 	}
 
-	usr, err := authr.GetUser(db)
+	wProxies[rand.Intn(wLen)].ServeHTTP(w, r, authr)
+	return true
+}
+
+// Authorize request with the host service, authr is nil when the request
+// is not authorized and handled is true when a response has been written
+func (p *Proxy) authorize(db *database.Database, host *Host,
+	w http.ResponseWriter, r *http.Request) (
+	authr *authorizer.Authorizer, handled bool) {
+
+	authrProxy, err := authorizer.AuthorizeProxy(db, host.Service, w, r)
 	if err != nil {
 		WriteError(w, r, 500, err)
-		return true
+		handled = true
+		return
+	}
+
+	if !authrProxy.IsValid() {
+		err = authrProxy.Clear(db, w, r)
+		if err != nil {
+			WriteError(w, r, 500, err)
+			handled = true
+			return
+		}
+
+		return
+	}
+
+	usr, err := authrProxy.GetUser(db)
+	if err != nil {
+		WriteError(w, r, 500, err)
+		handled = true
+		return
 	}
 
 	if usr == nil {
-		err = authr.Clear(db, w, r)
+		err = authrProxy.Clear(db, w, r)
 		if err != nil {
 			WriteError(w, r, 500, err)
-			return true
+			handled = true
+			return
 		}
 
-		return false
+		return
 	}
 
 	active, err := auth.SyncUser(db, usr)
 	if err != nil {
 		WriteError(w, r, 500, err)
-		return true
+		handled = true
+		return
 	}
 
 	if !active {
 		err = session.RemoveAll(db, usr.Id)
 		if err != nil {
 			WriteError(w, r, 500, err)
-			return true
+			handled = true
+			return
 		}
 
-		err = authr.Clear(db, w, r)
+		err = authrProxy.Clear(db, w, r)
 		if err != nil {
 			WriteError(w, r, 500, err)
-			return true
+			handled = true
+			return
 		}
 
-		return false
+		return
 	}
 
 	_, _, errAudit, errData, err := validator.ValidateProxy(
-		db, usr, authr.IsApi(), host.Service, r)
+		db, usr, authrProxy.IsApi(), host.Service, r)
 	if err != nil {
 		WriteError(w, r, 500, err)
-		return true
+		handled = true
+		return
 	}
 
 	if errData != nil {
-		err = authr.Clear(db, w, r)
+		err = authrProxy.Clear(db, w, r)
 		if err != nil {
 			WriteError(w, r, 500, err)
-			return true
+			handled = true
+			return
 		}
 
 		if errAudit == nil {
@@ -248,29 +294,49 @@ func (p *Proxy) ServeHTTP(w http.ResponseWriter, r *http.Request) bool {
 		)
 		if err != nil {
 			WriteError(w, r, 500, err)
-			return true
+			handled = true
+			return
 		}
 
-		return false
+		return
 	}
 
-	if wsLen != 0 && strings.ToLower(r.Header.Get("Upgrade")) == "websocket" {
-		wsProxies[rand.Intn(wsLen)].ServeHTTP(w, r, db, authr)
-		return true
-	}
+	authr = authrProxy
+	return
+}
 
-	if host.Service.MatchLogoutPath(r.URL.Path) {
-		err = authr.Clear(db, w, r)
-		if err != nil {
-			WriteError(w, r, 500, err)
-			return true
+func (p *Proxy) serveStatusPage(w http.ResponseWriter, r *http.Request,
+	host *Host) bool {
+
+	db := database.GetDatabase()
+	defer db.Close()
+
+	if host.Service != nil {
+		if !host.Service.DisableCsrfCheck {
+			valid := auth.CsrfCheck(w, r, host.Domain.Domain, false)
+			if !valid {
+				return true
+			}
 		}
 
-		http.Redirect(w, r, "/", 302)
-		return true
+		authr, handled := p.authorize(db, host, w, r)
+		if authr == nil {
+			return handled
+		}
+
+		if host.Service.MatchLogoutPath(r.URL.Path) {
+			err := authr.Clear(db, w, r)
+			if err != nil {
+				WriteError(w, r, 500, err)
+				return true
+			}
+
+			http.Redirect(w, r, "/", 302)
+			return true
+		}
 	}
 
-	wProxies[rand.Intn(wLen)].ServeHTTP(w, r, authr)
+	statuspage.ServeHTTP(w, r, db, host.StatusPage)
 	return true
 }
 
@@ -390,6 +456,50 @@ func (p *Proxy) reloadHosts(db *database.Database,
 		}
 	}
 
+	pages, err := statuspage.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, page := range pages {
+		if _, ok := hosts[page.Domain]; ok {
+			logrus.WithFields(logrus.Fields{
+				"status_page_id": page.Id.Hex(),
+				"domain":         page.Domain,
+			}).Warn("proxy: Status page domain conflicts with service")
+			continue
+		}
+
+		var pageSrvc *service.Service
+		if page.AuthRequired() {
+			for _, srvc := range srvcs {
+				if srvc.Id == page.Service {
+					pageSrvc = srvc
+					break
+				}
+			}
+
+			if pageSrvc == nil {
+				logrus.WithFields(logrus.Fields{
+					"status_page_id": page.Id.Hex(),
+					"service_id":     page.Service.Hex(),
+				}).Warn("proxy: Status page service not found")
+				continue
+			}
+		}
+
+		facets = append(facets, fmt.Sprintf("https://%s", page.Domain))
+
+		hosts[page.Domain] = &Host{
+			Id:      primitive.NewObjectID(),
+			Service: pageSrvc,
+			Domain: &service.Domain{
+				Domain: page.Domain,
+			},
+			StatusPage: page,
+		}
+	}
+
 	settings.Local.AppId = appId
 	settings.Local.Facets = facets
 
@@ -408,6 +518,10 @@ func (p *Proxy) reloadProxies(db *database.Database, proto string, port int) (
 
 	for _, hostSet := range []map[string]*Host{p.Hosts, p.WildcardHosts} {
 		for _, host := range hostSet {
+			if host.StatusPage != nil {
+				continue
+			}
+
 			domainProxies := []*web{}
 			for _, server := range host.Service.Servers {
 				prxy := newWeb(proto, port, host, server)
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/sirupsen/logrus"
+	"github.com/pritunl/pritunl-zero/endpoint"
 	"github.com/pritunl/pritunl-zero/node"
 	"github.com/pritunl/pritunl-zero/utils"
 )
@@ -25,8 +26,17 @@ func stripCookieHeaders(r *http.Request) {
 	r.Header.Del("Pritunl-Zero-Nonce")
 
 	cookie := r.Header.Get("Cookie")
-	start := strings.Index(cookie, "pritunl-zero=")
-	if start != -1 {
+	names := []string{
+		"pritunl-zero=",
+		endpoint.TokenCookie + "=",
+	}
+
+	for _, name := range names {
+		start := strings.Index(cookie, name)
+		if start == -1 {
+			continue
+		}
+
 		str := cookie[start:]
 		end := strings.Index(str, ";")
 		if end != -1 {
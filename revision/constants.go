@@ -0,0 +1,33 @@
+package revision
+
+import (
+	"github.com/dropbox/godropbox/container/set"
+)
+
+const (
+	Policy    = "policy"
+	Service   = "service"
+	Authority = "authority"
+
+	Baseline = "baseline"
+	Create   = "create"
+	Update   = "update"
+	Delete   = "delete"
+	Rollback = "rollback"
+
+	System   = "system"
+	Redacted = "[redacted]"
+)
+
+var (
+	sensitiveFields = set.NewSet(
+		"private_key",
+		"proxy_private_key",
+		"hsm_secret",
+	)
+	ignoredFields = set.NewSet(
+		"_id",
+		"hsm_status",
+		"hsm_timestamp",
+	)
+)
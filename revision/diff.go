@@ -0,0 +1,73 @@
+package revision
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+// Convert resource to bson document for storage and comparison
+func Snapshot(obj interface{}) (data bson.M, err error) {
+	if obj == nil {
+		return
+	}
+
+	raw, err := bson.Marshal(obj)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "revision: Failed to marshal snapshot"),
+		}
+		return
+	}
+
+	data = bson.M{}
+	err = bson.Unmarshal(raw, &data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "revision: Failed to unmarshal snapshot"),
+		}
+		return
+	}
+
+	return
+}
+
+// Compare top level fields of two snapshots, either may be nil
+func Diff(old, new bson.M) (changes []*Change) {
+	changes = []*Change{}
+
+	keys := []string{}
+	for key := range old {
+		keys = append(keys, key)
+	}
+	for key := range new {
+		if _, ok := old[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if ignoredFields.Contains(key) {
+			continue
+		}
+
+		oldVal := old[key]
+		newVal := new[key]
+
+		if reflect.DeepEqual(oldVal, newVal) {
+			continue
+		}
+
+		changes = append(changes, &Change{
+			Field: key,
+			Old:   oldVal,
+			New:   newVal,
+		})
+	}
+
+	return
+}
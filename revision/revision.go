@@ -0,0 +1,100 @@
+package revision
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Change struct {
+	Field string      `bson:"field" json:"field"`
+	Old   interface{} `bson:"old" json:"old"`
+	New   interface{} `bson:"new" json:"new"`
+}
+
+type Revision struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Resource   string             `bson:"resource" json:"resource"`
+	ResourceId primitive.ObjectID `bson:"resource_id" json:"resource_id"`
+	Version    int                `bson:"version" json:"version"`
+	Action     string             `bson:"action" json:"action"`
+	Name       string             `bson:"name" json:"name"`
+	Roles      []string           `bson:"roles" json:"roles"`
+	User       primitive.ObjectID `bson:"user,omitempty" json:"user"`
+	Username   string             `bson:"username" json:"username"`
+	Source     primitive.ObjectID `bson:"source,omitempty" json:"source"`
+	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
+	Changes    []*Change          `bson:"changes" json:"changes"`
+	Data       bson.M             `bson:"data" json:"data,omitempty"`
+}
+
+// Remove snapshot and redact sensitive values for output
+func (r *Revision) Json(data bool) {
+	if !data {
+		r.Data = nil
+	} else if r.Data != nil {
+		redacted := bson.M{}
+		for key, val := range r.Data {
+			if sensitiveFields.Contains(key) {
+				val = Redacted
+			}
+			redacted[key] = val
+		}
+		r.Data = redacted
+	}
+
+	Redact(r.Changes)
+}
+
+// Redact sensitive values from changes
+func Redact(changes []*Change) {
+	for _, change := range changes {
+		if sensitiveFields.Contains(change.Field) {
+			change.Old = Redacted
+			change.New = Redacted
+		}
+	}
+}
+
+// Remove sensitive values from snapshot and changes before storage
+func (r *Revision) strip() {
+	if r.Data != nil {
+		data := bson.M{}
+		for key, val := range r.Data {
+			if !sensitiveFields.Contains(key) {
+				data[key] = val
+			}
+		}
+		r.Data = data
+	}
+
+	Redact(r.Changes)
+}
+
+// Revisions are immutable and only inserted, sensitive values are removed
+// and never stored
+func (r *Revision) Insert(db *database.Database) (err error) {
+	coll := db.Revisions()
+
+	if !r.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("revision: Revision already exists"),
+		}
+		return
+	}
+
+	r.Id = primitive.NewObjectID()
+	r.strip()
+
+	_, err = coll.InsertOne(db, r)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,434 @@
+package revision
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/authority"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func Get(db *database.Database, revId primitive.ObjectID) (
+	rev *Revision, err error) {
+
+	coll := db.Revisions()
+	rev = &Revision{}
+
+	err = coll.FindOneId(revId, rev)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetResource(db *database.Database, resource string,
+	resourceId, revId primitive.ObjectID) (rev *Revision, err error) {
+
+	coll := db.Revisions()
+	rev = &Revision{}
+
+	err = coll.FindOne(db, &bson.M{
+		"_id":         revId,
+		"resource":    resource,
+		"resource_id": resourceId,
+	}).Decode(rev)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func getAll(db *database.Database, query *bson.M, limit int64) (
+	revs []*Revision, err error) {
+
+	coll := db.Revisions()
+	revs = []*Revision{}
+
+	opts := &options.FindOptions{
+		Sort: &bson.D{
+			{"timestamp", -1},
+			{"version", -1},
+		},
+	}
+	if limit != 0 {
+		opts.Limit = &limit
+	}
+
+	cursor, err := coll.Find(db, query, opts)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		rev := &Revision{}
+		err = cursor.Decode(rev)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		revs = append(revs, rev)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database, resource string,
+	resourceId primitive.ObjectID, limit int64) (
+	revs []*Revision, err error) {
+
+	revs, err = getAll(db, &bson.M{
+		"resource":    resource,
+		"resource_id": resourceId,
+	}, limit)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Get most recent revisions, all resources are included when empty
+func GetRecent(db *database.Database, resource string, limit int64) (
+	revs []*Revision, err error) {
+
+	query := &bson.M{}
+	if resource != "" {
+		query = &bson.M{
+			"resource": resource,
+		}
+	}
+
+	revs, err = getAll(db, query, limit)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Get most recent revision of resource, nil when resource has no history
+func Latest(db *database.Database, resource string,
+	resourceId primitive.ObjectID) (rev *Revision, err error) {
+
+	coll := db.Revisions()
+	rev = &Revision{}
+
+	err = coll.FindOne(db, &bson.M{
+		"resource":    resource,
+		"resource_id": resourceId,
+	}, &options.FindOneOptions{
+		Sort: &bson.D{
+			{"version", -1},
+		},
+	}).Decode(rev)
+	if err != nil {
+		err = database.ParseError(err)
+		if _, ok := err.(*database.NotFoundError); ok {
+			err = nil
+			rev = nil
+		}
+		return
+	}
+
+	return
+}
+
+func newRevision(resource string, resourceId primitive.ObjectID,
+	action string, usr *user.User, data bson.M) (rev *Revision) {
+
+	rev = &Revision{
+		Resource:   resource,
+		ResourceId: resourceId,
+		Action:     action,
+		Roles:      []string{},
+		Username:   System,
+		Timestamp:  time.Now(),
+		Data:       data,
+	}
+
+	if usr != nil {
+		rev.User = usr.Id
+		rev.Username = usr.Username
+	}
+
+	if name, ok := data["name"].(string); ok {
+		rev.Name = name
+	}
+
+	if roles, ok := data["roles"].(bson.A); ok {
+		for _, roleInf := range roles {
+			if role, ok := roleInf.(string); ok {
+				rev.Roles = append(rev.Roles, role)
+			}
+		}
+	}
+
+	return
+}
+
+func record(db *database.Database, usr *user.User, resource, action string,
+	resourceId, source primitive.ObjectID, prev, cur interface{}) (
+	rev *Revision, err error) {
+
+	prevData, err := Snapshot(prev)
+	if err != nil {
+		return
+	}
+
+	curData, err := Snapshot(cur)
+	if err != nil {
+		return
+	}
+
+	last, err := Latest(db, resource, resourceId)
+	if err != nil {
+		return
+	}
+
+	version := 1
+	if last != nil {
+		version = last.Version + 1
+	} else if prevData != nil {
+		base := newRevision(resource, resourceId, Baseline, nil, prevData)
+		base.Version = version
+		base.Changes = Diff(nil, prevData)
+
+		err = base.Insert(db)
+		if err != nil {
+			return
+		}
+		version += 1
+	}
+
+	data := curData
+	if data == nil {
+		data = prevData
+	}
+
+	rev = newRevision(resource, resourceId, action, usr, data)
+	rev.Version = version
+	rev.Source = source
+	if action == Delete {
+		rev.Changes = Diff(prevData, nil)
+	} else {
+		rev.Changes = Diff(prevData, curData)
+	}
+
+	err = rev.Insert(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Record change to resource, prev and cur may be resources or snapshots. The
+// state before the change is recorded as a baseline for resources without
+// history. A nil user records a system change.
+func Record(db *database.Database, usr *user.User, resource, action string,
+	resourceId primitive.ObjectID, prev, cur interface{}) (
+	rev *Revision, err error) {
+
+	rev, err = record(db, usr, resource, action, resourceId,
+		primitive.NilObjectID, prev, cur)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func decode(raw []byte, obj interface{}) (err error) {
+	err = bson.Unmarshal(raw, obj)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "revision: Failed to unmarshal revision data"),
+		}
+		return
+	}
+
+	return
+}
+
+func restore(db *database.Database, coll *database.Collection,
+	id primitive.ObjectID, exists bool, doc interface{}) (err error) {
+
+	if exists {
+		err = coll.Commit(id, doc)
+	} else {
+		_, err = coll.InsertOne(db, doc)
+		if err != nil {
+			err = database.ParseError(err)
+		}
+	}
+
+	return
+}
+
+// Restore resource to the state of revision, deleted resources are
+// recreated with the same id
+func (r *Revision) Rollback(db *database.Database, usr *user.User) (
+	rev *Revision, errData *errortypes.ErrorData, err error) {
+
+	if r.Action == Delete || r.Data == nil {
+		errData = &errortypes.ErrorData{
+			Error:   "revision_rollback_invalid",
+			Message: "Cannot rollback to a deleted revision",
+		}
+		return
+	}
+
+	raw, err := bson.Marshal(r.Data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "revision: Failed to marshal revision data"),
+		}
+		return
+	}
+
+	var prev interface{}
+	var cur interface{}
+
+	switch r.Resource {
+	case Policy:
+		polcy := &policy.Policy{}
+		err = decode(raw, polcy)
+		if err != nil {
+			return
+		}
+		polcy.Id = r.ResourceId
+
+		prevPolcy, e := policy.Get(db, r.ResourceId)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); !ok {
+				err = e
+				return
+			}
+			prevPolcy = nil
+		} else {
+			prev = prevPolcy
+		}
+
+		errData, err = polcy.Validate(db)
+		if err != nil || errData != nil {
+			return
+		}
+
+		err = restore(db, db.Policies(), polcy.Id, prevPolcy != nil, polcy)
+		cur = polcy
+		break
+	case Service:
+		srvc := &service.Service{}
+		err = decode(raw, srvc)
+		if err != nil {
+			return
+		}
+		srvc.Id = r.ResourceId
+
+		prevSrvc, e := service.Get(db, r.ResourceId)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); !ok {
+				err = e
+				return
+			}
+			prevSrvc = nil
+		} else {
+			prev = prevSrvc
+		}
+
+		errData, err = srvc.Validate(db)
+		if err != nil || errData != nil {
+			return
+		}
+
+		err = restore(db, db.Services(), srvc.Id, prevSrvc != nil, srvc)
+		cur = srvc
+		break
+	case Authority:
+		authr := &authority.Authority{}
+		err = decode(raw, authr)
+		if err != nil {
+			return
+		}
+		authr.Id = r.ResourceId
+
+		prevAuthr, e := authority.Get(db, r.ResourceId)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); !ok {
+				err = e
+				return
+			}
+			prevAuthr = nil
+		} else {
+			prev = prevAuthr
+		}
+
+		// Keys are not stored in revisions, the current key pairs are kept
+		if prevAuthr == nil {
+			errData = &errortypes.ErrorData{
+				Error:   "revision_rollback_invalid",
+				Message: "Cannot rollback deleted authority",
+			}
+			return
+		}
+
+		authr.Algorithm = prevAuthr.Algorithm
+		authr.PrivateKey = prevAuthr.PrivateKey
+		authr.PublicKey = prevAuthr.PublicKey
+		authr.PublicKeyPem = prevAuthr.PublicKeyPem
+		authr.RootCertificate = prevAuthr.RootCertificate
+		authr.ProxyPrivateKey = prevAuthr.ProxyPrivateKey
+		authr.ProxyPublicKey = prevAuthr.ProxyPublicKey
+		authr.HsmSecret = prevAuthr.HsmSecret
+
+		errData, err = authr.Validate(db)
+		if err != nil || errData != nil {
+			return
+		}
+
+		err = restore(db, db.Authorities(), authr.Id, prevAuthr != nil, authr)
+		cur = authr
+		break
+	default:
+		err = &errortypes.UnknownError{
+			errors.Newf("revision: Unknown resource '%s'", r.Resource),
+		}
+		return
+	}
+	if err != nil {
+		return
+	}
+
+	rev, err = record(db, usr, r.Resource, Rollback, r.ResourceId, r.Id,
+		prev, cur)
+	if err != nil {
+		return
+	}
+
+	_ = event.PublishDispatch(db, r.Resource+".change")
+	if r.Resource == Authority {
+		_ = event.PublishDispatch(db, "node.change")
+	}
+
+	return
+}
@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/netlist"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/service"
+)
+
+const (
+	NewDevice          = "new_device"
+	FailedLogins       = "failed_logins"
+	Network            = "network"
+	TimeOfDay          = "time_of_day"
+	ServiceSensitivity = "service_sensitivity"
+
+	MaxScore        = 100
+	FailedWindow    = time.Hour
+	FailedWeight    = 5
+	FailedMax       = 25
+	NewDeviceWeight = 20
+	TimeOfDayWeight = 10
+	TimeOfDayMin    = 5
+	TimeOfDaySpread = 1
+)
+
+var anomalyChecks = []string{
+	policy.ImpossibleTravel,
+	policy.NewCountry,
+	policy.NewIsp,
+}
+
+var anomalyWeights = map[string]int{
+	policy.ImpossibleTravel: 40,
+	policy.NewCountry:       20,
+	policy.NewIsp:           10,
+}
+
+var networkWeights = map[string]int{
+	netlist.Tor:     40,
+	netlist.Vpn:     20,
+	netlist.Hosting: 15,
+}
+
+var sensitivityWeights = map[string]int{
+	service.Low:    0,
+	service.Medium: 10,
+	service.High:   25,
+}
+
+var failedTypes = []string{
+	audit.AdminLoginFailed,
+	audit.AdminPasskeyLoginFailed,
+	audit.UserLoginFailed,
+	audit.UserPasskeyLoginFailed,
+	audit.ProxyLoginFailed,
+	audit.ProxyPasskeyLoginFailed,
+	audit.SshDeny,
+}
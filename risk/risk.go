@@ -0,0 +1,165 @@
+package risk
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/anomaly"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/netlist"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+type Factor struct {
+	Type    string `bson:"type" json:"type"`
+	Score   int    `bson:"score" json:"score"`
+	Message string `bson:"message" json:"message"`
+}
+
+type Assessment struct {
+	Score   int       `bson:"score" json:"score"`
+	Factors []*Factor `bson:"factors" json:"factors"`
+}
+
+func (a *Assessment) add(typ string, score int, message string) {
+	if score <= 0 {
+		return
+	}
+
+	a.Factors = append(a.Factors, &Factor{
+		Type:    typ,
+		Score:   score,
+		Message: message,
+	})
+
+	a.Score += score
+	if a.Score > MaxScore {
+		a.Score = MaxScore
+	}
+}
+
+// Get score without factors of type
+func (a *Assessment) scoreWithout(typ string) (score int) {
+	for _, factor := range a.Factors {
+		if factor.Type != typ {
+			score += factor.Score
+		}
+	}
+
+	if score > MaxScore {
+		score = MaxScore
+	}
+
+	return
+}
+
+func hourDistance(x, y int) int {
+	diff := x - y
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > 12 {
+		diff = 24 - diff
+	}
+	return diff
+}
+
+// Score authentication from login history, recent failures, network
+// reputation, time of day and service sensitivity
+func Score(db *database.Database, usr *user.User, agnt *agent.Agent,
+	srvc *service.Service, now time.Time) (asmt *Assessment, err error) {
+
+	asmt = &Assessment{
+		Factors: []*Factor{},
+	}
+
+	history, err := audit.GetHistory(db, usr.Id, anomaly.LoginTypes,
+		now.Add(-anomaly.HistoryWindow), anomaly.HistoryLimit)
+	if err != nil {
+		return
+	}
+
+	previous := []*agent.Agent{}
+	for _, adt := range history {
+		if adt.Agent != nil {
+			previous = append(previous, adt.Agent)
+		}
+	}
+
+	if len(previous) > 0 {
+		seen := false
+		for _, prev := range previous {
+			if prev.OperatingSystem == agnt.OperatingSystem &&
+				prev.Browser == agnt.Browser {
+
+				seen = true
+				break
+			}
+		}
+
+		if !seen {
+			asmt.add(NewDevice, NewDeviceWeight, fmt.Sprintf(
+				"First login from %s on %s",
+				agnt.Browser, agnt.OperatingSystem))
+		}
+	}
+
+	for _, anmly := range anomaly.Detect(agnt, history, anomalyChecks, now) {
+		asmt.add(anmly.Type, anomalyWeights[anmly.Type], anmly.Message)
+	}
+
+	failed, err := audit.Count(db, usr.Id, failedTypes,
+		now.Add(-FailedWindow))
+	if err != nil {
+		return
+	}
+
+	if failed > 0 {
+		score := int(failed) * FailedWeight
+		if score > FailedMax {
+			score = FailedMax
+		}
+
+		asmt.add(FailedLogins, score, fmt.Sprintf(
+			"%d failed logins in the last hour", failed))
+	}
+
+	class, err := netlist.Match(db, agnt.Ip, nil)
+	if err != nil {
+		return
+	}
+
+	if class != "" {
+		asmt.add(Network, networkWeights[class], fmt.Sprintf(
+			"Login from %s network", class))
+	}
+
+	if len(history) >= TimeOfDayMin {
+		hour := now.UTC().Hour()
+		seen := false
+		for _, adt := range history {
+			if hourDistance(adt.Timestamp.UTC().Hour(),
+				hour) <= TimeOfDaySpread {
+
+				seen = true
+				break
+			}
+		}
+
+		if !seen {
+			asmt.add(TimeOfDay, TimeOfDayWeight, fmt.Sprintf(
+				"Unusual login time %02d:00 UTC", hour))
+		}
+	}
+
+	if srvc != nil && srvc.Sensitivity != "" {
+		asmt.add(ServiceSensitivity, sensitivityWeights[srvc.Sensitivity],
+			fmt.Sprintf("Service %s has %s sensitivity",
+				srvc.Name, srvc.Sensitivity))
+	}
+
+	return
+}
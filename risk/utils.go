@@ -0,0 +1,177 @@
+package risk
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/accessrequest"
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+// Score login for policies with risk score rules, the assessment is
+// audited and returned for the login audit. The strictest band action
+// across rules applies.
+func Check(db *database.Database, usr *user.User,
+	policies []*policy.Policy, srvc *service.Service, r *http.Request) (
+	stepUp bool, riskAudit audit.Fields, errAudit audit.Fields,
+	errData *errortypes.ErrorData, err error) {
+
+	rules := []*policy.Rule{}
+	rulePolicies := []*policy.Policy{}
+	for _, polcy := range policies {
+		if polcy.Disabled {
+			continue
+		}
+
+		for _, rule := range polcy.Rules {
+			if rule.Type == policy.RiskScore {
+				rules = append(rules, rule)
+				rulePolicies = append(rulePolicies, polcy)
+			}
+		}
+	}
+
+	if len(rules) == 0 {
+		return
+	}
+
+	agnt, err := agent.Parse(db, r)
+	if err != nil || agnt == nil {
+		return
+	}
+
+	asmt, err := Score(db, usr, agnt, srvc, time.Now())
+	if err != nil {
+		return
+	}
+
+	action := policy.Allow
+	var denyRule *policy.Rule
+	approvalRoles := set.NewSet()
+	for i, rule := range rules {
+		ruleAction := rule.RiskAction(asmt.Score)
+		action = policy.StricterRisk(action, ruleAction)
+
+		if ruleAction == policy.Deny && (denyRule == nil || rule.Disable) {
+			denyRule = rule
+		}
+
+		if ruleAction == policy.Approval {
+			for _, role := range rulePolicies[i].Roles {
+				if usr.RolesMatch([]string{role}) {
+					approvalRoles.Add(role)
+				}
+			}
+		}
+	}
+
+	// Approval must be for the service being accessed or for a role of
+	// the policies requiring approval
+	approved := false
+	if action == policy.Approval {
+		resourceId := primitive.NilObjectID
+		if srvc != nil {
+			resourceId = srvc.Id
+		}
+
+		roles := []string{}
+		for roleInf := range approvalRoles.Iter() {
+			roles = append(roles, roleInf.(string))
+		}
+
+		approved, err = accessrequest.HasApproved(
+			db, usr.Id, resourceId, roles)
+		if err != nil {
+			return
+		}
+	}
+
+	fields := audit.Fields{
+		"action":  action,
+		"score":   asmt.Score,
+		"factors": asmt.Factors,
+	}
+	if action == policy.Approval {
+		fields["approved"] = approved
+	}
+
+	err = audit.New(
+		db,
+		r,
+		usr.Id,
+		audit.RiskAssessment,
+		fields,
+	)
+	if err != nil {
+		return
+	}
+
+	riskAudit = audit.Fields{
+		"risk_action":  action,
+		"risk_score":   asmt.Score,
+		"risk_factors": asmt.Factors,
+	}
+
+	switch action {
+	case policy.StepUp:
+		stepUp = true
+		break
+	case policy.Approval:
+		if approved {
+			break
+		}
+
+		errAudit = audit.Fields{
+			"error":   "risk_approval_required",
+			"message": "Login requires administrator approval",
+			"score":   asmt.Score,
+			"factors": asmt.Factors,
+		}
+		errData = &errortypes.ErrorData{
+			Error: "risk_approval_required",
+			Message: "Login requires administrator approval, submit " +
+				"an access request to continue",
+		}
+		break
+	case policy.Deny:
+		errAudit = audit.Fields{
+			"error":   "risk_policy",
+			"message": "Login blocked due to elevated risk",
+			"score":   asmt.Score,
+			"factors": asmt.Factors,
+		}
+
+		// Failed logins can be caused by anyone with the username and
+		// cannot alone disable the user
+		if denyRule.Disable && denyRule.RiskAction(
+			asmt.scoreWithout(FailedLogins)) == policy.Deny {
+
+			usr.Disabled = true
+			err = usr.CommitFields(db, set.NewSet("disabled"))
+			if err != nil {
+				return
+			}
+
+			errData = &errortypes.ErrorData{
+				Error:   "unauthorized",
+				Message: "Not authorized",
+			}
+		} else {
+			errData = &errortypes.ErrorData{
+				Error:   "risk_policy",
+				Message: "Login blocked due to elevated risk",
+			}
+		}
+		break
+	}
+
+	return
+}
@@ -2,4 +2,8 @@ package service
 
 const (
 	Http = "http"
+
+	Low    = "low"
+	Medium = "medium"
+	High   = "high"
 )
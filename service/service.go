@@ -45,6 +45,7 @@ type Service struct {
 	Servers            []*Server          `bson:"servers" json:"servers"`
 	WhitelistNetworks  []string           `bson:"whitelist_networks" json:"whitelist_networks"`
 	WhitelistPaths     []*WhitelistPath   `bson:"whitelist_paths" json:"whitelist_paths"`
+	Sensitivity        string             `bson:"sensitivity" json:"sensitivity"`
 	logoutPathExtMatch int
 }
 
@@ -144,6 +145,17 @@ func (s *Service) Validate(db *database.Database) (
 		s.WhitelistPaths = []*WhitelistPath{}
 	}
 
+	switch s.Sensitivity {
+	case "", Low, Medium, High:
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "sensitivity_invalid",
+			Message: "Service sensitivity is invalid",
+		}
+		return
+	}
+
 	for _, domain := range s.Domains {
 		wildcardCount := strings.Count(domain.Domain, "*")
 		if wildcardCount > 1 {
@@ -245,6 +257,8 @@ func (s *Service) Insert(db *database.Database) (err error) {
 		return
 	}
 
+	s.Id = primitive.NewObjectID()
+
 	_, err = coll.InsertOne(db, s)
 	if err != nil {
 		err = database.ParseError(err)
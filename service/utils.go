@@ -96,13 +96,15 @@ func GetAll(db *database.Database) (services []*Service, err error) {
 	return
 }
 
-func GetAllName(db *database.Database) (services []*Service, err error) {
+func GetAllName(db *database.Database, query *bson.M) (
+	services []*Service, err error) {
+
 	coll := db.Services()
 	services = []*Service{}
 
 	cursor, err := coll.Find(
 		db,
-		&bson.M{},
+		query,
 		&options.FindOptions{
 			Projection: &bson.D{
 				{"name", 1},
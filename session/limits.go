@@ -0,0 +1,41 @@
+package session
+
+import (
+	"time"
+)
+
+type Limits struct {
+	Expire          time.Duration
+	MaxDuration     time.Duration
+	ReauthPrimary   time.Duration
+	ReauthSecondary time.Duration
+}
+
+// Check session against limits, sessions authenticated before the
+// reauthentication window must authenticate again
+func (l *Limits) Allowed(sess *Session) bool {
+	lastActive := sess.prevActive
+	if lastActive.IsZero() {
+		lastActive = sess.LastActive
+	}
+
+	if l.Expire != 0 && time.Since(lastActive) > l.Expire {
+		return false
+	}
+
+	if l.MaxDuration != 0 && time.Since(sess.Timestamp) > l.MaxDuration {
+		return false
+	}
+
+	if l.ReauthPrimary != 0 && time.Since(sess.Timestamp) > l.ReauthPrimary {
+		return false
+	}
+
+	if l.ReauthSecondary != 0 && (!sess.Secondary ||
+		time.Since(sess.Timestamp) > l.ReauthSecondary) {
+
+		return false
+	}
+
+	return true
+}
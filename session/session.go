@@ -26,9 +26,11 @@ type Session struct {
 	Secret     string             `bson:"secret" json:"-"`
 	Timestamp  time.Time          `bson:"timestamp" json:"timestamp"`
 	LastActive time.Time          `bson:"last_active" json:"last_active"`
+	Secondary  bool               `bson:"secondary" json:"secondary"`
 	Removed    bool               `bson:"removed" json:"removed"`
 	Agent      *agent.Agent       `bson:"agent" json:"agent"`
 	user       *user.User         `bson:"-" json:"-"`
+	prevActive time.Time          `bson:"-" json:"-"`
 }
 
 func (s *Session) CheckSignature(db *database.Database, inSig string) (
@@ -48,8 +48,10 @@ func Get(db *database.Database, sessId string) (
 	return
 }
 
+// Get and update session activity, limits override the global expire and
+// max duration. Sessions outside of limits are not returned.
 func GetUpdate(db *database.Database, sessId string, r *http.Request,
-	typ, sig string) (sess *Session, err error) {
+	typ, sig string, limits *Limits) (sess *Session, err error) {
 
 	query := bson.M{
 		"_id": sessId,
@@ -61,6 +63,15 @@ func GetUpdate(db *database.Database, sessId string, r *http.Request,
 	expire := GetExpire(typ)
 	maxDuration := GetMaxDuration(typ)
 
+	if limits != nil {
+		if limits.Expire != 0 {
+			expire = limits.Expire
+		}
+		if limits.MaxDuration != 0 {
+			maxDuration = limits.MaxDuration
+		}
+	}
+
 	if expire != 0 {
 		query["last_active"] = &bson.M{
 			"$gte": time.Now().Add(-expire),
@@ -91,6 +102,7 @@ func GetUpdate(db *database.Database, sessId string, r *http.Request,
 		return
 	}
 
+	sess.prevActive = sess.LastActive
 	sess.LastActive = timestamp
 
 	valid, err := sess.CheckSignature(db, sig)
@@ -103,6 +115,11 @@ func GetUpdate(db *database.Database, sessId string, r *http.Request,
 		return
 	}
 
+	if limits != nil && !limits.Allowed(sess) {
+		sess = nil
+		return
+	}
+
 	agnt, err := agent.Parse(db, r)
 	if err != nil {
 		return
@@ -166,7 +183,7 @@ func GetAll(db *database.Database, userId primitive.ObjectID,
 }
 
 func New(db *database.Database, r *http.Request, userId primitive.ObjectID,
-	typ string) (sess *Session, sig string, err error) {
+	typ string, secondary bool) (sess *Session, sig string, err error) {
 
 	id, err := utils.RandStr(32)
 	if err != nil {
@@ -185,6 +202,7 @@ func New(db *database.Database, r *http.Request, userId primitive.ObjectID,
 		User:       userId,
 		Timestamp:  time.Now(),
 		LastActive: time.Now(),
+		Secondary:  secondary,
 		Agent:      agnt,
 	}
 
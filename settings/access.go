@@ -0,0 +1,26 @@
+package settings
+
+var Access *access
+
+type access struct {
+	Id            string   `bson:"_id"`
+	RequestRoles  []string `bson:"request_roles"`
+	ApproverRoles []string `bson:"approver_roles"`
+	MaxDuration   int      `bson:"max_duration" default:"168"`
+}
+
+func newAccess() interface{} {
+	return &access{
+		Id:            "access",
+		RequestRoles:  []string{},
+		ApproverRoles: []string{},
+	}
+}
+
+func updateAccess(data interface{}) {
+	Access = data.(*access)
+}
+
+func init() {
+	register("access", newAccess, updateAccess)
+}
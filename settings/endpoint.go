@@ -2,11 +2,18 @@ package settings
 
 var Endpoint *endpoint
 
+type RetentionTier struct {
+	Interval  int `bson:"interval" json:"interval"`
+	Retention int `bson:"retention" json:"retention"`
+}
+
 type endpoint struct {
-	Id                string `bson:"_id"`
-	Name              string `bson:"name"`
-	KmsgDisplayLimit  int64  `bson:"kmsg_display_limit" default:"5000"`
-	CheckDisplayLimit int64  `bson:"check_display_limit" default:"5000"`
+	Id                string           `bson:"_id"`
+	Name              string           `bson:"name"`
+	KmsgDisplayLimit  int64            `bson:"kmsg_display_limit" default:"5000"`
+	CheckDisplayLimit int64            `bson:"check_display_limit" default:"5000"`
+	RawRetention      int              `bson:"raw_retention"`
+	Tiers             []*RetentionTier `bson:"tiers"`
 }
 
 func newEndpoint() interface{} {
@@ -0,0 +1,24 @@
+package settings
+
+var Netlist *netlist
+
+type netlist struct {
+	Id            string `bson:"_id"`
+	TorSource     string `bson:"tor_source"`
+	VpnSource     string `bson:"vpn_source"`
+	HostingSource string `bson:"hosting_source"`
+}
+
+func newNetlist() interface{} {
+	return &netlist{
+		Id: "netlist",
+	}
+}
+
+func updateNetlist(data interface{}) {
+	Netlist = data.(*netlist)
+}
+
+func init() {
+	register("netlist", newNetlist, updateNetlist)
+}
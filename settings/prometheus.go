@@ -0,0 +1,25 @@
+package settings
+
+var Prometheus *prometheus
+
+type prometheus struct {
+	Id                  string `bson:"_id"`
+	Token               string `bson:"token"`
+	RemoteWriteUrl      string `bson:"remote_write_url"`
+	RemoteWriteUsername string `bson:"remote_write_username"`
+	RemoteWritePassword string `bson:"remote_write_password"`
+}
+
+func newPrometheus() interface{} {
+	return &prometheus{
+		Id: "prometheus",
+	}
+}
+
+func updatePrometheus(data interface{}) {
+	Prometheus = data.(*prometheus)
+}
+
+func init() {
+	register("prometheus", newPrometheus, updatePrometheus)
+}
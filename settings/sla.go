@@ -0,0 +1,23 @@
+package settings
+
+var Sla *sla
+
+type sla struct {
+	Id         string   `bson:"_id"`
+	Schedule   string   `bson:"schedule"`
+	Recipients []string `bson:"recipients"`
+}
+
+func newSla() interface{} {
+	return &sla{
+		Id: "sla",
+	}
+}
+
+func updateSla(data interface{}) {
+	Sla = data.(*sla)
+}
+
+func init() {
+	register("sla", newSla, updateSla)
+}
@@ -0,0 +1,26 @@
+package settings
+
+var Smtp *smtp
+
+type smtp struct {
+	Id       string `bson:"_id"`
+	Host     string `bson:"host"`
+	Port     int    `bson:"port" default:"587"`
+	Username string `bson:"username"`
+	Password string `bson:"password"`
+	From     string `bson:"from"`
+}
+
+func newSmtp() interface{} {
+	return &smtp{
+		Id: "smtp",
+	}
+}
+
+func updateSmtp(data interface{}) {
+	Smtp = data.(*smtp)
+}
+
+func init() {
+	register("smtp", newSmtp, updateSmtp)
+}
@@ -20,6 +20,8 @@ type system struct {
 	ProxyCookieCryptoKey           []byte `bson:"proxy_cookie_crypto_key"`
 	UserCookieAuthKey              []byte `bson:"user_cookie_auth_key"`
 	UserCookieCryptoKey            []byte `bson:"user_cookie_crypto_key"`
+	ApiKeySecret                   []byte `bson:"api_key_secret"`
+	OidcPrivateKey                 string `bson:"oidc_private_key"`
 	AcmeKeyAlgorithm               string `bson:"acme_key_algorithm" default:"rsa"`
 	SshPubKeyLen                   int    `bson:"ssh_pub_key_len" default:"5000"`
 	SshHostTokenLen                int    `bson:"ssh_host_token_len" default:"10"`
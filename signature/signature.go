@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/pritunl-zero/apikey"
 	"github.com/pritunl/pritunl-zero/database"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/nonce"
@@ -26,6 +27,7 @@ type Signature struct {
 	Method    string
 	Path      string
 	user      *user.User
+	key       *apikey.ApiKey
 }
 
 func (s *Signature) GetUser(db *database.Database) (
@@ -38,7 +40,25 @@ func (s *Signature) GetUser(db *database.Database) (
 
 	usr, err = user.GetTokenUpdate(db, s.Token)
 	if err != nil {
-		return
+		switch err.(type) {
+		case *database.NotFoundError:
+			break
+		default:
+			return
+		}
+
+		key, e := apikey.GetToken(db, s.Token)
+		if e != nil {
+			err = e
+			return
+		}
+
+		usr, err = user.GetUpdate(db, key.User)
+		if err != nil {
+			return
+		}
+
+		s.key = key
 	}
 
 	s.user = usr
@@ -46,6 +66,10 @@ func (s *Signature) GetUser(db *database.Database) (
 	return
 }
 
+func (s *Signature) GetApiKey() *apikey.ApiKey {
+	return s.key
+}
+
 func (s *Signature) Validate(db *database.Database) (err error) {
 	if s.Token == "" {
 		err = &errortypes.AuthenticationError{
@@ -82,9 +106,23 @@ func (s *Signature) Validate(db *database.Database) (err error) {
 		}
 	}
 
-	if usr == nil || usr.Type != user.Api ||
-		usr.Token == "" || usr.Secret == "" {
+	secret := ""
+	if usr != nil && s.key != nil {
+		if s.key.IsExpired() {
+			err = &errortypes.AuthenticationError{
+				errors.New("signature: Api key expired"),
+			}
+			return
+		}
+
+		secret = apikey.DeriveSecret(s.Token)
+	} else if usr != nil && usr.Type == user.Api &&
+		usr.Token != "" && usr.Secret != "" {
+
+		secret = usr.Secret
+	}
 
+	if secret == "" {
 		err = &errortypes.AuthenticationError{
 			errors.New("signature: User not found"),
 		}
@@ -92,7 +130,7 @@ func (s *Signature) Validate(db *database.Database) (err error) {
 	}
 
 	authString := strings.Join([]string{
-		usr.Token,
+		s.Token,
 		strconv.FormatInt(s.Timestamp.Unix(), 10),
 		s.Nonce,
 		s.Method,
@@ -104,7 +142,7 @@ func (s *Signature) Validate(db *database.Database) (err error) {
 		return
 	}
 
-	hashFunc := hmac.New(sha512.New, []byte(usr.Secret))
+	hashFunc := hmac.New(sha512.New, []byte(secret))
 	hashFunc.Write([]byte(authString))
 	rawSignature := hashFunc.Sum(nil)
 	sig := base64.StdEncoding.EncodeToString(rawSignature)
@@ -0,0 +1,13 @@
+package simulation
+
+const (
+	Allow = "allow"
+	Deny  = "deny"
+
+	Service   = "service"
+	Authority = "authority"
+
+	MatchService   = "service"
+	MatchAuthority = "authority"
+	MatchRole      = "role"
+)
@@ -0,0 +1,289 @@
+package simulation
+
+import (
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/agent"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/geo"
+	"github.com/pritunl/pritunl-zero/netlist"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/sirupsen/logrus"
+)
+
+type Context struct {
+	Ip                     string             `json:"ip"`
+	OperatingSystem        string             `json:"operating_system"`
+	Browser                string             `json:"browser"`
+	Platform               string             `json:"platform"`
+	OperatingSystemVersion string             `json:"operating_system_version"`
+	BrowserVersion         string             `json:"browser_version"`
+	CountryCode            string             `json:"country_code"`
+	RegionCode             string             `json:"region_code"`
+	Method                 string             `json:"method"`
+	Path                   string             `json:"path"`
+	Endpoint               primitive.ObjectID `json:"endpoint"`
+	Timestamp              time.Time          `json:"timestamp"`
+	agnt                   *agent.Agent
+}
+
+type RuleResult struct {
+	Type    string `json:"type"`
+	Disable bool   `json:"disable"`
+	Passed  bool   `json:"passed"`
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+type PolicyResult struct {
+	Id               primitive.ObjectID `json:"id"`
+	Name             string             `json:"name"`
+	Disabled         bool               `json:"disabled"`
+	Matched          []string           `json:"matched"`
+	Passed           bool               `json:"passed"`
+	Rules            []*RuleResult      `json:"rules"`
+	Secondary        primitive.ObjectID `json:"secondary"`
+	DeviceSecondary  bool               `json:"device_secondary"`
+	Passkey          bool               `json:"passkey"`
+	RequireSmartCard bool               `json:"require_smart_card"`
+}
+
+type Result struct {
+	Type             string             `json:"type"`
+	Id               primitive.ObjectID `json:"id"`
+	Name             string             `json:"name"`
+	Decision         string             `json:"decision"`
+	Error            string             `json:"error"`
+	Message          string             `json:"message"`
+	Agent            *agent.Agent       `json:"agent"`
+	Policies         []*PolicyResult    `json:"policies"`
+	Secondary        primitive.ObjectID `json:"secondary"`
+	SecondaryName    string             `json:"secondary_name"`
+	DeviceSecondary  bool               `json:"device_secondary"`
+	Passkey          bool               `json:"passkey"`
+	RequireSmartCard bool               `json:"require_smart_card"`
+}
+
+func (r *Result) deny(err, msg string) {
+	if r.Decision == Deny {
+		return
+	}
+
+	r.Decision = Deny
+	r.Error = err
+	r.Message = msg
+}
+
+// Check user state without expiring or disabling the user
+func (r *Result) checkUser(usr *user.User, now time.Time) {
+	if !usr.ActiveUntil.IsZero() && usr.ActiveUntil.Before(now) {
+		r.deny("user_disabled", "User is disabled from expired active time")
+	} else if usr.Disabled {
+		r.deny("user_disabled", "User is disabled")
+	}
+}
+
+func (r *Result) evaluate(policies []*policy.Policy,
+	matched map[primitive.ObjectID][]string, pctx *policy.Context) (
+	err error) {
+
+	for _, polcy := range policies {
+		polcyResult := &PolicyResult{
+			Id:       polcy.Id,
+			Name:     polcy.Name,
+			Disabled: polcy.Disabled,
+			Matched:  matched[polcy.Id],
+			Passed:   true,
+			Rules:    []*RuleResult{},
+		}
+		r.Policies = append(r.Policies, polcyResult)
+
+		if polcy.Disabled {
+			continue
+		}
+
+		for _, rule := range polcy.Rules {
+			ruleResult := &RuleResult{
+				Type:    rule.Type,
+				Disable: rule.Disable,
+				Passed:  true,
+			}
+			polcyResult.Rules = append(polcyResult.Rules, ruleResult)
+
+			errData, e := polcy.CheckRule(rule, pctx)
+			if e != nil {
+				err = e
+				return
+			}
+
+			if errData != nil {
+				ruleResult.Passed = false
+				ruleResult.Error = errData.Error
+				ruleResult.Message = errData.Message
+				polcyResult.Passed = false
+				r.deny(errData.Error, errData.Message)
+			}
+		}
+
+		switch r.Type {
+		case Service:
+			polcyResult.Secondary = polcy.ProxySecondary
+			polcyResult.DeviceSecondary = polcy.ProxyDeviceSecondary
+			polcyResult.Passkey = polcy.ProxyPasskey
+			break
+		case Authority:
+			polcyResult.Secondary = polcy.AuthoritySecondary
+			polcyResult.DeviceSecondary = polcy.AuthorityDeviceSecondary
+			polcyResult.RequireSmartCard = polcy.AuthorityRequireSmartCard
+			break
+		}
+
+		if !polcyResult.Secondary.IsZero() && r.Secondary.IsZero() {
+			r.Secondary = polcyResult.Secondary
+		}
+		if polcyResult.DeviceSecondary {
+			r.DeviceSecondary = true
+		}
+		if polcyResult.Passkey {
+			r.Passkey = true
+		}
+		if polcyResult.RequireSmartCard {
+			r.RequireSmartCard = true
+		}
+	}
+
+	if !r.Secondary.IsZero() {
+		provider := settings.Auth.GetSecondaryProvider(r.Secondary)
+		if provider != nil {
+			r.SecondaryName = provider.Name
+		}
+	}
+
+	if r.Decision == "" {
+		r.Decision = Allow
+	}
+
+	return
+}
+
+// Build agent from request context, location is resolved from the
+// address when not provided
+func (c *Context) agent(db *database.Database) (
+	agnt *agent.Agent, err error) {
+
+	if c.agnt != nil {
+		agnt = c.agnt
+		return
+	}
+
+	agnt = &agent.Agent{
+		Ip:                     c.Ip,
+		OperatingSystem:        c.OperatingSystem,
+		Browser:                c.Browser,
+		Platform:               c.Platform,
+		OperatingSystemVersion: c.OperatingSystemVersion,
+		BrowserVersion:         c.BrowserVersion,
+		CountryCode:            c.CountryCode,
+		RegionCode:             c.RegionCode,
+	}
+
+	if c.Ip != "" && c.CountryCode == "" {
+		ge, e := geo.Get(db, c.Ip)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"error": e,
+			}).Error("simulation: Failed to get geo IP information")
+			ge = &geo.Geo{}
+		}
+
+		agnt.Isp = ge.Isp
+		agnt.Continent = ge.Continent
+		agnt.ContinentCode = ge.ContinentCode
+		agnt.Country = ge.Country
+		agnt.CountryCode = ge.CountryCode
+		agnt.Region = ge.Region
+		agnt.RegionCode = ge.RegionCode
+		agnt.City = ge.City
+		agnt.Latitude = ge.Latitude
+		agnt.Longitude = ge.Longitude
+	}
+
+	c.agnt = agnt
+
+	return
+}
+
+// Build policy context for expression and posture rules, the request is
+// synthesized from the simulated method and path and the endpoint is
+// trusted without a token
+func (c *Context) policy(db *database.Database, usr *user.User,
+	agnt *agent.Agent, service string) (pctx *policy.Context, err error) {
+
+	pctx = &policy.Context{
+		User:    usr,
+		Agent:   agnt,
+		Service: service,
+		Devices: []string{},
+		Time:    c.now(),
+	}
+
+	if c.Method != "" || c.Path != "" {
+		pctx.Request = &http.Request{
+			Method: c.Method,
+			URL: &url.URL{
+				Path: c.Path,
+			},
+		}
+	}
+
+	if !usr.Id.IsZero() {
+		pctx.Devices, err = device.GetTypes(db, usr.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	if agnt != nil && agnt.Ip != "" {
+		pctx.Classification, err = netlist.Match(db, agnt.Ip, nil)
+		if err != nil {
+			return
+		}
+	}
+
+	if !c.Endpoint.IsZero() {
+		endpt, e := endpoint.Get(db, c.Endpoint)
+		if e != nil {
+			if _, ok := e.(*database.NotFoundError); !ok {
+				err = e
+				return
+			}
+			endpt = nil
+		}
+
+		if endpt != nil && !endpt.User.IsZero() && endpt.User == usr.Id {
+			pctx.Endpoint = endpt
+		} else {
+			pctx.EndpointError = &errortypes.ErrorData{
+				Error:   "endpoint_token_invalid",
+				Message: "Endpoint not registered to user",
+			}
+		}
+	}
+
+	return
+}
+
+func (c *Context) now() time.Time {
+	if c.Timestamp.IsZero() {
+		return time.Now()
+	}
+	return c.Timestamp
+}
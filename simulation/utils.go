@@ -0,0 +1,173 @@
+package simulation
+
+import (
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/authority"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/user"
+)
+
+func collect(policies []*policy.Policy, match string,
+	all []*policy.Policy, matched map[primitive.ObjectID][]string) (
+	merged []*policy.Policy) {
+
+	merged = all
+	for _, polcy := range policies {
+		if _, ok := matched[polcy.Id]; !ok {
+			merged = append(merged, polcy)
+		}
+		matched[polcy.Id] = append(matched[polcy.Id], match)
+	}
+
+	return
+}
+
+// Evaluate proxy access to service
+func EvaluateService(db *database.Database, usr *user.User,
+	srvc *service.Service, ctx *Context) (result *Result, err error) {
+
+	now := ctx.now()
+
+	agnt, err := ctx.agent(db)
+	if err != nil {
+		return
+	}
+
+	result = &Result{
+		Type:     Service,
+		Id:       srvc.Id,
+		Name:     srvc.Name,
+		Agent:    agnt,
+		Policies: []*PolicyResult{},
+	}
+
+	result.checkUser(usr, now)
+
+	if !usr.RolesMatch(srvc.Roles) {
+		result.deny("service_unauthorized",
+			"User does not have roles required to access service")
+	}
+
+	matched := map[primitive.ObjectID][]string{}
+	policies := []*policy.Policy{}
+
+	srvcPolicies, err := policy.GetService(db, srvc.Id)
+	if err != nil {
+		return
+	}
+	policies = collect(srvcPolicies, MatchService, policies, matched)
+
+	rolePolicies, err := policy.GetRoles(db, usr.Roles)
+	if err != nil {
+		return
+	}
+	policies = collect(rolePolicies, MatchRole, policies, matched)
+
+	pctx, err := ctx.policy(db, usr, agnt, srvc.Name)
+	if err != nil {
+		return
+	}
+
+	err = result.evaluate(policies, matched, pctx)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Evaluate SSH certificate access to authority
+func EvaluateAuthority(db *database.Database, usr *user.User,
+	authr *authority.Authority, ctx *Context) (result *Result, err error) {
+
+	now := ctx.now()
+
+	agnt, err := ctx.agent(db)
+	if err != nil {
+		return
+	}
+
+	result = &Result{
+		Type:     Authority,
+		Id:       authr.Id,
+		Name:     authr.Name,
+		Agent:    agnt,
+		Policies: []*PolicyResult{},
+	}
+
+	result.checkUser(usr, now)
+
+	if !authr.UserHasAccess(usr) {
+		result.deny("authority_unauthorized",
+			"User does not have roles required to access authority")
+	}
+
+	matched := map[primitive.ObjectID][]string{}
+	policies := []*policy.Policy{}
+
+	authrPolicies, err := policy.GetAuthoritiesRoles(
+		db, []primitive.ObjectID{authr.Id}, []string{})
+	if err != nil {
+		return
+	}
+	policies = collect(authrPolicies, MatchAuthority, policies, matched)
+
+	rolePolicies, err := policy.GetRoles(db, usr.Roles)
+	if err != nil {
+		return
+	}
+	policies = collect(rolePolicies, MatchRole, policies, matched)
+
+	pctx, err := ctx.policy(db, usr, agnt, "")
+	if err != nil {
+		return
+	}
+
+	err = result.evaluate(policies, matched, pctx)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Evaluate every service and authority for user
+func Reachable(db *database.Database, usr *user.User, ctx *Context) (
+	results []*Result, err error) {
+
+	results = []*Result{}
+
+	srvcs, err := service.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, srvc := range srvcs {
+		result, e := EvaluateService(db, usr, srvc, ctx)
+		if e != nil {
+			err = e
+			return
+		}
+
+		results = append(results, result)
+	}
+
+	authrs, err := authority.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, authr := range authrs {
+		result, e := EvaluateAuthority(db, usr, authr, ctx)
+		if e != nil {
+			err = e
+			return
+		}
+
+		results = append(results, result)
+	}
+
+	return
+}
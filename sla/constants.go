@@ -0,0 +1,21 @@
+package sla
+
+import (
+	"time"
+)
+
+const (
+	Check    = "check"
+	Endpoint = "endpoint"
+
+	Day   = "day"
+	Week  = "week"
+	Month = "month"
+
+	Weekly  = "weekly"
+	Monthly = "monthly"
+
+	checkInterval    = 10 * time.Second
+	endpointInterval = time.Minute
+	maxRange         = 366 * 24 * time.Hour
+)
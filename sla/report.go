@@ -0,0 +1,208 @@
+package sla
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+)
+
+type Outage struct {
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Duration int64     `json:"duration"`
+	Ongoing  bool      `json:"ongoing"`
+}
+
+type Latency struct {
+	P50 int `json:"p50"`
+	P90 int `json:"p90"`
+	P95 int `json:"p95"`
+	P99 int `json:"p99"`
+}
+
+type Report struct {
+	Resource string             `json:"resource"`
+	Id       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	Start    time.Time          `json:"start"`
+	End      time.Time          `json:"end"`
+	Samples  int64              `json:"samples"`
+	Uptime   float64            `json:"uptime"`
+	Downtime int64              `json:"downtime"`
+	Mttr     int64              `json:"mttr"`
+	Outages  []*Outage          `json:"outages"`
+	Latency  *Latency           `json:"latency"`
+
+	up        int64
+	outage    *Outage
+	latencies []int
+}
+
+func newReport(resource string, id primitive.ObjectID, name string,
+	start, end time.Time) *Report {
+
+	return &Report{
+		Resource: resource,
+		Id:       id,
+		Name:     name,
+		Start:    start,
+		End:      end,
+		Outages:  []*Outage{},
+	}
+}
+
+func (r *Report) add(timestamp time.Time, up bool) {
+	r.Samples += 1
+
+	if up {
+		r.up += 1
+		if r.outage != nil {
+			r.outage.End = timestamp
+			r.Outages = append(r.Outages, r.outage)
+			r.outage = nil
+		}
+	} else if r.outage == nil {
+		r.outage = &Outage{
+			Start: timestamp,
+		}
+	}
+}
+
+func (r *Report) addLatency(latency int) {
+	if latency > 0 {
+		r.latencies = append(r.latencies, latency)
+	}
+}
+
+func percentile(vals []int, pct float64) int {
+	if len(vals) == 0 {
+		return 0
+	}
+
+	rank := int(math.Ceil(pct / 100 * float64(len(vals))))
+	if rank < 1 {
+		rank = 1
+	}
+
+	return vals[rank-1]
+}
+
+func (r *Report) finish() {
+	if r.outage != nil {
+		r.outage.End = r.End
+		r.outage.Ongoing = true
+		r.Outages = append(r.Outages, r.outage)
+		r.outage = nil
+	}
+
+	if r.Samples > 0 {
+		r.Uptime = math.Round(
+			float64(r.up)/float64(r.Samples)*100000) / 1000
+	}
+
+	recovered := int64(0)
+	recoveredTotal := int64(0)
+	for _, outage := range r.Outages {
+		outage.Duration = int64(outage.End.Sub(outage.Start).Seconds())
+		r.Downtime += outage.Duration
+
+		if !outage.Ongoing {
+			recovered += 1
+			recoveredTotal += outage.Duration
+		}
+	}
+
+	if recovered > 0 {
+		r.Mttr = recoveredTotal / recovered
+	}
+
+	if r.latencies != nil {
+		sort.Ints(r.latencies)
+		r.Latency = &Latency{
+			P50: percentile(r.latencies, 50),
+			P90: percentile(r.latencies, 90),
+			P95: percentile(r.latencies, 95),
+			P99: percentile(r.latencies, 99),
+		}
+		r.latencies = nil
+	}
+}
+
+func (r *Report) Csv() (data []byte, err error) {
+	buf := &bytes.Buffer{}
+	writer := csv.NewWriter(buf)
+
+	latency := r.Latency
+	if latency == nil {
+		latency = &Latency{}
+	}
+
+	rows := [][]string{
+		{"resource", "id", "name", "start", "end", "samples", "uptime",
+			"downtime", "mttr", "outages", "latency_p50", "latency_p90",
+			"latency_p95", "latency_p99"},
+		{
+			r.Resource,
+			r.Id.Hex(),
+			r.Name,
+			r.Start.Format(time.RFC3339),
+			r.End.Format(time.RFC3339),
+			strconv.FormatInt(r.Samples, 10),
+			strconv.FormatFloat(r.Uptime, 'f', 3, 64),
+			strconv.FormatInt(r.Downtime, 10),
+			strconv.FormatInt(r.Mttr, 10),
+			strconv.Itoa(len(r.Outages)),
+			strconv.Itoa(latency.P50),
+			strconv.Itoa(latency.P90),
+			strconv.Itoa(latency.P95),
+			strconv.Itoa(latency.P99),
+		},
+		{},
+		{"outage_start", "outage_end", "duration", "ongoing"},
+	}
+
+	for _, outage := range r.Outages {
+		rows = append(rows, []string{
+			outage.Start.Format(time.RFC3339),
+			outage.End.Format(time.RFC3339),
+			strconv.FormatInt(outage.Duration, 10),
+			strconv.FormatBool(outage.Ongoing),
+		})
+	}
+
+	err = writer.WriteAll(rows)
+	if err != nil {
+		return
+	}
+
+	data = buf.Bytes()
+	return
+}
+
+func (r *Report) Summary() string {
+	summary := fmt.Sprintf(
+		"%s %s: %.3f%% uptime, %d outages, %s downtime",
+		r.Resource,
+		r.Name,
+		r.Uptime,
+		len(r.Outages),
+		time.Duration(r.Downtime)*time.Second,
+	)
+
+	if r.Mttr > 0 {
+		summary += fmt.Sprintf(", %s mttr",
+			time.Duration(r.Mttr)*time.Second)
+	}
+
+	if r.Latency != nil {
+		summary += fmt.Sprintf(", p95 latency %dms", r.Latency.P95)
+	}
+
+	return summary
+}
@@ -0,0 +1,163 @@
+package sla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+)
+
+var testStart = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+func testReport(states ...bool) (report *Report) {
+	end := testStart.Add(time.Duration(len(states)) * time.Minute)
+	report = newReport(Check, primitive.NewObjectID(), "test",
+		testStart, end)
+
+	for i, up := range states {
+		report.add(testStart.Add(time.Duration(i)*time.Minute), up)
+	}
+
+	report.finish()
+	return
+}
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name     string
+		states   []bool
+		uptime   float64
+		downtime int64
+		mttr     int64
+		outages  int
+		ongoing  bool
+	}{
+		{
+			"no samples",
+			[]bool{},
+			0,
+			0,
+			0,
+			0,
+			false,
+		},
+		{
+			"all up",
+			[]bool{true, true, true, true},
+			100,
+			0,
+			0,
+			0,
+			false,
+		},
+		{
+			"recovered outage",
+			[]bool{true, false, false, true},
+			50,
+			120,
+			120,
+			1,
+			false,
+		},
+		{
+			"outage open at end of range",
+			[]bool{true, true, false},
+			66.667,
+			60,
+			0,
+			1,
+			true,
+		},
+		{
+			"outage from start of range",
+			[]bool{false, true, true, true},
+			75,
+			60,
+			60,
+			1,
+			false,
+		},
+		{
+			"recovered and open outages",
+			[]bool{false, false, false, true, false, true, false},
+			28.571,
+			300,
+			120,
+			3,
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		report := testReport(test.states...)
+
+		if report.Samples != int64(len(test.states)) {
+			t.Errorf("%s: samples got %d want %d",
+				test.name, report.Samples, len(test.states))
+		}
+
+		if report.Uptime != test.uptime {
+			t.Errorf("%s: uptime got %f want %f",
+				test.name, report.Uptime, test.uptime)
+		}
+
+		if report.Downtime != test.downtime {
+			t.Errorf("%s: downtime got %d want %d",
+				test.name, report.Downtime, test.downtime)
+		}
+
+		if report.Mttr != test.mttr {
+			t.Errorf("%s: mttr got %d want %d",
+				test.name, report.Mttr, test.mttr)
+		}
+
+		if len(report.Outages) != test.outages {
+			t.Errorf("%s: outages got %d want %d",
+				test.name, len(report.Outages), test.outages)
+			continue
+		}
+
+		if test.outages > 0 {
+			last := report.Outages[len(report.Outages)-1]
+			if last.Ongoing != test.ongoing {
+				t.Errorf("%s: ongoing got %t want %t",
+					test.name, last.Ongoing, test.ongoing)
+			}
+
+			if last.Ongoing && !last.End.Equal(report.End) {
+				t.Errorf("%s: ongoing outage end got %s want %s",
+					test.name, last.End, report.End)
+			}
+		}
+	}
+}
+
+func TestReportLatency(t *testing.T) {
+	report := newReport(Check, primitive.NewObjectID(), "test",
+		testStart, testStart.Add(time.Hour))
+
+	report.addLatency(0)
+	for i := 100; i > 0; i-- {
+		report.addLatency(i)
+	}
+	report.finish()
+
+	if report.Latency == nil {
+		t.Fatalf("latency missing")
+	}
+
+	if report.Latency.P50 != 50 || report.Latency.P90 != 90 ||
+		report.Latency.P95 != 95 || report.Latency.P99 != 99 {
+
+		t.Errorf("latency got %+v", report.Latency)
+	}
+
+	report = newReport(Check, primitive.NewObjectID(), "test",
+		testStart, testStart.Add(time.Hour))
+	report.addLatency(0)
+	report.finish()
+
+	if report.Latency != nil {
+		t.Errorf("latency without samples got %+v", report.Latency)
+	}
+}
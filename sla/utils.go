@@ -0,0 +1,335 @@
+package sla
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/check"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/endpoints"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/mail"
+	"github.com/pritunl/pritunl-zero/settings"
+)
+
+type checkSample struct {
+	Timestamp   time.Time `bson:"t"`
+	TargetsUp   int       `bson:"u"`
+	TargetsDown int       `bson:"d"`
+	LatencyAvg  int       `bson:"p"`
+}
+
+type endpointSample struct {
+	Timestamp time.Time `bson:"t"`
+}
+
+// Parse named range or custom RFC 3339 start and end
+func ParseRange(rng, startStr, endStr string, now time.Time) (
+	start, end time.Time, errData *errortypes.ErrorData) {
+
+	end = now
+
+	switch rng {
+	case Day:
+		start = end.Add(-24 * time.Hour)
+		return
+	case "", Week:
+		start = end.Add(-7 * 24 * time.Hour)
+		return
+	case Month:
+		start = end.AddDate(0, -1, 0)
+		return
+	}
+
+	start, err := time.Parse(time.RFC3339, startStr)
+	if err != nil {
+		errData = &errortypes.ErrorData{
+			Error:   "sla_range_invalid",
+			Message: "Report start time is invalid",
+		}
+		return
+	}
+
+	if endStr != "" {
+		end, err = time.Parse(time.RFC3339, endStr)
+		if err != nil {
+			errData = &errortypes.ErrorData{
+				Error:   "sla_range_invalid",
+				Message: "Report end time is invalid",
+			}
+			return
+		}
+	}
+
+	if !start.Before(end) || end.Sub(start) > maxRange {
+		errData = &errortypes.ErrorData{
+			Error:   "sla_range_invalid",
+			Message: "Report range is invalid",
+		}
+		return
+	}
+
+	return
+}
+
+// Get check report, an interval is down when any target of any endpoint
+// reports a failure and intervals without data are not counted. Ranges
+// past the raw retention are read from the rollup tier intervals.
+func GetCheck(db *database.Database, checkId primitive.ObjectID,
+	start, end time.Time) (report *Report, err error) {
+
+	chck, err := check.Get(db, checkId)
+	if err != nil {
+		return
+	}
+
+	report = newReport(Check, chck.Id, chck.Name, start, end)
+	coll, interval := endpoints.GetReportCollection(db, "check", start)
+	if interval == 0 {
+		interval = checkInterval
+	}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"c": chck.Id,
+			"t": &bson.M{
+				"$gte": start,
+				"$lte": end,
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"t", 1},
+			},
+			Projection: &bson.D{
+				{"t", 1},
+				{"u", 1},
+				{"d", 1},
+				{"p", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	var cur time.Time
+	down := false
+	for cursor.Next(db) {
+		smpl := &checkSample{}
+		err = cursor.Decode(smpl)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		timestamp := smpl.Timestamp.Truncate(interval)
+		if !cur.IsZero() && !timestamp.Equal(cur) {
+			report.add(cur, !down)
+			down = false
+		}
+
+		cur = timestamp
+		if smpl.TargetsDown > 0 {
+			down = true
+		}
+		report.addLatency(smpl.LatencyAvg)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if !cur.IsZero() {
+		report.add(cur, !down)
+	}
+
+	report.finish()
+
+	return
+}
+
+// Get endpoint report, intervals missing system data after the first
+// sample are counted as down. Ranges past the raw retention are read from
+// the rollup tier intervals.
+func GetEndpoint(db *database.Database, endpointId primitive.ObjectID,
+	start, end time.Time) (report *Report, err error) {
+
+	endpt, err := endpoint.Get(db, endpointId)
+	if err != nil {
+		return
+	}
+
+	report = newReport(Endpoint, endpt.Id, endpt.Name, start, end)
+	coll, interval := endpoints.GetReportCollection(db, "system", start)
+	if interval == 0 {
+		interval = endpointInterval
+	}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"e": endpt.Id,
+			"t": &bson.M{
+				"$gte": start,
+				"$lte": end,
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"t", 1},
+			},
+			Projection: &bson.D{
+				{"t", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	var last time.Time
+	for cursor.Next(db) {
+		smpl := &endpointSample{}
+		err = cursor.Decode(smpl)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		timestamp := smpl.Timestamp.Truncate(interval)
+		if !last.IsZero() {
+			for missing := last.Add(interval); missing.Before(
+				timestamp); missing = missing.Add(interval) {
+
+				report.add(missing, false)
+			}
+		}
+
+		report.add(timestamp, true)
+		last = timestamp
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if !last.IsZero() {
+		for missing := last.Add(interval); missing.Before(
+			end.Truncate(interval)); missing = missing.Add(interval) {
+
+			report.add(missing, false)
+		}
+	}
+
+	report.finish()
+
+	return
+}
+
+// Get reports for all checks and endpoints
+func GetAll(db *database.Database, start, end time.Time) (
+	reports []*Report, err error) {
+
+	reports = []*Report{}
+
+	checks, err := check.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, chck := range checks {
+		report, e := GetCheck(db, chck.Id, start, end)
+		if e != nil {
+			err = e
+			return
+		}
+
+		reports = append(reports, report)
+	}
+
+	endpts, err := endpoint.GetAll(db)
+	if err != nil {
+		return
+	}
+
+	for _, endpt := range endpts {
+		report, e := GetEndpoint(db, endpt.Id, start, end)
+		if e != nil {
+			err = e
+			return
+		}
+
+		reports = append(reports, report)
+	}
+
+	return
+}
+
+// Send scheduled report email when the schedule is due at now
+func SendScheduled(db *database.Database, now time.Time) (err error) {
+	if len(settings.Sla.Recipients) == 0 {
+		return
+	}
+
+	var start time.Time
+	end := time.Date(now.Year(), now.Month(), now.Day(),
+		0, 0, 0, 0, now.Location())
+
+	switch settings.Sla.Schedule {
+	case Weekly:
+		if now.Weekday() != time.Monday {
+			return
+		}
+		start = end.AddDate(0, 0, -7)
+		break
+	case Monthly:
+		if now.Day() != 1 {
+			return
+		}
+		start = end.AddDate(0, -1, 0)
+		break
+	default:
+		return
+	}
+
+	reports, err := GetAll(db, start, end)
+	if err != nil {
+		return
+	}
+
+	lines := []string{
+		fmt.Sprintf("SLA report %s to %s", start.Format("2006-01-02"),
+			end.Format("2006-01-02")),
+		"",
+	}
+	for _, report := range reports {
+		lines = append(lines, report.Summary())
+	}
+
+	err = mail.Send(&mail.Message{
+		To: settings.Sla.Recipients,
+		Subject: fmt.Sprintf("Pritunl Zero SLA report %s",
+			start.Format("2006-01-02")),
+		Body: strings.Join(lines, "\n") + "\n",
+	})
+	if err != nil {
+		return
+	}
+
+	return
+}
@@ -0,0 +1,34 @@
+package statuspage
+
+import (
+	"time"
+)
+
+const (
+	Operational = "operational"
+	Degraded    = "degraded"
+	Outage      = "outage"
+	Unknown     = "unknown"
+)
+
+const (
+	Investigating = "investigating"
+	Identified    = "identified"
+	Monitoring    = "monitoring"
+	Resolved      = "resolved"
+)
+
+const (
+	cacheTtl       = 30 * time.Second
+	incidentWindow = 7 * 24 * time.Hour
+	outageWindow   = 24 * time.Hour
+	checkStale     = 2 * time.Minute
+	endpointStale  = 3 * time.Minute
+)
+
+var stateRank = map[string]int{
+	Operational: 0,
+	Unknown:     1,
+	Degraded:    2,
+	Outage:      3,
+}
@@ -0,0 +1,110 @@
+package statuspage
+
+import (
+	"strings"
+	"time"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+)
+
+type Post struct {
+	Id        primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Page      primitive.ObjectID `bson:"page" json:"page"`
+	Title     string             `bson:"title" json:"title"`
+	Body      string             `bson:"body" json:"body"`
+	State     string             `bson:"state" json:"state"`
+	Timestamp time.Time          `bson:"timestamp" json:"timestamp"`
+	Updated   time.Time          `bson:"updated" json:"updated"`
+}
+
+func (p *Post) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	p.Title = strings.TrimSpace(p.Title)
+
+	if p.Page.IsZero() {
+		errData = &errortypes.ErrorData{
+			Error:   "status_post_page_invalid",
+			Message: "Status post page is required",
+		}
+		return
+	}
+
+	if p.Title == "" {
+		errData = &errortypes.ErrorData{
+			Error:   "status_post_title_invalid",
+			Message: "Status post title is required",
+		}
+		return
+	}
+
+	switch p.State {
+	case "":
+		p.State = Investigating
+		break
+	case Investigating, Identified, Monitoring, Resolved:
+		break
+	default:
+		errData = &errortypes.ErrorData{
+			Error:   "status_post_state_invalid",
+			Message: "Status post state is invalid",
+		}
+		return
+	}
+
+	if p.Timestamp.IsZero() {
+		p.Timestamp = time.Now()
+	}
+	p.Updated = time.Now()
+
+	return
+}
+
+func (p *Post) Commit(db *database.Database) (err error) {
+	coll := db.StatusPosts()
+
+	err = coll.Commit(p.Id, p)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (p *Post) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.StatusPosts()
+
+	err = coll.CommitFields(p.Id, p, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (p *Post) Insert(db *database.Database) (err error) {
+	coll := db.StatusPosts()
+
+	if !p.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("statuspage: Post already exists"),
+		}
+		return
+	}
+
+	p.Id = primitive.NewObjectID()
+
+	_, err = coll.InsertOne(db, p)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,98 @@
+package statuspage
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"time"
+
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/sirupsen/logrus"
+)
+
+var pageTemplate = template.Must(template.New("status").Funcs(
+	template.FuncMap{
+		"time": func(t time.Time) string {
+			if t.IsZero() {
+				return ""
+			}
+			return t.UTC().Format("2006-01-02 15:04 MST")
+		},
+	},
+).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta http-equiv="refresh" content="60">
+<title>{{.Title}}</title>
+<style>
+body{font-family:sans-serif;max-width:860px;margin:30px auto;color:#222}
+.state{display:inline-block;padding:2px 8px;border-radius:3px;color:#fff}
+.operational{background:#0f9960}.degraded{background:#d9822b}
+.outage{background:#db3737}.unknown{background:#8a9ba8}
+table{width:100%;border-collapse:collapse;margin-bottom:20px}
+td,th{text-align:left;padding:6px;border-bottom:1px solid #ddd}
+</style>
+</head>
+<body>
+<h1>{{.Title}} <span class="state {{.State}}">{{.State}}</span></h1>
+{{range .Posts}}<div>
+<h3>{{.Title}} <span class="state unknown">{{.State}}</span></h3>
+<p>{{.Body}}</p>
+<small>{{time .Timestamp}}</small>
+</div>{{end}}
+{{range .Components}}<h2>{{.Name}} <span class="state {{.State}}">{{.State}}</span></h2>
+<table>{{range .Members}}
+<tr><td>{{.Name}}</td><td><span class="state {{.State}}">{{.State}}</span></td></tr>{{end}}
+</table>{{end}}
+<h2>Incidents</h2>
+<table>
+<tr><th>Component</th><th>Name</th><th>Message</th><th>Start</th><th>End</th></tr>
+{{range .Incidents}}<tr><td>{{.Component}}</td><td>{{.Name}}</td><td>{{.Message}}</td><td>{{time .Start}}</td><td>{{if .Active}}Ongoing{{else}}{{time .End}}{{end}}</td></tr>
+{{end}}</table>
+<small>Updated {{time .Timestamp}}</small>
+</body>
+</html>
+`))
+
+// Serve status page html at root and json at /status.json
+func ServeHTTP(w http.ResponseWriter, r *http.Request,
+	db *database.Database, page *StatusPage) {
+
+	if r.Method != "GET" && r.Method != "HEAD" {
+		http.Error(w, "Method not allowed", 405)
+		return
+	}
+
+	if r.URL.Path != "/" && r.URL.Path != "/status.json" {
+		http.Error(w, "Not found", 404)
+		return
+	}
+
+	status, err := GetStatus(db, page)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"status_page": page.Id.Hex(),
+			"error":       err,
+		}).Error("statuspage: Failed to build status")
+		http.Error(w, "Server error", 500)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-cache")
+
+	if r.URL.Path == "/status.json" {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(status)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	err = pageTemplate.Execute(w, status)
+	if err != nil {
+		logrus.WithFields(logrus.Fields{
+			"status_page": page.Id.Hex(),
+			"error":       err,
+		}).Error("statuspage: Failed to render status")
+	}
+}
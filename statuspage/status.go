@@ -0,0 +1,360 @@
+package statuspage
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/alertinstance"
+	"github.com/pritunl/pritunl-zero/check"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoint"
+	"github.com/pritunl/pritunl-zero/sla"
+)
+
+var (
+	cache     = map[primitive.ObjectID]*cachedStatus{}
+	cacheLock = sync.Mutex{}
+)
+
+type cachedStatus struct {
+	status    *Status
+	timestamp time.Time
+}
+
+type MemberStatus struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	State string `json:"state"`
+}
+
+type ComponentStatus struct {
+	Name    string          `json:"name"`
+	State   string          `json:"state"`
+	Members []*MemberStatus `json:"members"`
+}
+
+type Incident struct {
+	Component string    `json:"component"`
+	Name      string    `json:"name"`
+	Message   string    `json:"message"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Active    bool      `json:"active"`
+}
+
+type Status struct {
+	Title      string             `json:"title"`
+	State      string             `json:"state"`
+	Timestamp  time.Time          `json:"timestamp"`
+	Components []*ComponentStatus `json:"components"`
+	Incidents  []*Incident        `json:"incidents"`
+	Posts      []*Post            `json:"posts"`
+}
+
+type checkLatest struct {
+	Endpoint    primitive.ObjectID `bson:"e"`
+	TargetsDown int                `bson:"d"`
+}
+
+func worst(x, y string) string {
+	if stateRank[y] > stateRank[x] {
+		return y
+	}
+	return x
+}
+
+// Get check state from the latest raw samples, the stale window is always
+// within the raw retention which is at least one hour
+func checkState(db *database.Database, checkId primitive.ObjectID,
+	now time.Time) (state string, err error) {
+
+	coll := db.EndpointsCheck()
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"c": checkId,
+			"t": &bson.M{
+				"$gte": now.Add(-checkStale),
+			},
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"t", -1},
+			},
+			Projection: &bson.D{
+				{"e", 1},
+				{"d", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	seen := map[primitive.ObjectID]bool{}
+	down := 0
+	for cursor.Next(db) {
+		doc := &checkLatest{}
+		err = cursor.Decode(doc)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		if _, ok := seen[doc.Endpoint]; ok {
+			continue
+		}
+
+		seen[doc.Endpoint] = true
+		if doc.TargetsDown > 0 {
+			down += 1
+		}
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if len(seen) == 0 {
+		state = Unknown
+	} else if down == len(seen) {
+		state = Outage
+	} else if down > 0 {
+		state = Degraded
+	} else {
+		state = Operational
+	}
+
+	return
+}
+
+// Check for recent raw system samples, the stale window is always within
+// the raw retention which is at least one hour
+func endpointOnline(db *database.Database, endpointId primitive.ObjectID,
+	now time.Time) (online bool, err error) {
+
+	coll := db.EndpointsSystem()
+
+	count, err := coll.CountDocuments(db, &bson.M{
+		"e": endpointId,
+		"t": &bson.M{
+			"$gte": now.Add(-endpointStale),
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	online = count > 0
+	return
+}
+
+func build(db *database.Database, page *StatusPage) (
+	status *Status, err error) {
+
+	now := time.Now()
+	status = &Status{
+		Title:      page.Title,
+		State:      Operational,
+		Timestamp:  now,
+		Components: []*ComponentStatus{},
+		Incidents:  []*Incident{},
+	}
+
+	checkIds := []primitive.ObjectID{}
+	endpointIds := []primitive.ObjectID{}
+	checkComps := map[primitive.ObjectID]string{}
+	endpointComps := map[primitive.ObjectID]string{}
+
+	for _, comp := range page.Components {
+		for _, checkId := range comp.Checks {
+			if _, ok := checkComps[checkId]; !ok {
+				checkComps[checkId] = comp.Name
+				checkIds = append(checkIds, checkId)
+			}
+		}
+		for _, endpointId := range comp.Endpoints {
+			if _, ok := endpointComps[endpointId]; !ok {
+				endpointComps[endpointId] = comp.Name
+				endpointIds = append(endpointIds, endpointId)
+			}
+		}
+	}
+
+	checks, err := check.GetMulti(db, checkIds)
+	if err != nil {
+		return
+	}
+
+	checkNames := map[primitive.ObjectID]string{}
+	for _, chck := range checks {
+		checkNames[chck.Id] = chck.Name
+	}
+
+	endpts, err := endpoint.GetMulti(db, endpointIds)
+	if err != nil {
+		return
+	}
+
+	endpointNames := map[primitive.ObjectID]string{}
+	for _, endpt := range endpts {
+		endpointNames[endpt.Id] = endpt.Name
+	}
+
+	instances, err := alertinstance.GetSources(db, endpointIds,
+		now.Add(-incidentWindow))
+	if err != nil {
+		return
+	}
+
+	alerting := map[primitive.ObjectID]bool{}
+	for _, inst := range instances {
+		if inst.Active {
+			alerting[inst.Source] = true
+		}
+
+		incident := &Incident{
+			Component: endpointComps[inst.Source],
+			Name:      inst.Name,
+			Message:   inst.Message,
+			Start:     inst.Start,
+			Active:    inst.Active,
+		}
+		if !inst.Active {
+			incident.End = inst.Resolved
+		}
+
+		status.Incidents = append(status.Incidents, incident)
+	}
+
+	for _, comp := range page.Components {
+		compStatus := &ComponentStatus{
+			Name:    comp.Name,
+			State:   Operational,
+			Members: []*MemberStatus{},
+		}
+
+		for _, checkId := range comp.Checks {
+			name, ok := checkNames[checkId]
+			if !ok {
+				continue
+			}
+
+			state, e := checkState(db, checkId, now)
+			if e != nil {
+				err = e
+				return
+			}
+
+			compStatus.State = worst(compStatus.State, state)
+			compStatus.Members = append(compStatus.Members, &MemberStatus{
+				Type:  sla.Check,
+				Name:  name,
+				State: state,
+			})
+		}
+
+		for _, endpointId := range comp.Endpoints {
+			name, ok := endpointNames[endpointId]
+			if !ok {
+				continue
+			}
+
+			online, e := endpointOnline(db, endpointId, now)
+			if e != nil {
+				err = e
+				return
+			}
+
+			state := Operational
+			if !online {
+				state = Outage
+			} else if alerting[endpointId] {
+				state = Degraded
+			}
+
+			compStatus.State = worst(compStatus.State, state)
+			compStatus.Members = append(compStatus.Members, &MemberStatus{
+				Type:  sla.Endpoint,
+				Name:  name,
+				State: state,
+			})
+		}
+
+		status.State = worst(status.State, compStatus.State)
+		status.Components = append(status.Components, compStatus)
+	}
+
+	for _, checkId := range checkIds {
+		name, ok := checkNames[checkId]
+		if !ok {
+			continue
+		}
+
+		report, e := sla.GetCheck(db, checkId, now.Add(-outageWindow), now)
+		if e != nil {
+			err = e
+			return
+		}
+
+		for _, outage := range report.Outages {
+			status.Incidents = append(status.Incidents, &Incident{
+				Component: checkComps[checkId],
+				Name:      name,
+				Message:   "Check failing",
+				Start:     outage.Start,
+				End:       outage.End,
+				Active:    outage.Ongoing,
+			})
+		}
+	}
+
+	sort.Slice(status.Incidents, func(i, j int) bool {
+		return status.Incidents[i].Start.After(status.Incidents[j].Start)
+	})
+
+	status.Posts, err = GetPosts(db, page.Id, 20)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+// Get page status, cached for a short period to limit load from viewers
+func GetStatus(db *database.Database, page *StatusPage) (
+	status *Status, err error) {
+
+	cacheLock.Lock()
+	cached := cache[page.Id]
+	cacheLock.Unlock()
+
+	if cached != nil && time.Since(cached.timestamp) < cacheTtl {
+		status = cached.status
+		return
+	}
+
+	status, err = build(db, page)
+	if err != nil {
+		return
+	}
+
+	cacheLock.Lock()
+	cache[page.Id] = &cachedStatus{
+		status:    status,
+		timestamp: time.Now(),
+	}
+	cacheLock.Unlock()
+
+	return
+}
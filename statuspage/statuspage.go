@@ -0,0 +1,158 @@
+package statuspage
+
+import (
+	"strings"
+
+	"github.com/dropbox/godropbox/container/set"
+	"github.com/dropbox/godropbox/errors"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/service"
+)
+
+type Component struct {
+	Name      string               `bson:"name" json:"name"`
+	Checks    []primitive.ObjectID `bson:"checks" json:"checks"`
+	Endpoints []primitive.ObjectID `bson:"endpoints" json:"endpoints"`
+}
+
+type StatusPage struct {
+	Id         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Name       string             `bson:"name" json:"name"`
+	Title      string             `bson:"title" json:"title"`
+	Domain     string             `bson:"domain" json:"domain"`
+	Service    primitive.ObjectID `bson:"service,omitempty" json:"service"`
+	Components []*Component       `bson:"components" json:"components"`
+}
+
+func (p *StatusPage) Validate(db *database.Database) (
+	errData *errortypes.ErrorData, err error) {
+
+	p.Name = strings.TrimSpace(p.Name)
+	p.Title = strings.TrimSpace(p.Title)
+	p.Domain = strings.ToLower(strings.TrimSpace(p.Domain))
+
+	if p.Title == "" {
+		p.Title = p.Name
+	}
+
+	if p.Domain == "" || strings.Contains(p.Domain, "*") ||
+		strings.ContainsAny(p.Domain, "/: ") {
+
+		errData = &errortypes.ErrorData{
+			Error:   "status_page_domain_invalid",
+			Message: "Status page domain is invalid",
+		}
+		return
+	}
+
+	coll := db.StatusPages()
+	count, err := coll.CountDocuments(db, &bson.M{
+		"_id": &bson.M{
+			"$ne": p.Id,
+		},
+		"domain": p.Domain,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	if count > 0 {
+		errData = &errortypes.ErrorData{
+			Error:   "status_page_domain_conflict",
+			Message: "Status page domain is already in use",
+		}
+		return
+	}
+
+	if !p.Service.IsZero() {
+		_, err = service.Get(db, p.Service)
+		if err != nil {
+			if _, ok := err.(*database.NotFoundError); ok {
+				err = nil
+				errData = &errortypes.ErrorData{
+					Error:   "status_page_service_invalid",
+					Message: "Status page authentication service not found",
+				}
+			}
+			return
+		}
+	}
+
+	if p.Components == nil {
+		p.Components = []*Component{}
+	}
+
+	for _, comp := range p.Components {
+		comp.Name = strings.TrimSpace(comp.Name)
+		if comp.Name == "" {
+			errData = &errortypes.ErrorData{
+				Error:   "status_page_component_invalid",
+				Message: "Status page component name is empty",
+			}
+			return
+		}
+
+		if comp.Checks == nil {
+			comp.Checks = []primitive.ObjectID{}
+		}
+		if comp.Endpoints == nil {
+			comp.Endpoints = []primitive.ObjectID{}
+		}
+	}
+
+	return
+}
+
+// Check if proxy authentication is required to view page
+func (p *StatusPage) AuthRequired() bool {
+	return !p.Service.IsZero()
+}
+
+func (p *StatusPage) Commit(db *database.Database) (err error) {
+	coll := db.StatusPages()
+
+	err = coll.Commit(p.Id, p)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (p *StatusPage) CommitFields(db *database.Database, fields set.Set) (
+	err error) {
+
+	coll := db.StatusPages()
+
+	err = coll.CommitFields(p.Id, p, fields)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func (p *StatusPage) Insert(db *database.Database) (err error) {
+	coll := db.StatusPages()
+
+	if !p.Id.IsZero() {
+		err = &errortypes.DatabaseError{
+			errors.New("statuspage: Status page already exists"),
+		}
+		return
+	}
+
+	p.Id = primitive.NewObjectID()
+
+	_, err = coll.InsertOne(db, p)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
@@ -0,0 +1,159 @@
+package statuspage
+
+import (
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/mongo-go-driver/mongo/options"
+	"github.com/pritunl/pritunl-zero/database"
+)
+
+func Get(db *database.Database, pageId primitive.ObjectID) (
+	page *StatusPage, err error) {
+
+	coll := db.StatusPages()
+	page = &StatusPage{}
+
+	err = coll.FindOneId(pageId, page)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetAll(db *database.Database) (pages []*StatusPage, err error) {
+	coll := db.StatusPages()
+	pages = []*StatusPage{}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"name", 1},
+			},
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		page := &StatusPage{}
+		err = cursor.Decode(page)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		pages = append(pages, page)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func Remove(db *database.Database, pageId primitive.ObjectID) (err error) {
+	coll := db.StatusPosts()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"page": pageId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	coll = db.StatusPages()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": pageId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func GetPost(db *database.Database, postId primitive.ObjectID) (
+	post *Post, err error) {
+
+	coll := db.StatusPosts()
+	post = &Post{}
+
+	err = coll.FindOneId(postId, post)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func GetPosts(db *database.Database, pageId primitive.ObjectID,
+	limit int64) (posts []*Post, err error) {
+
+	coll := db.StatusPosts()
+	posts = []*Post{}
+
+	cursor, err := coll.Find(
+		db,
+		&bson.M{
+			"page": pageId,
+		},
+		&options.FindOptions{
+			Sort: &bson.D{
+				{"timestamp", -1},
+			},
+			Limit: &limit,
+		},
+	)
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+	defer cursor.Close(db)
+
+	for cursor.Next(db) {
+		post := &Post{}
+		err = cursor.Decode(post)
+		if err != nil {
+			err = database.ParseError(err)
+			return
+		}
+
+		posts = append(posts, post)
+	}
+
+	err = cursor.Err()
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
+
+func RemovePost(db *database.Database, postId primitive.ObjectID) (
+	err error) {
+
+	coll := db.StatusPosts()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"_id": postId,
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	return
+}
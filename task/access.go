@@ -0,0 +1,68 @@
+package task
+
+import (
+	"github.com/pritunl/pritunl-zero/accessrequest"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/sirupsen/logrus"
+)
+
+var accessExpire = &Task{
+	Name:    "access_expire",
+	Hours:   AllHours,
+	Mins:    AllMins,
+	Handler: accessExpireHandler,
+}
+
+func accessExpireHandler(db *database.Database) (err error) {
+	reqs, err := accessrequest.GetExpired(db)
+	if err != nil {
+		return
+	}
+
+	if len(reqs) == 0 {
+		return
+	}
+
+	for _, req := range reqs {
+		errData, e := req.End(db, accessrequest.Expired)
+		if e != nil {
+			logrus.WithFields(logrus.Fields{
+				"access_request_id": req.Id.Hex(),
+				"error":             e,
+			}).Error("task: Failed to expire access grant")
+			continue
+		}
+
+		if errData != nil {
+			continue
+		}
+
+		err = audit.NewSystem(
+			db,
+			req.User,
+			audit.AccessExpire,
+			audit.Fields{
+				"access_request_id": req.Id.Hex(),
+				"type":              req.Type,
+				"resource":          req.ResourceName,
+				"role":              req.Role,
+			},
+		)
+		if err != nil {
+			return
+		}
+
+		req.NotifyUser()
+	}
+
+	_ = event.PublishDispatch(db, "user.change")
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	return
+}
+
+func init() {
+	register(accessExpire)
+}
@@ -0,0 +1,26 @@
+package task
+
+import (
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/netlist"
+)
+
+var netlistRefresh = &Task{
+	Name:    "netlist_refresh",
+	Hours:   AllHours,
+	Mins:    []int{15},
+	Handler: netlistRefreshHandler,
+}
+
+func netlistRefreshHandler(db *database.Database) (err error) {
+	err = netlist.Refresh(db)
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func init() {
+	register(netlistRefresh)
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/endpoints"
+)
+
+var endpointRollup = &Task{
+	Name:    "endpoint_rollup",
+	Hours:   AllHours,
+	Mins:    FiveMins,
+	Handler: endpointRollupHandler,
+}
+
+func endpointRollupHandler(db *database.Database) (err error) {
+	err = endpoints.Rollup(db, time.Now().UTC())
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func init() {
+	register(endpointRollup)
+}
@@ -0,0 +1,28 @@
+package task
+
+import (
+	"time"
+
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/sla"
+)
+
+var slaReport = &Task{
+	Name:    "sla_report",
+	Hours:   []int{6},
+	Mins:    []int{0},
+	Handler: slaReportHandler,
+}
+
+func slaReportHandler(db *database.Database) (err error) {
+	err = sla.SendScheduled(db, time.Now())
+	if err != nil {
+		return
+	}
+
+	return
+}
+
+func init() {
+	register(slaReport)
+}
@@ -0,0 +1,388 @@
+package uhandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/accessrequest"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/authority"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/service"
+	"github.com/pritunl/pritunl-zero/settings"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type accessRequestData struct {
+	Type          string             `json:"type"`
+	Resource      primitive.ObjectID `json:"resource"`
+	Role          string             `json:"role"`
+	Justification string             `json:"justification"`
+	Duration      int                `json:"duration"`
+}
+
+type accessDecisionData struct {
+	Approve bool   `json:"approve"`
+	Comment string `json:"comment"`
+}
+
+type accessResourceData struct {
+	Id    primitive.ObjectID `json:"id"`
+	Name  string             `json:"name"`
+	Roles []string           `json:"roles"`
+}
+
+type accessOptionsData struct {
+	Roles       []string              `json:"roles"`
+	Services    []*accessResourceData `json:"services"`
+	Authorities []*accessResourceData `json:"authorities"`
+	MaxDuration int                   `json:"max_duration"`
+	Approver    bool                  `json:"approver"`
+}
+
+func accessOptionsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	data := &accessOptionsData{
+		Roles:       settings.Access.RequestRoles,
+		Services:    []*accessResourceData{},
+		Authorities: []*accessResourceData{},
+		MaxDuration: settings.Access.MaxDuration,
+		Approver:    accessrequest.IsApprover(usr),
+	}
+
+	srvcs, err := service.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	for _, srvce := range srvcs {
+		if len(srvce.Roles) == 0 {
+			continue
+		}
+
+		data.Services = append(data.Services, &accessResourceData{
+			Id:    srvce.Id,
+			Name:  srvce.Name,
+			Roles: srvce.Roles,
+		})
+	}
+
+	authrs, err := authority.GetAll(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	for _, authr := range authrs {
+		if !authr.MatchRoles || len(authr.Roles) == 0 {
+			continue
+		}
+
+		data.Authorities = append(data.Authorities, &accessResourceData{
+			Id:    authr.Id,
+			Name:  authr.Name,
+			Roles: authr.Roles,
+		})
+	}
+
+	c.JSON(200, data)
+}
+
+func accessRequestsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	reqs, err := accessrequest.GetAll(db, &bson.M{
+		"user": usr.Id,
+	}, 100)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, reqs)
+}
+
+func accessRequestPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &accessRequestData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req := accessrequest.New(usr)
+	req.Type = data.Type
+	req.Resource = data.Resource
+	req.Role = data.Role
+	req.Justification = data.Justification
+	req.Duration = data.Duration
+
+	errData, err := req.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	for _, role := range usr.Roles {
+		if role == req.Role {
+			errData = &errortypes.ErrorData{
+				Error:   "access_request_role_exists",
+				Message: "Access is already granted",
+			}
+			c.JSON(400, errData)
+			return
+		}
+	}
+
+	exists, err := accessrequest.GetPendingDuplicate(db, usr.Id, req.Role)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if exists {
+		errData = &errortypes.ErrorData{
+			Error:   "access_request_pending",
+			Message: "Access request is already pending",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	err = req.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.AccessRequest,
+		audit.Fields{
+			"access_request_id": req.Id.Hex(),
+			"type":              req.Type,
+			"resource":          req.ResourceName,
+			"role":              req.Role,
+			"duration":          req.Duration,
+			"justification":     req.Justification,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req.NotifyApprovers()
+
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	c.JSON(200, req)
+}
+
+func accessRequestDelete(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	reqId, ok := utils.ParseObjectId(c.Param("request_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req, err := accessrequest.GetUser(db, reqId, usr.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	errData, err := req.Cancel(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.AccessRequestCancel,
+		audit.Fields{
+			"access_request_id": req.Id.Hex(),
+			"type":              req.Type,
+			"resource":          req.ResourceName,
+			"role":              req.Role,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	c.JSON(200, nil)
+}
+
+func accessApprovalsGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !accessrequest.IsApprover(usr) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	reqs, err := accessrequest.GetAll(db, &bson.M{
+		"user": &bson.M{
+			"$ne": usr.Id,
+		},
+		"state": accessrequest.Pending,
+	}, 0)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, reqs)
+}
+
+func accessApprovalPut(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &accessDecisionData{}
+
+	reqId, ok := utils.ParseObjectId(c.Param("request_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if !accessrequest.IsApprover(usr) {
+		utils.AbortWithStatus(c, 403)
+		return
+	}
+
+	req, err := accessrequest.Get(db, reqId)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	var errData *errortypes.ErrorData
+	typ := ""
+	if data.Approve {
+		typ = audit.AccessApprove
+		errData, err = req.Approve(db, usr, data.Comment)
+	} else {
+		typ = audit.AccessDeny
+		errData, err = req.Deny(db, usr, data.Comment)
+	}
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		req.User,
+		typ,
+		audit.Fields{
+			"access_request_id": req.Id.Hex(),
+			"type":              req.Type,
+			"resource":          req.ResourceName,
+			"role":              req.Role,
+			"approver_id":       usr.Id.Hex(),
+			"approver":          usr.Username,
+			"comment":           req.Comment,
+			"expires":           req.Expires,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	req.NotifyUser()
+
+	_ = event.PublishDispatch(db, "user.change")
+	_ = event.PublishDispatch(db, "access_request.change")
+
+	c.JSON(200, req)
+}
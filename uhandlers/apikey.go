@@ -0,0 +1,151 @@
+package uhandlers
+
+import (
+	"time"
+
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/apikey"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/utils"
+)
+
+type apiKeyData struct {
+	Name     string             `json:"name"`
+	Expires  time.Time          `json:"expires"`
+	ReadOnly bool               `json:"read_only"`
+	Service  primitive.ObjectID `json:"service"`
+}
+
+// Keys cannot be created or modified with a key, this prevents a scoped key
+// from creating a key with broader access
+func apiKeyAuthBlocked(c *gin.Context) bool {
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	if !authr.IsApi() {
+		return false
+	}
+
+	c.JSON(403, &errortypes.ErrorData{
+		Error:   "api_key_auth_invalid",
+		Message: "API keys cannot be managed with an API key",
+	})
+	return true
+}
+
+func apiKeyPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	if apiKeyAuthBlocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &apiKeyData{}
+
+	err := c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	key := apikey.New(usr.Id)
+
+	key.Name = data.Name
+	key.Expires = data.Expires
+	key.ReadOnly = data.ReadOnly
+	key.Service = data.Service
+
+	errData, err := key.Validate(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.JSON(400, errData)
+		return
+	}
+
+	err = key.GenerateToken()
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = key.Insert(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "apikey.change")
+
+	c.JSON(200, key)
+}
+
+func apiKeyDelete(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	keyId, ok := utils.ParseObjectId(c.Param("apikey_id"))
+	if !ok {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = apikey.RemoveUser(db, keyId, usr.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "apikey.change")
+
+	c.JSON(200, nil)
+}
+
+func apiKeysGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	keys, err := apikey.GetAll(db, usr.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, keys)
+}
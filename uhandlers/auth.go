@@ -112,6 +112,27 @@ func authSessionPost(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, devAuth, secProviderId,
+		"local") {
+
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.UserLoginFailed, "local")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.UserLoginFailed, "local") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -170,9 +191,7 @@ func authSessionPost(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.UserLogin,
-		audit.Fields{
-			"method": "local",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -181,7 +200,7 @@ func authSessionPost(c *gin.Context) {
 
 	cook := cookie.NewUser(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -351,7 +370,7 @@ func authSecondaryPost(c *gin.Context) {
 
 	cook := cookie.NewUser(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -535,6 +554,27 @@ func authCallbackGet(c *gin.Context) {
 		return
 	}
 
+	if passkeyEnforce(c, db, usr, devAuth, secProviderId,
+		"callback") {
+
+		return
+	}
+
+	stepUp, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.UserLoginFailed, "callback")
+	if blocked {
+		return
+	}
+
+	if stepUp {
+		if validator.StepUpLogin(c, db, usr, secProviderId,
+			audit.UserLoginFailed, "callback") {
+
+			return
+		}
+		devAuth = true
+	}
+
 	if devAuth {
 		deviceCount, err := device.CountSecondary(db, usr.Id)
 		if err != nil {
@@ -601,9 +641,7 @@ func authCallbackGet(c *gin.Context) {
 		c.Request,
 		usr.Id,
 		audit.UserLogin,
-		audit.Fields{
-			"method": "callback",
-		},
+		loginAudit,
 	)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
@@ -612,7 +650,7 @@ func authCallbackGet(c *gin.Context) {
 
 	cook := cookie.NewUser(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -841,7 +879,7 @@ func authWanRegisterPost(c *gin.Context) {
 
 	cook := cookie.NewUser(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -1054,7 +1092,7 @@ func authWanRespondPost(c *gin.Context) {
 
 	cook := cookie.NewUser(c.Writer, c.Request)
 
-	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User)
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -40,6 +40,17 @@ func Register(engine *gin.Engine) {
 
 	engine.NoRoute(middlewear.NotFound)
 
+	csrfGroup.GET("/access_approval", accessApprovalsGet)
+	csrfGroup.PUT("/access_approval/:request_id", accessApprovalPut)
+	csrfGroup.GET("/access_request", accessRequestsGet)
+	csrfGroup.GET("/access_request/options", accessOptionsGet)
+	csrfGroup.POST("/access_request", accessRequestPost)
+	csrfGroup.DELETE("/access_request/:request_id", accessRequestDelete)
+
+	csrfGroup.GET("/apikey", apiKeysGet)
+	csrfGroup.POST("/apikey", apiKeyPost)
+	csrfGroup.DELETE("/apikey/:apikey_id", apiKeyDelete)
+
 	engine.GET("/auth/state", authStateGet)
 	dbGroup.POST("/auth/session", authSessionPost)
 	dbGroup.POST("/auth/secondary", authSecondaryPost)
@@ -50,6 +61,8 @@ func Register(engine *gin.Engine) {
 	dbGroup.POST("/auth/webauthn/respond", authWanRespondPost)
 	dbGroup.GET("/auth/webauthn/register", authWanRegisterGet)
 	dbGroup.POST("/auth/webauthn/register", authWanRegisterPost)
+	dbGroup.GET("/auth/passkey/request", authPasskeyRequestGet)
+	dbGroup.POST("/auth/passkey/respond", authPasskeyRespondPost)
 	sessGroup.GET("/logout", logoutGet)
 	sessGroup.GET("/logout_all", logoutAllGet)
 
@@ -65,14 +78,25 @@ func Register(engine *gin.Engine) {
 	csrfGroup.POST("/device/:device_id/respond", deviceWanRespondPost)
 	csrfGroup.GET("/device/:device_id/register", deviceWanRegisterGet)
 	csrfGroup.POST("/device/:device_id/register", deviceWanRegisterPost)
+	csrfGroup.GET("/passkey/register", passkeyRegisterGet)
+	csrfGroup.POST("/passkey/register", passkeyRegisterPost)
 
 	dbGroup.PUT("/endpoint/:endpoint_id/register",
 		handlers.EndpointRegisterPut)
+	dbGroup.PUT("/endpoint/:endpoint_id/challenge",
+		handlers.EndpointChallengePut)
 	dbGroup.GET("/endpoint/:endpoint_id/comm",
 		handlers.EndpointCommGet)
 
 	hsmAuthGroup.GET("/hsm", hsmGet)
 
+	engine.GET("/.well-known/openid-configuration", oidcConfigurationGet)
+	engine.GET("/oidc/jwks", oidcJwksGet)
+	sessGroup.GET("/oidc/authorize", oidcAuthorizeGet)
+	dbGroup.POST("/oidc/token", oidcTokenPost)
+	dbGroup.GET("/oidc/userinfo", oidcUserinfoGet)
+	dbGroup.POST("/oidc/userinfo", oidcUserinfoGet)
+
 	sessGroup.GET("/ssh", sshGet)
 	csrfGroup.PUT("/ssh/validate/:ssh_token", sshValidatePut)
 	csrfGroup.DELETE("/ssh/validate/:ssh_token", sshValidateDelete)
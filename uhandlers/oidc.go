@@ -0,0 +1,474 @@
+package uhandlers
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/oidc"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/pritunl/pritunl-zero/validator"
+)
+
+type oidcConfigurationData struct {
+	Issuer                            string   `json:"issuer"`
+	AuthorizationEndpoint             string   `json:"authorization_endpoint"`
+	TokenEndpoint                     string   `json:"token_endpoint"`
+	UserinfoEndpoint                  string   `json:"userinfo_endpoint"`
+	JwksUri                           string   `json:"jwks_uri"`
+	ResponseTypesSupported            []string `json:"response_types_supported"`
+	GrantTypesSupported               []string `json:"grant_types_supported"`
+	SubjectTypesSupported             []string `json:"subject_types_supported"`
+	IdTokenSigningAlgValuesSupported  []string `json:"id_token_signing_alg_values_supported"`
+	ScopesSupported                   []string `json:"scopes_supported"`
+	ClaimsSupported                   []string `json:"claims_supported"`
+	TokenEndpointAuthMethodsSupported []string `json:"token_endpoint_auth_methods_supported"`
+	CodeChallengeMethodsSupported     []string `json:"code_challenge_methods_supported"`
+}
+
+type oidcTokenData struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+	IdToken     string `json:"id_token"`
+	Scope       string `json:"scope"`
+}
+
+type oidcErrorData struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
+
+func oidcRedirectError(c *gin.Context, redirectUri, state,
+	errCode, errDesc string) {
+
+	redirectUrl, err := url.Parse(redirectUri)
+	if err != nil {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	query := redirectUrl.Query()
+	query.Set("error", errCode)
+	if errDesc != "" {
+		query.Set("error_description", errDesc)
+	}
+	if state != "" {
+		query.Set("state", state)
+	}
+	redirectUrl.RawQuery = query.Encode()
+
+	c.Redirect(302, redirectUrl.String())
+}
+
+func oidcTokenError(c *gin.Context, code int, errCode, errDesc string) {
+	c.Writer.Header().Set("Cache-Control", "no-store")
+	c.Writer.Header().Set("Pragma", "no-cache")
+	c.JSON(code, &oidcErrorData{
+		Error:            errCode,
+		ErrorDescription: errDesc,
+	})
+}
+
+// Continue an authorization request that was interrupted by the login page
+func oidcResume(c *gin.Context) bool {
+	query := c.Query("oidc_authorize")
+	if query == "" {
+		return false
+	}
+
+	c.Redirect(302, "/oidc/authorize?"+query)
+	return true
+}
+
+func oidcConfigurationGet(c *gin.Context) {
+	issuer := oidc.Issuer()
+
+	data := &oidcConfigurationData{
+		Issuer:                 issuer,
+		AuthorizationEndpoint:  issuer + "/oidc/authorize",
+		TokenEndpoint:          issuer + "/oidc/token",
+		UserinfoEndpoint:       issuer + "/oidc/userinfo",
+		JwksUri:                issuer + "/oidc/jwks",
+		ResponseTypesSupported: []string{"code"},
+		GrantTypesSupported:    []string{"authorization_code"},
+		SubjectTypesSupported:  []string{"public"},
+		IdTokenSigningAlgValuesSupported: []string{
+			"RS256",
+		},
+		ScopesSupported: oidc.Scopes,
+		ClaimsSupported: oidc.Claims,
+		TokenEndpointAuthMethodsSupported: []string{
+			"client_secret_basic",
+			"client_secret_post",
+		},
+		CodeChallengeMethodsSupported: []string{
+			oidc.ChallengePlain,
+			oidc.ChallengeS256,
+		},
+	}
+
+	c.JSON(200, data)
+}
+
+func oidcJwksGet(c *gin.Context) {
+	jwks, err := oidc.GetJwks()
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, jwks)
+}
+
+func oidcAuthorizeGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	query := c.Request.URL.Query()
+
+	redirectUri := query.Get("redirect_uri")
+	state := query.Get("state")
+
+	client, err := oidc.GetClientId(db, query.Get("client_id"))
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			errData := &errortypes.ErrorData{
+				Error:   "oidc_client_invalid",
+				Message: "Unknown OpenID Connect client",
+			}
+			c.JSON(400, errData)
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if !client.HasRedirectUri(redirectUri) {
+		errData := &errortypes.ErrorData{
+			Error:   "oidc_redirect_uri_invalid",
+			Message: "OpenID Connect redirect URI not registered for client",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	if query.Get("response_type") != "code" {
+		oidcRedirectError(c, redirectUri, state,
+			"unsupported_response_type", "Only code flow is supported")
+		return
+	}
+
+	scope, valid := oidc.ParseScope(query.Get("scope"))
+	if !valid {
+		oidcRedirectError(c, redirectUri, state,
+			"invalid_scope", "Scope must include openid")
+		return
+	}
+
+	challenge := query.Get("code_challenge")
+	challengeMethod := query.Get("code_challenge_method")
+	if challenge != "" {
+		if challengeMethod == "" {
+			challengeMethod = oidc.ChallengePlain
+		}
+
+		if challengeMethod != oidc.ChallengePlain &&
+			challengeMethod != oidc.ChallengeS256 {
+
+			oidcRedirectError(c, redirectUri, state,
+				"invalid_request", "Unsupported code challenge method")
+			return
+		}
+	} else {
+		challengeMethod = ""
+	}
+
+	if !authr.IsValid() || authr.IsApi() {
+		if query.Get("prompt") == "none" {
+			oidcRedirectError(c, redirectUri, state,
+				"login_required", "")
+			return
+		}
+
+		c.Redirect(302, "/login?"+url.Values{
+			"oidc_authorize": []string{c.Request.URL.RawQuery},
+		}.Encode())
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	deviceAuth, secProvider, errAudit, errData, err := validator.ValidateUser(
+		db, usr, false, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData == nil && !client.UserHasAccess(usr) {
+		errData = &errortypes.ErrorData{
+			Error:   "oidc_client_unauthorized",
+			Message: "Not authorized for application",
+		}
+	}
+
+	if errData != nil {
+		if errAudit == nil {
+			errAudit = audit.Fields{
+				"error":   errData.Error,
+				"message": errData.Message,
+			}
+		}
+		errAudit["client_name"] = client.Name
+		errAudit["client_id"] = client.ClientId
+
+		err = audit.New(
+			db,
+			c.Request,
+			usr.Id,
+			audit.UserOidcAuthorizeFailed,
+			errAudit,
+		)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		oidcRedirectError(c, redirectUri, state,
+			"access_denied", errData.Message)
+		return
+	}
+
+	// Sessions without secondary authentication must login again to
+	// complete the secondary and device steps required by policies
+	if (deviceAuth || !secProvider.IsZero()) &&
+		!authr.GetSession().Secondary {
+
+		if query.Get("prompt") == "none" {
+			oidcRedirectError(c, redirectUri, state,
+				"interaction_required", "")
+			return
+		}
+
+		c.Redirect(302, "/login?"+url.Values{
+			"oidc_authorize": []string{c.Request.URL.RawQuery},
+		}.Encode())
+		return
+	}
+
+	code, err := oidc.NewCode(db, client, usr.Id, redirectUri, scope,
+		query.Get("nonce"), challenge, challengeMethod,
+		authr.GetSession().Timestamp)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.UserOidcAuthorize,
+		audit.Fields{
+			"client_name": client.Name,
+			"client_id":   client.ClientId,
+			"scope":       strings.Join(scope, " "),
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	redirectUrl, err := url.Parse(redirectUri)
+	if err != nil {
+		utils.AbortWithStatus(c, 400)
+		return
+	}
+
+	redirectQuery := redirectUrl.Query()
+	redirectQuery.Set("code", code)
+	if state != "" {
+		redirectQuery.Set("state", state)
+	}
+	redirectUrl.RawQuery = redirectQuery.Encode()
+
+	c.Redirect(302, redirectUrl.String())
+}
+
+func oidcTokenPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	clientId, clientSecret, ok := c.Request.BasicAuth()
+	if ok {
+		clientId, _ = url.QueryUnescape(clientId)
+		clientSecret, _ = url.QueryUnescape(clientSecret)
+	} else {
+		clientId = c.PostForm("client_id")
+		clientSecret = c.PostForm("client_secret")
+	}
+
+	client, err := oidc.GetClientId(db, clientId)
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			oidcTokenError(c, 401, "invalid_client", "")
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if !client.CheckSecret(clientSecret) {
+		oidcTokenError(c, 401, "invalid_client", "")
+		return
+	}
+
+	if c.PostForm("grant_type") != "authorization_code" {
+		oidcTokenError(c, 400, "unsupported_grant_type", "")
+		return
+	}
+
+	cd, err := oidc.RedeemCode(db, c.PostForm("code"))
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			oidcTokenError(c, 400, "invalid_grant",
+				"Authorization code invalid or expired")
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if cd.Client != client.Id ||
+		cd.RedirectUri != c.PostForm("redirect_uri") {
+
+		oidcTokenError(c, 400, "invalid_grant",
+			"Authorization code not issued for client")
+		return
+	}
+
+	if !cd.CheckVerifier(c.PostForm("code_verifier")) {
+		oidcTokenError(c, 400, "invalid_grant", "Code verifier invalid")
+		return
+	}
+
+	usr, err := user.Get(db, cd.User)
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			oidcTokenError(c, 400, "invalid_grant", "")
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	_, _, _, errData, err := validator.ValidateUser(
+		db, usr, true, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil || !client.UserHasAccess(usr) {
+		oidcTokenError(c, 400, "invalid_grant", "")
+		return
+	}
+
+	accessToken, err := oidc.NewToken(db, cd)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	idToken, err := oidc.NewIdToken(client, usr, cd)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	data := &oidcTokenData{
+		AccessToken: accessToken,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(oidc.TokenExpire.Seconds()),
+		IdToken:     idToken,
+		Scope:       strings.Join(cd.Scope, " "),
+	}
+
+	c.Writer.Header().Set("Cache-Control", "no-store")
+	c.Writer.Header().Set("Pragma", "no-cache")
+	c.JSON(200, data)
+}
+
+func oidcUserinfoGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	authHeader := c.GetHeader("Authorization")
+	if len(authHeader) < 7 ||
+		!strings.EqualFold(authHeader[:7], "Bearer ") {
+
+		c.Writer.Header().Set("WWW-Authenticate", "Bearer")
+		utils.AbortWithStatus(c, 401)
+		return
+	}
+
+	tkn, err := oidc.GetToken(db, strings.TrimSpace(authHeader[7:]))
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			c.Writer.Header().Set("WWW-Authenticate",
+				"Bearer error=\"invalid_token\"")
+			utils.AbortWithStatus(c, 401)
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	usr, err := user.Get(db, tkn.User)
+	if err != nil {
+		switch err.(type) {
+		case *database.NotFoundError:
+			c.Writer.Header().Set("WWW-Authenticate",
+				"Bearer error=\"invalid_token\"")
+			utils.AbortWithStatus(c, 401)
+			break
+		default:
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	_, _, _, errData, err := validator.ValidateUser(
+		db, usr, true, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		c.Writer.Header().Set("WWW-Authenticate",
+			"Bearer error=\"invalid_token\"")
+		utils.AbortWithStatus(c, 401)
+		return
+	}
+
+	c.JSON(200, oidc.GetClaims(usr, tkn.Scope))
+}
@@ -0,0 +1,394 @@
+package uhandlers
+
+import (
+	"github.com/dropbox/godropbox/errors"
+	"github.com/gin-gonic/gin"
+	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/audit"
+	"github.com/pritunl/pritunl-zero/authorizer"
+	"github.com/pritunl/pritunl-zero/cookie"
+	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/demo"
+	"github.com/pritunl/pritunl-zero/device"
+	"github.com/pritunl/pritunl-zero/errortypes"
+	"github.com/pritunl/pritunl-zero/event"
+	"github.com/pritunl/pritunl-zero/node"
+	"github.com/pritunl/pritunl-zero/passkey"
+	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/secondary"
+	"github.com/pritunl/pritunl-zero/session"
+	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
+	"github.com/pritunl/pritunl-zero/validator"
+)
+
+type passkeyRequestData struct {
+	Token   string      `json:"token"`
+	Options interface{} `json:"options"`
+}
+
+type passkeyRespondData struct {
+	Token string `json:"token"`
+	Name  string `json:"name"`
+}
+
+// Reject password and single sign-on logins when a policy requires
+// passkeys. Users without a passkey may login to enroll one only when the
+// login will complete a secondary with an enrolled device or provider.
+func passkeyEnforce(c *gin.Context, db *database.Database,
+	usr *user.User, devAuth bool, secProviderId primitive.ObjectID,
+	method string) (blocked bool) {
+
+	required, err := validator.Passkey(db, usr,
+		func(polcy *policy.Policy) bool {
+			return polcy.UserPasskey
+		})
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	if !required {
+		return
+	}
+
+	count, err := device.CountPasskey(db, usr.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	if count == 0 {
+		if !secProviderId.IsZero() {
+			return
+		}
+
+		if devAuth {
+			secCount, e := device.CountSecondary(db, usr.Id)
+			if e != nil {
+				utils.AbortWithError(c, 500, e)
+				blocked = true
+				return
+			}
+
+			if secCount != 0 {
+				return
+			}
+		}
+	}
+
+	errData := &errortypes.ErrorData{
+		Error:   "passkey_required",
+		Message: "Passkey login is required",
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.UserLoginFailed,
+		audit.Fields{
+			"method":  method,
+			"error":   errData.Error,
+			"message": errData.Message,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	c.JSON(401, errData)
+	blocked = true
+
+	return
+}
+
+func authPasskeyRequestGet(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+
+	if node.Self.WebauthnDomain == "" {
+		errData := &errortypes.ErrorData{
+			Error:   "webauthn_domain_unavailable",
+			Message: "WebAuthn domain must be configured",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	token, resp, err := passkey.LoginRequest(
+		db, utils.GetOrigin(c.Request))
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, &passkeyRequestData{
+		Token:   token,
+		Options: resp,
+	})
+}
+
+func authPasskeyRespondPost(c *gin.Context) {
+	db := c.MustGet("db").(*database.Database)
+	data := &passkeyRespondData{}
+
+	body, err := utils.CopyBody(c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, devc, errData, err := passkey.LoginResponse(
+		db, utils.GetOrigin(c.Request), data.Token, body)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			errData := &errortypes.ErrorData{
+				Error:   "passkey_expired",
+				Message: "Passkey authentication has expired",
+			}
+			c.JSON(401, errData)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if errData != nil {
+		if usr != nil {
+			err = audit.New(
+				db,
+				c.Request,
+				usr.Id,
+				audit.UserPasskeyLoginFailed,
+				audit.Fields{
+					"method":  "passkey",
+					"error":   errData.Error,
+					"message": errData.Message,
+				},
+			)
+			if err != nil {
+				utils.AbortWithError(c, 500, err)
+				return
+			}
+		}
+
+		c.JSON(401, errData)
+		return
+	}
+
+	_, secProviderId, errAudit, errData, err := validator.ValidateUser(
+		db, usr, false, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	if errData != nil {
+		if errAudit == nil {
+			errAudit = audit.Fields{
+				"error":   errData.Error,
+				"message": errData.Message,
+			}
+		}
+		errAudit["method"] = "passkey"
+		errAudit["device_id"] = devc.Id.Hex()
+
+		err = audit.New(
+			db,
+			c.Request,
+			usr.Id,
+			audit.UserPasskeyLoginFailed,
+			errAudit,
+		)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		c.JSON(401, errData)
+		return
+	}
+
+	// Passkey verification already satisfies device step-up
+	_, loginAudit, blocked := validator.DetectLogin(c, db, usr, nil,
+		audit.UserLoginFailed, "passkey")
+	if blocked {
+		return
+	}
+
+	if !secProviderId.IsZero() {
+		secd, err := secondary.New(db, usr.Id, secondary.User, secProviderId)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		data, err := secd.GetData()
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		c.JSON(201, data)
+		return
+	}
+
+	loginAudit["device_id"] = devc.Id.Hex()
+	loginAudit["device_name"] = devc.Name
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.UserPasskeyLogin,
+		loginAudit,
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	cook := cookie.NewUser(c.Writer, c.Request)
+
+	_, err = cook.NewSession(db, c.Request, usr.Id, true, session.User, true)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	redirectQueryJson(c, c.Request.URL.RawQuery)
+}
+
+func passkeyRegisterGet(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+
+	if node.Self.WebauthnDomain == "" {
+		errData := &errortypes.ErrorData{
+			Error:   "webauthn_domain_unavailable",
+			Message: "WebAuthn domain must be configured",
+		}
+		c.JSON(400, errData)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	token, resp, err := passkey.RegisterRequest(
+		db, utils.GetOrigin(c.Request), usr)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	c.JSON(200, &passkeyRequestData{
+		Token:   token,
+		Options: resp,
+	})
+}
+
+func passkeyRegisterPost(c *gin.Context) {
+	if demo.Blocked(c) {
+		return
+	}
+
+	db := c.MustGet("db").(*database.Database)
+	authr := c.MustGet("authorizer").(*authorizer.Authorizer)
+	data := &passkeyRespondData{}
+
+	body, err := utils.CopyBody(c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	err = c.Bind(data)
+	if err != nil {
+		err = &errortypes.ParseError{
+			errors.Wrap(err, "handler: Bind error"),
+		}
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	usr, err := authr.GetUser(db)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	devc, errData, err := passkey.RegisterResponse(
+		db, utils.GetOrigin(c.Request), usr, data.Token, body, data.Name)
+	if err != nil {
+		if _, ok := err.(*database.NotFoundError); ok {
+			errData := &errortypes.ErrorData{
+				Error:   "passkey_expired",
+				Message: "Passkey registration has expired",
+			}
+			c.JSON(400, errData)
+		} else {
+			utils.AbortWithError(c, 500, err)
+		}
+		return
+	}
+
+	if errData != nil {
+		err = audit.New(
+			db,
+			c.Request,
+			usr.Id,
+			audit.UserPasskeyRegisterFailed,
+			audit.Fields{
+				"error":   errData.Error,
+				"message": errData.Message,
+			},
+		)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			return
+		}
+
+		c.JSON(400, errData)
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		audit.UserPasskeyRegister,
+		audit.Fields{
+			"device_id":   devc.Id.Hex(),
+			"device_name": devc.Name,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		return
+	}
+
+	_ = event.PublishDispatch(db, "device.change")
+
+	c.JSON(200, devc)
+}
@@ -84,13 +84,24 @@ func sshValidatePut(c *gin.Context) {
 	}
 
 	deviceAuth, secProviderId, err, errData := chal.Approve(
-		db, usr, c.Request, false, false)
+		db, usr, authr.GetSession(), c.Request, false, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
 	}
 
 	if errData != nil {
+		if errData.Error == "reauth_required" {
+			err = authr.Clear(db, c.Writer, c.Request)
+			if err != nil {
+				utils.AbortWithError(c, 500, err)
+				return
+			}
+
+			c.JSON(401, errData)
+			return
+		}
+
 		c.JSON(400, errData)
 		return
 	}
@@ -279,7 +290,7 @@ func sshSecondaryPut(c *gin.Context) {
 		return
 	}
 
-	_, _, err, errData = chal.Approve(db, usr, c.Request, true, true)
+	_, _, err, errData = chal.Approve(db, usr, nil, c.Request, true, true)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
@@ -443,7 +454,7 @@ func sshWanRespondPost(c *gin.Context) {
 	}
 
 	_, secProviderId, err, errData = chal.Approve(
-		db, usr, c.Request, true, false)
+		db, usr, nil, c.Request, true, false)
 	if err != nil {
 		utils.AbortWithError(c, 500, err)
 		return
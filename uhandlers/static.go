@@ -45,6 +45,10 @@ func staticIndexGet(c *gin.Context) {
 		return
 	}
 
+	if oidcResume(c) {
+		return
+	}
+
 	staticPath(c, "/uindex.html", false)
 }
 
@@ -79,6 +83,10 @@ func staticTestingGet(c *gin.Context) {
 				return
 			}
 
+			if oidcResume(c) {
+				return
+			}
+
 			pth = "uindex.html"
 		}
 	}
@@ -34,6 +34,7 @@ type User struct {
 	LastSync        time.Time             `bson:"last_sync" json:"last_sync"`
 	Roles           []string              `bson:"roles" json:"roles"`
 	Administrator   string                `bson:"administrator" json:"administrator"`
+	AdminRoles      []primitive.ObjectID  `bson:"admin_roles" json:"admin_roles"`
 	Disabled        bool                  `bson:"disabled" json:"disabled"`
 	ActiveUntil     time.Time             `bson:"active_until" json:"active_until"`
 	Permissions     []string              `bson:"permissions" json:"permissions"`
@@ -51,6 +52,10 @@ func (u *User) Validate(db *database.Database) (
 		u.Permissions = []string{}
 	}
 
+	if u.AdminRoles == nil {
+		u.AdminRoles = []primitive.ObjectID{}
+	}
+
 	if !types.Contains(u.Type) {
 		errData = &errortypes.ErrorData{
 			Error:   "user_type_invalid",
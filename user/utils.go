@@ -217,6 +217,30 @@ func Remove(db *database.Database, userIds []primitive.ObjectID) (
 		return
 	}
 
+	coll = db.ApiKeys()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"user": &bson.M{
+			"$in": userIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
+	coll = db.AccessRequests()
+
+	_, err = coll.DeleteMany(db, &bson.M{
+		"user": &bson.M{
+			"$in": userIds,
+		},
+	})
+	if err != nil {
+		err = database.ParseError(err)
+		return
+	}
+
 	coll = db.Users()
 
 	_, err = coll.DeleteMany(db, &bson.M{
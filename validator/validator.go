@@ -5,14 +5,20 @@ import (
 	"time"
 
 	"github.com/dropbox/godropbox/container/set"
+	"github.com/gin-gonic/gin"
 	"github.com/pritunl/mongo-go-driver/bson/primitive"
+	"github.com/pritunl/pritunl-zero/adminrole"
+	"github.com/pritunl/pritunl-zero/anomaly"
 	"github.com/pritunl/pritunl-zero/audit"
 	"github.com/pritunl/pritunl-zero/database"
+	"github.com/pritunl/pritunl-zero/device"
 	"github.com/pritunl/pritunl-zero/errortypes"
 	"github.com/pritunl/pritunl-zero/event"
 	"github.com/pritunl/pritunl-zero/policy"
+	"github.com/pritunl/pritunl-zero/risk"
 	"github.com/pritunl/pritunl-zero/service"
 	"github.com/pritunl/pritunl-zero/user"
+	"github.com/pritunl/pritunl-zero/utils"
 )
 
 func ValidateAdmin(db *database.Database, usr *user.User,
@@ -53,10 +59,10 @@ func ValidateAdmin(db *database.Database, usr *user.User,
 		return
 	}
 
-	if usr.Administrator != "super" {
+	if !adminrole.IsAdmin(usr) {
 		errAudit = audit.Fields{
-			"error":   "user_not_super",
-			"message": "User is not super user",
+			"error":   "user_not_admin",
+			"message": "User is not an administrator",
 		}
 		errData = &errortypes.ErrorData{
 			Error:   "unauthorized",
@@ -237,7 +243,7 @@ func ValidateProxy(db *database.Database, usr *user.User,
 		}
 
 		for _, polcy := range policies {
-			errData, err = polcy.ValidateUser(db, usr, r)
+			errData, err = polcy.ValidateService(db, usr, r, srvc.Name)
 			if err != nil || errData != nil {
 				return
 			}
@@ -263,7 +269,7 @@ func ValidateProxy(db *database.Database, usr *user.User,
 		}
 
 		for _, polcy := range policies {
-			errData, err = polcy.ValidateUser(db, usr, r)
+			errData, err = polcy.ValidateService(db, usr, r, srvc.Name)
 			if err != nil || errData != nil {
 				return
 			}
@@ -286,3 +292,184 @@ func ValidateProxy(db *database.Database, usr *user.User,
 
 	return
 }
+
+func passkeyRequired(policies []*policy.Policy,
+	lookup func(polcy *policy.Policy) bool) bool {
+
+	for _, polcy := range policies {
+		if !polcy.Disabled && lookup(polcy) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Check if a role policy requires passkey login, lookup returns the policy
+// option for the login type such as AdminPasskey or UserPasskey
+func Passkey(db *database.Database, usr *user.User,
+	lookup func(polcy *policy.Policy) bool) (required bool, err error) {
+
+	policies, err := policy.GetRoles(db, usr.Roles)
+	if err != nil {
+		return
+	}
+
+	required = passkeyRequired(policies, lookup)
+
+	return
+}
+
+func PasskeyProxy(db *database.Database, usr *user.User,
+	srvc *service.Service) (required bool, err error) {
+
+	policies, err := policy.GetService(db, srvc.Id)
+	if err != nil {
+		return
+	}
+
+	rolePolicies, err := policy.GetRoles(db, usr.Roles)
+	if err != nil {
+		return
+	}
+	policies = append(policies, rolePolicies...)
+
+	required = passkeyRequired(policies, func(polcy *policy.Policy) bool {
+		return polcy.ProxyPasskey
+	})
+
+	return
+}
+
+// Run anomaly and risk detection for login, service policies are included
+// when srvc is not nil
+func Detect(db *database.Database, usr *user.User,
+	srvc *service.Service, r *http.Request) (stepUp bool,
+	riskAudit audit.Fields, errAudit audit.Fields,
+	errData *errortypes.ErrorData, err error) {
+
+	policies := []*policy.Policy{}
+	if srvc != nil {
+		policies, err = policy.GetService(db, srvc.Id)
+		if err != nil {
+			return
+		}
+	}
+
+	rolePolicies, err := policy.GetRoles(db, usr.Roles)
+	if err != nil {
+		return
+	}
+	policies = append(policies, rolePolicies...)
+
+	stepUp, errAudit, errData, err = anomaly.Check(db, usr, policies, r)
+	if err != nil || errData != nil {
+		return
+	}
+
+	riskStepUp, riskAudit, errAudit, errData, err := risk.Check(
+		db, usr, policies, srvc, r)
+	if err != nil || errData != nil {
+		return
+	}
+
+	if riskStepUp {
+		stepUp = true
+	}
+
+	return
+}
+
+// Run login detection for handler, denials are audited with the failed
+// login audit type and written to the response. Returns fields for the
+// login audit with the method and risk assessment.
+func DetectLogin(c *gin.Context, db *database.Database, usr *user.User,
+	srvc *service.Service, auditType, method string) (
+	stepUp bool, loginAudit audit.Fields, blocked bool) {
+
+	stepUp, riskAudit, errAudit, errData, err := Detect(
+		db, usr, srvc, c.Request)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	if errData != nil {
+		errAudit["method"] = method
+
+		err = audit.New(
+			db,
+			c.Request,
+			usr.Id,
+			auditType,
+			errAudit,
+		)
+		if err != nil {
+			utils.AbortWithError(c, 500, err)
+			blocked = true
+			return
+		}
+
+		c.JSON(401, errData)
+		blocked = true
+		return
+	}
+
+	loginAudit = audit.Fields{
+		"method": method,
+	}
+	for key, val := range riskAudit {
+		loginAudit[key] = val
+	}
+
+	return
+}
+
+// Require an enrolled secondary device or provider for a detection step-up,
+// registering a device cannot satisfy a step-up. Denials are audited with
+// the failed login audit type and written to the response.
+func StepUpLogin(c *gin.Context, db *database.Database, usr *user.User,
+	secProvider primitive.ObjectID, auditType, method string) (
+	blocked bool) {
+
+	if !secProvider.IsZero() {
+		return
+	}
+
+	count, err := device.CountSecondary(db, usr.Id)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	if count != 0 {
+		return
+	}
+
+	err = audit.New(
+		db,
+		c.Request,
+		usr.Id,
+		auditType,
+		audit.Fields{
+			"error":   "step_up_unavailable",
+			"message": "Login requires step-up without secondary device",
+			"method":  method,
+		},
+	)
+	if err != nil {
+		utils.AbortWithError(c, 500, err)
+		blocked = true
+		return
+	}
+
+	c.JSON(401, &errortypes.ErrorData{
+		Error:   "step_up_unavailable",
+		Message: "Login requires verification with a registered device",
+	})
+	blocked = true
+
+	return
+}